@@ -0,0 +1,55 @@
+package analyzer
+
+import "go++/ast"
+
+// Analyzer runs every static pass over a program before it is evaluated
+type Analyzer struct {
+	resolver   *Resolver
+	types      *TypeChecker
+	resolution *Resolution
+}
+
+func New() *Analyzer {
+	return &Analyzer{resolver: NewResolver(), types: NewTypeChecker()}
+}
+
+// Declare adds a top level binding the host environment provides, like the arguments of a script
+func (a *Analyzer) Declare(name string, isMutable bool) {
+	a.resolver.global.declare(&ast.Identifier{Value: name}, VariableBinding, isMutable)
+}
+
+// Analyze can be called repeatedly, top level bindings are kept between calls so it can be used by the REPL
+func (a *Analyzer) Analyze(program *ast.Program) []Diagnostic {
+	resolution, diagnostics := a.resolver.Resolve(program)
+	a.resolution = resolution
+
+	diagnostics = append(diagnostics, checkMutability(resolution)...)
+	diagnostics = append(diagnostics, a.types.Check(program, resolution)...)
+	sortDiagnostics(diagnostics)
+
+	return diagnostics
+}
+
+// Resolution returns the resolution of the last analyzed program, tools use it to look up bindings
+func (a *Analyzer) Resolution() *Resolution {
+	return a.resolution
+}
+
+// TypeOfBinding returns the type the type checker gave a binding, or any
+func (a *Analyzer) TypeOfBinding(binding *Binding) *Type {
+	return a.types.TypeOf(binding)
+}
+
+// TypeOf analyzes an expression as if it was evaluated at the top level and returns its static type
+func (a *Analyzer) TypeOf(expression ast.Expression) (*Type, []Diagnostic) {
+	statement := &ast.ExpressionStatement{Token: ast.StartToken(expression), Expression: expression}
+	program := &ast.Program{Statements: []ast.Statement{statement}}
+
+	resolution, diagnostics := a.resolver.Resolve(program)
+	result, typeDiagnostics := a.types.check(program, resolution)
+
+	diagnostics = append(diagnostics, typeDiagnostics...)
+	sortDiagnostics(diagnostics)
+
+	return result, diagnostics
+}
@@ -0,0 +1,71 @@
+package analyzer
+
+import (
+	"fmt"
+	"go++/token"
+	"sort"
+)
+
+type Severity int
+
+const (
+	Error Severity = iota
+	Warning
+)
+
+type Diagnostic struct {
+	Line     int
+	Column   int
+	Severity Severity
+	Message  string
+}
+
+func newError(tok token.Token, format string, a ...interface{}) Diagnostic {
+	return Diagnostic{Line: tok.Line, Column: tok.Column, Severity: Error, Message: fmt.Sprintf(format, a...)}
+}
+
+func newWarning(tok token.Token, format string, a ...interface{}) Diagnostic {
+	return Diagnostic{Line: tok.Line, Column: tok.Column, Severity: Warning, Message: fmt.Sprintf(format, a...)}
+}
+
+func (d Diagnostic) String() string {
+	if d.Severity == Warning {
+		return fmt.Sprintf("%d:%d: warning: %s", d.Line, d.Column, d.Message)
+	}
+
+	return fmt.Sprintf("%d:%d: %s", d.Line, d.Column, d.Message)
+}
+
+// HasErrors reports whether any of the diagnostics should stop the program from running
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == Error {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Errors filters out everything but errors
+func Errors(diagnostics []Diagnostic) []Diagnostic {
+	errors := []Diagnostic{}
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == Error {
+			errors = append(errors, diagnostic)
+		}
+	}
+
+	return errors
+}
+
+func sortDiagnostics(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		if diagnostics[i].Line != diagnostics[j].Line {
+			return diagnostics[i].Line < diagnostics[j].Line
+		}
+
+		return diagnostics[i].Column < diagnostics[j].Column
+	})
+}
@@ -0,0 +1,57 @@
+package analyzer
+
+import (
+	"go++/ast"
+)
+
+// MutabilityChecker rejects assignments to bindings that weren't declared with `let mut`,
+// including assignments to array elements and members of such bindings.
+type MutabilityChecker struct {
+	resolver *Resolver
+}
+
+func NewMutabilityChecker() *MutabilityChecker {
+	return &MutabilityChecker{resolver: NewResolver()}
+}
+
+// Check can be called repeatedly, top level bindings are kept between calls so it can be used by the REPL
+func (c *MutabilityChecker) Check(program *ast.Program) []Diagnostic {
+	resolution, _ := c.resolver.Resolve(program)
+
+	return checkMutability(resolution)
+}
+
+func checkMutability(resolution *Resolution) []Diagnostic {
+	diagnostics := []Diagnostic{}
+
+	for _, assignment := range resolution.Assignments {
+		root, ok := rootIdentifier(assignment.Assignee)
+
+		if !ok {
+			continue
+		}
+
+		b, ok := resolution.Bindings[root]
+
+		// Unknown identifiers are reported by the resolver
+		if !ok || b.IsMutable {
+			continue
+		}
+
+		switch assignment.Assignee.(type) {
+		case *ast.Identifier:
+			diagnostics = append(diagnostics, newError(root.Token,
+				"cannot assign to immutable binding %s (%s)", b.Name, b.declaredAt()))
+		case *ast.ArrayAccessExpression:
+			diagnostics = append(diagnostics, newError(root.Token,
+				"cannot assign to element of immutable binding %s (%s)", b.Name, b.declaredAt()))
+		case *ast.MemberAccessExpression:
+			diagnostics = append(diagnostics, newError(root.Token,
+				"cannot assign to member of immutable binding %s (%s)", b.Name, b.declaredAt()))
+		}
+	}
+
+	sortDiagnostics(diagnostics)
+
+	return diagnostics
+}
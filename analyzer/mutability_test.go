@@ -0,0 +1,68 @@
+package analyzer
+
+import (
+	"go++/ast"
+	lex "go++/lexer"
+	parse "go++/parser"
+	"testing"
+)
+
+func TestMutabilityChecker(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let mut x = 1 x = 2", []string{}},
+		{"let x = 1 x = 2", []string{"1:11: cannot assign to immutable binding x (declared at 1:5)"}},
+		{"let arr = [1] arr[0] = 2", []string{"1:15: cannot assign to element of immutable binding arr (declared at 1:5)"}},
+		{"let mut arr = [[1]] arr[0][0] = 2", []string{}},
+		{"let arr = [[1]] arr[0][0] = 2", []string{"1:17: cannot assign to element of immutable binding arr (declared at 1:5)"}},
+		{`let s = "hi" s.value = 2`, []string{"1:14: cannot assign to member of immutable binding s (declared at 1:5)"}},
+		{"let f = fn (x) { x = 2 }", []string{}},
+		{"let x = 1 let f = fn () { x = 2 }", []string{"1:27: cannot assign to immutable binding x (declared at 1:5)"}},
+		{"let x = 1 let f = fn () { let mut x = 1 x = 2 }", []string{}},
+		{"let mut x = 1 if true { let x = 2 x = 3 }", []string{"1:35: cannot assign to immutable binding x (declared at 1:29)"}},
+		{"let mut x = 1 if true { let x = 2 } x = 3", []string{}},
+		{"let f = fn () { g = 1 } let g = 0", []string{"1:17: cannot assign to immutable binding g (declared at 1:29)"}},
+		{"let mut i = 0 for i < 3 { let n = i n = 1 i = i + 1 }", []string{"1:37: cannot assign to immutable binding n (declared at 1:31)"}},
+		{"unknown = 1", []string{}},
+	}
+
+	for _, tt := range tests {
+		diagnostics := NewMutabilityChecker().Check(parseProgram(t, tt.input))
+
+		if len(diagnostics) != len(tt.expected) {
+			t.Errorf("wrong number of diagnostics for %q. want=%d, got=%d (%v)", tt.input, len(tt.expected), len(diagnostics), diagnostics)
+			continue
+		}
+
+		for i, diagnostic := range diagnostics {
+			if diagnostic.String() != tt.expected[i] {
+				t.Errorf("wrong diagnostic for %q. want=%q, got=%q", tt.input, tt.expected[i], diagnostic.String())
+			}
+		}
+	}
+}
+
+func TestMutabilityCheckerKeepsGlobals(t *testing.T) {
+	checker := NewMutabilityChecker()
+
+	if diagnostics := checker.Check(parseProgram(t, "let x = 1")); len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics. got=%v", diagnostics)
+	}
+
+	if diagnostics := checker.Check(parseProgram(t, "x = 2")); len(diagnostics) != 1 {
+		t.Fatalf("expected 1 diagnostic. got=%v", diagnostics)
+	}
+}
+
+func parseProgram(t *testing.T, input string) *ast.Program {
+	parser := parse.New(lex.New(input))
+	program := parser.ParseProgram()
+
+	if len(parser.Errors()) != 0 {
+		t.Fatalf("parser has %d errors for %q: %v", len(parser.Errors()), input, parser.Errors())
+	}
+
+	return program
+}
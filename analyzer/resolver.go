@@ -0,0 +1,257 @@
+package analyzer
+
+import (
+	"go++/ast"
+	"go++/evaluator"
+	"strings"
+)
+
+// Resolution is the result of resolving a program
+type Resolution struct {
+	Global *Scope
+
+	// Bindings maps every declaring or reading identifier to its binding
+	Bindings map[*ast.Identifier]*Binding
+
+	// Scopes maps every node that introduces a scope to it
+	Scopes map[ast.Node]*Scope
+
+	Assignments []*ast.AssignExpression
+}
+
+type pendingFunction struct {
+	literal *ast.FunctionLiteral
+	scope   *Scope
+}
+
+// Resolver builds the scope tree of a program, resolves every identifier to its declaration
+// and annotates it with the depth and slot the evaluator can find it at
+type Resolver struct {
+	global      *Scope
+	resolution  *Resolution
+	pending     []pendingFunction
+	declared    []*Binding
+	diagnostics []Diagnostic
+}
+
+func NewResolver() *Resolver {
+	return &Resolver{global: newScope(nil, nil)}
+}
+
+// Resolve can be called repeatedly, top level bindings are kept between calls so it can be used by the REPL
+func (r *Resolver) Resolve(program *ast.Program) (*Resolution, []Diagnostic) {
+	r.resolution = &Resolution{
+		Global:   r.global,
+		Bindings: make(map[*ast.Identifier]*Binding),
+		Scopes:   map[ast.Node]*Scope{program: r.global},
+	}
+	r.declared = []*Binding{}
+	r.diagnostics = []Diagnostic{}
+
+	r.resolveStatements(program.Statements, r.global)
+
+	// Function bodies are resolved last since they are bound late, at call time every binding
+	// of their enclosing scopes already exists
+	for len(r.pending) > 0 {
+		fn := r.pending[0]
+		r.pending = r.pending[1:]
+
+		r.resolveFunctionBody(fn)
+	}
+
+	r.reportUnused()
+	sortDiagnostics(r.diagnostics)
+
+	return r.resolution, r.diagnostics
+}
+
+func (r *Resolver) resolveStatements(statements []ast.Statement, scope *Scope) {
+	// Declared functions are bound before the statements of their block run, like the evaluator hoists them
+	for _, statement := range statements {
+		if declaration, ok := statement.(*ast.FunctionStatement); ok {
+			r.declare(declaration.Function.Name, FunctionBinding, false, scope)
+		}
+	}
+
+	for _, statement := range statements {
+		r.resolveStatement(statement, scope)
+	}
+}
+
+func (r *Resolver) resolveStatement(statement ast.Statement, scope *Scope) {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		if statement == nil {
+			return
+		}
+
+		r.resolveExpression(statement.Value, scope)
+		r.declarePattern(statement.Target(), VariableBinding, statement.IsMutable, scope)
+	case *ast.FunctionStatement:
+		r.resolveExpression(statement.Function, scope)
+	case *ast.ReturnStatement:
+		r.resolveExpression(statement.ReturnValue, scope)
+	case *ast.ExpressionStatement:
+		r.resolveExpression(statement.Expression, scope)
+	case *ast.BlockStatement:
+		r.resolveStatements(statement.Statements, r.newScope(scope, statement))
+	}
+}
+
+func (r *Resolver) resolveExpression(expression ast.Expression, scope *Scope) {
+	switch expression := expression.(type) {
+	case *ast.Identifier:
+		r.resolveIdentifier(expression, scope, true)
+	case *ast.AssignExpression:
+		r.resolveExpression(expression.Value, scope)
+		r.resolveAssignee(expression.Assignee, scope)
+		r.resolution.Assignments = append(r.resolution.Assignments, expression)
+	case *ast.InterpolatedString:
+		for _, embedded := range expression.Expressions {
+			r.resolveExpression(embedded, scope)
+		}
+	case *ast.PrefixExpression:
+		r.resolveExpression(expression.Right, scope)
+	case *ast.InfixExpression:
+		r.resolveExpression(expression.Left, scope)
+		r.resolveExpression(expression.Right, scope)
+	case *ast.CallExpression:
+		r.resolveExpression(expression.Function, scope)
+
+		for _, argument := range expression.Arguments {
+			r.resolveExpression(argument, scope)
+		}
+	case *ast.SpreadExpression:
+		r.resolveExpression(expression.Value, scope)
+	case *ast.NamedArgument:
+		// The name is a parameter of the callee, not a binding in scope
+		r.resolveExpression(expression.Value, scope)
+	case *ast.MemberAccessExpression:
+		r.resolveExpression(expression.Expression, scope)
+	case *ast.ArrayAccessExpression:
+		r.resolveExpression(expression.Expression, scope)
+		r.resolveExpression(expression.Index, scope)
+	case *ast.ArrayLiteral:
+		for _, value := range expression.Values {
+			r.resolveExpression(value, scope)
+		}
+	case *ast.IfExpression:
+		r.resolveExpression(expression.Condition, scope)
+		r.resolveStatements(expression.Consequence.Statements, r.newScope(scope, expression.Consequence))
+
+		if expression.Alternative != nil {
+			r.resolveStatements(expression.Alternative.Statements, r.newScope(scope, expression.Alternative))
+		}
+	case *ast.ForLoopLiteral:
+		r.resolveExpression(expression.Condition, scope)
+		r.resolveStatements(expression.Body.Statements, r.newScope(scope, expression.Body))
+	case *ast.ForInLoopLiteral:
+		r.resolveExpression(expression.Iterable, scope)
+
+		// The pattern is bound in the environment the body runs in
+		loopScope := r.newScope(scope, expression)
+
+		r.declarePattern(expression.Pattern, VariableBinding, false, loopScope)
+		r.resolveStatements(expression.Body.Statements, loopScope)
+	case *ast.FunctionLiteral:
+		r.pending = append(r.pending, pendingFunction{literal: expression, scope: scope})
+	}
+}
+
+// resolveAssignee resolves the target of an assignment, only the subexpressions of element
+// and member assignments are reads of their bindings
+func (r *Resolver) resolveAssignee(assignee ast.Expression, scope *Scope) {
+	switch assignee := assignee.(type) {
+	case *ast.Identifier:
+		r.resolveIdentifier(assignee, scope, false)
+	case *ast.ArrayAccessExpression:
+		r.resolveExpression(assignee.Expression, scope)
+		r.resolveExpression(assignee.Index, scope)
+	default:
+		r.resolveExpression(assignee, scope)
+	}
+}
+
+func (r *Resolver) resolveFunctionBody(fn pendingFunction) {
+	scope := r.newScope(fn.scope, fn.literal)
+
+	// Parameters are bound as mutable when the function is applied, a default can read the parameters before it
+	for _, parameter := range fn.literal.Parameters {
+		r.resolveExpression(parameter.Default, scope)
+		r.declarePattern(parameter.Target(), ParameterBinding, true, scope)
+	}
+
+	r.resolveStatements(fn.literal.Body.Statements, scope)
+}
+
+func (r *Resolver) resolveIdentifier(identifier *ast.Identifier, scope *Scope, isRead bool) {
+	// Builtins are looked up before the environment by the evaluator
+	if evaluator.IsBuiltin(identifier.Value) {
+		return
+	}
+
+	binding, depth, ok := scope.Lookup(identifier.Value)
+
+	if !ok {
+		r.diagnostics = append(r.diagnostics, newError(identifier.Token, "undefined: %s", identifier.Value))
+		return
+	}
+
+	identifier.Depth = depth
+	identifier.Slot = binding.Slot
+	identifier.IsResolved = true
+
+	r.resolution.Bindings[identifier] = binding
+
+	if isRead {
+		binding.References = append(binding.References, identifier)
+	}
+}
+
+func (r *Resolver) declare(identifier *ast.Identifier, kind BindingKind, isMutable bool, scope *Scope) {
+	if evaluator.IsBuiltin(identifier.Value) {
+		r.diagnostics = append(r.diagnostics, newWarning(identifier.Token,
+			"declaration of %s is shadowed by the builtin of the same name", identifier.Value))
+	} else if previous, _, ok := scope.Lookup(identifier.Value); ok {
+		r.diagnostics = append(r.diagnostics, newWarning(identifier.Token,
+			"declaration of %s shadows binding (%s)", identifier.Value, previous.declaredAt()))
+	}
+
+	binding := scope.declare(identifier, kind, isMutable)
+
+	identifier.Slot = binding.Slot
+	identifier.IsResolved = true
+
+	r.resolution.Bindings[identifier] = binding
+	r.declared = append(r.declared, binding)
+}
+
+// declarePattern declares the names of a pattern in the order the evaluator binds them
+func (r *Resolver) declarePattern(pattern ast.Pattern, kind BindingKind, isMutable bool, scope *Scope) {
+	for _, identifier := range ast.PatternIdentifiers(pattern) {
+		r.declare(identifier, kind, isMutable, scope)
+	}
+}
+
+func (r *Resolver) newScope(parent *Scope, node ast.Node) *Scope {
+	scope := newScope(parent, node)
+	r.resolution.Scopes[node] = scope
+
+	return scope
+}
+
+// reportUnused reports local bindings that are never read, top level bindings may be used by
+// later input in the REPL and names starting with an underscore are ignored
+func (r *Resolver) reportUnused() {
+	for _, binding := range r.declared {
+		if binding.Scope.isGlobal() || len(binding.References) > 0 || strings.HasPrefix(binding.Name, "_") {
+			continue
+		}
+
+		if binding.Kind == ParameterBinding {
+			r.diagnostics = append(r.diagnostics, newWarning(binding.Token, "parameter %s is unused", binding.Name))
+		} else {
+			r.diagnostics = append(r.diagnostics, newWarning(binding.Token, "%s declared and not used", binding.Name))
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package analyzer
+
+import (
+	"go++/ast"
+	"go++/evaluator"
+	"go++/object"
+	"testing"
+)
+
+func TestResolverDiagnostics(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 1 x", []string{}},
+		{"y", []string{"1:1: undefined: y"}},
+		{"let x = y + 1", []string{"1:9: undefined: y"}},
+		{"let f = fn () { g() } let g = fn () { 1 }", []string{}},
+		{"let f = fn () { g() }", []string{"1:17: undefined: g"}},
+		{"let f = fn () { f() }", []string{}},
+		{"println(1)", []string{}},
+		{"let f = fn (x) { 1 }", []string{"1:13: warning: parameter x is unused"}},
+		{"let f = fn (_x) { 1 }", []string{}},
+		{"let f = fn () { let y = 1 2 }", []string{"1:21: warning: y declared and not used"}},
+		{"let mut x = 0 if true { let mut y = 1 y = 2 }", []string{"1:33: warning: y declared and not used"}},
+		{"let x = 1 let f = fn (x) { x }", []string{"1:23: warning: declaration of x shadows binding (declared at 1:5)"}},
+		{"let x = 1 if true { let x = 2 x }", []string{"1:25: warning: declaration of x shadows binding (declared at 1:5)"}},
+		{"let println = 1", []string{"1:5: warning: declaration of println is shadowed by the builtin of the same name"}},
+		{"let x = 1 x = 2", []string{"1:11: cannot assign to immutable binding x (declared at 1:5)"}},
+		{"let f = fn () { z = 1 }", []string{"1:17: undefined: z"}},
+		{"let f = fn (x, y = x * 2) { y }", []string{}},
+		{"let f = fn (x = y) { x }", []string{"1:17: undefined: y"}},
+		{"let f = fn (...rest) { 1 }", []string{"1:16: warning: parameter rest is unused"}},
+		{"let f = fn (x) { x } let xs = [1] f(...xs) f(x: xs)", []string{}},
+		{"f() fn f() { 1 }", []string{}},
+		{"fn f() { g() fn g() { 1 } }", []string{}},
+		{"fn f() { fn g() { 1 } 2 }", []string{"1:13: warning: g declared and not used"}},
+		{"fn f() { 1 } f = f", []string{"1:14: cannot assign to immutable binding f (declared at 1:4)"}},
+		{"if true { fn f() { 1 } } f()", []string{"1:14: warning: f declared and not used", "1:26: undefined: f"}},
+		{"let [a, [b], ...c] = [1, [2]] a + b + c[0]", []string{}},
+		{"let f = fn ([x, y]) { x }", []string{"1:17: warning: parameter y is unused"}},
+		{"let [a] = [1] a = 2", []string{"1:15: cannot assign to immutable binding a (declared at 1:6)"}},
+		{"for x in [1] { x } x", []string{"1:20: undefined: x"}},
+		{`let x = 1 "${x} ${y}"`, []string{"1:19: undefined: y"}},
+		{"let f = fn () { for [k, v] in [] { k } }", []string{"1:25: warning: v declared and not used"}},
+		{"for x in [1] { x = 2 }", []string{"1:5: warning: x declared and not used", "1:16: cannot assign to immutable binding x (declared at 1:5)"}},
+	}
+
+	for _, tt := range tests {
+		diagnostics := New().Analyze(parseProgram(t, tt.input))
+
+		if len(diagnostics) != len(tt.expected) {
+			t.Errorf("wrong number of diagnostics for %q. want=%d, got=%d (%v)", tt.input, len(tt.expected), len(diagnostics), diagnostics)
+			continue
+		}
+
+		for i, diagnostic := range diagnostics {
+			if diagnostic.String() != tt.expected[i] {
+				t.Errorf("wrong diagnostic for %q. want=%q, got=%q", tt.input, tt.expected[i], diagnostic.String())
+			}
+		}
+	}
+}
+
+func TestResolverAnnotatesIdentifiers(t *testing.T) {
+	program := parseProgram(t, "let a = 1 let b = 2 let f = fn (x, y) { if true { a + y } }")
+
+	resolution, diagnostics := NewResolver().Resolve(program)
+
+	if len(Errors(diagnostics)) != 0 {
+		t.Fatalf("expected no errors. got=%v", diagnostics)
+	}
+
+	fn := program.Statements[2].(*ast.LetStatement).Value.(*ast.FunctionLiteral)
+	ifExpression := fn.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	infix := ifExpression.Consequence.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.InfixExpression)
+
+	tests := []struct {
+		identifier    *ast.Identifier
+		expectedDepth int
+		expectedSlot  int
+	}{
+		{infix.Left.(*ast.Identifier), 2, 0},
+		{infix.Right.(*ast.Identifier), 1, 1},
+	}
+
+	for _, tt := range tests {
+		if !tt.identifier.IsResolved {
+			t.Fatalf("identifier %s is not resolved", tt.identifier.Value)
+		}
+
+		if tt.identifier.Depth != tt.expectedDepth || tt.identifier.Slot != tt.expectedSlot {
+			t.Errorf("identifier %s resolved wrong. want=(%d, %d), got=(%d, %d)", tt.identifier.Value,
+				tt.expectedDepth, tt.expectedSlot, tt.identifier.Depth, tt.identifier.Slot)
+		}
+	}
+
+	binding := resolution.Bindings[infix.Left.(*ast.Identifier)]
+
+	if binding.Name != "a" || binding.Kind != VariableBinding || len(binding.References) != 1 {
+		t.Errorf("wrong binding for a. got=%+v", binding)
+	}
+
+	if scope := resolution.Scopes[fn]; len(scope.Bindings()) != 2 || scope.Bindings()[1].Kind != ParameterBinding {
+		t.Errorf("wrong function scope. got=%+v", scope.Bindings())
+	}
+}
+
+func TestResolvedEvaluation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let x = 5 x", 5},
+		{"let add = fn (x, y) { x + y } add(2, 3)", 5},
+		{"let mut total = 0 let mut i = 0 for i < 4 { let n = i * 2 total = total + n i = i + 1 } total", 12},
+		{"let counter = fn () { let mut c = 0 fn () { c = c + 1 c } } let next = counter() next() next()", 2},
+		{"let fact = fn (n) { if n < 2 { 1 } else { n * fact(n - 1) } } fact(5)", 120},
+		{"let x = 1 let f = fn () { x } let x = 2 f()", 2},
+		{"let x = even(10) fn even(n) { if n == 0 { return 1 } odd(n - 1) } fn odd(n) { if n == 0 { return 0 } even(n - 1) } x", 1},
+		{"fn f() { let y = g() fn g() { 2 } let z = 3 y + z } f()", 5},
+		{"let f = fn (x, y = x + 1, ...rest) { x + y + rest.length() } f(1) + f(1, 5, 0, 0)", 11},
+		{"let f = fn (n, [x, [y], ...rest], z = x) { n + x + y + z + rest.length() } f(1, [2, [3], 0])", 9},
+		{"let mut total = 0 for [k, v] in [[1, 2], [3, 4]] { let n = k * v total = total + n } total", 14},
+		{"let [a, b] = [1, 2] let f = fn () { let [c] = [b] a + c } f()", 3},
+	}
+
+	for _, tt := range tests {
+		program := parseProgram(t, tt.input)
+
+		if _, diagnostics := NewResolver().Resolve(program); len(Errors(diagnostics)) != 0 {
+			t.Fatalf("expected no errors for %q. got=%v", tt.input, diagnostics)
+		}
+
+		evaluated := evaluator.Evaluate(program, object.NewEnvironment())
+		integer, ok := evaluated.(*object.Integer)
+
+		if !ok {
+			t.Errorf("object is not Integer for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if integer.Value != tt.expected {
+			t.Errorf("integer has wrong value for %q. want=%d, got=%d", tt.input, tt.expected, integer.Value)
+		}
+	}
+}
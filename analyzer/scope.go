@@ -0,0 +1,125 @@
+package analyzer
+
+import (
+	"fmt"
+	"go++/ast"
+	"go++/token"
+)
+
+type BindingKind int
+
+const (
+	VariableBinding BindingKind = iota
+	ParameterBinding
+	FunctionBinding
+)
+
+func (k BindingKind) String() string {
+	switch k {
+	case ParameterBinding:
+		return "parameter"
+	case FunctionBinding:
+		return "function"
+	}
+
+	return "variable"
+}
+
+type Binding struct {
+	Name      string
+	Kind      BindingKind
+	Token     token.Token
+	IsMutable bool
+	Slot      int
+	Scope     *Scope
+
+	// References holds every identifier that reads the binding, assignments aren't counted
+	References []*ast.Identifier
+}
+
+func (b *Binding) declaredAt() string {
+	// Bindings declared by the host environment have no position
+	if b.Token.Line == 0 {
+		return "predeclared"
+	}
+
+	return fmt.Sprintf("declared at %d:%d", b.Token.Line, b.Token.Column)
+}
+
+// Scope mirrors an object.Environment created at runtime, a binding's slot is its index in the environment
+type Scope struct {
+	Parent   *Scope
+	Children []*Scope
+	Node     ast.Node
+
+	bindings map[string]*Binding
+	ordered  []*Binding
+}
+
+func newScope(parent *Scope, node ast.Node) *Scope {
+	scope := &Scope{Parent: parent, Node: node, bindings: make(map[string]*Binding)}
+
+	if parent != nil {
+		parent.Children = append(parent.Children, scope)
+	}
+
+	return scope
+}
+
+// declare binds the identifier, redeclaring a name in the same scope reuses its slot like Environment.Set does
+func (s *Scope) declare(identifier *ast.Identifier, kind BindingKind, isMutable bool) *Binding {
+	slot := len(s.ordered)
+
+	if previous, ok := s.bindings[identifier.Value]; ok {
+		slot = previous.Slot
+	}
+
+	binding := &Binding{Name: identifier.Value, Kind: kind, Token: identifier.Token, IsMutable: isMutable, Slot: slot, Scope: s}
+	s.bindings[identifier.Value] = binding
+
+	if slot == len(s.ordered) {
+		s.ordered = append(s.ordered, binding)
+	} else {
+		s.ordered[slot] = binding
+	}
+
+	return binding
+}
+
+// Lookup returns the binding of name and how many scopes up it was declared
+func (s *Scope) Lookup(name string) (*Binding, int, bool) {
+	depth := 0
+
+	for scope := s; scope != nil; scope = scope.Parent {
+		if binding, ok := scope.bindings[name]; ok {
+			return binding, depth, true
+		}
+
+		depth += 1
+	}
+
+	return nil, -1, false
+}
+
+// Bindings returns the bindings of this scope ordered by slot
+func (s *Scope) Bindings() []*Binding {
+	return s.ordered
+}
+
+func (s *Scope) isGlobal() bool {
+	return s.Parent == nil
+}
+
+// rootIdentifier returns the identifier an assignee like arr[0][1] or point.x is rooted in
+func rootIdentifier(expression ast.Expression) (*ast.Identifier, bool) {
+	switch expression := expression.(type) {
+	case *ast.Identifier:
+		return expression, true
+	case *ast.ArrayAccessExpression:
+		return rootIdentifier(expression.Expression)
+	case *ast.MemberAccessExpression:
+		return rootIdentifier(expression.Expression)
+	default:
+		return nil, false
+	}
+}
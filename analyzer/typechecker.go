@@ -0,0 +1,540 @@
+package analyzer
+
+import (
+	"go++/ast"
+	"go++/evaluator"
+)
+
+type pendingTypedFunction struct {
+	literal   *ast.FunctionLiteral
+	signature *Type
+
+	// parameters are the types of all parameters, the signature leaves out the rest parameter
+	parameters []*Type
+}
+
+// TypeChecker infers the types of expressions locally and reports values that can't match
+// the optional annotations on let bindings and function signatures, or operators the evaluator would reject
+type TypeChecker struct {
+	types       map[*Binding]*Type
+	resolution  *Resolution
+	pending     []pendingTypedFunction
+	results     []*Type
+	diagnostics []Diagnostic
+}
+
+func NewTypeChecker() *TypeChecker {
+	return &TypeChecker{types: make(map[*Binding]*Type)}
+}
+
+// Check expects the program to be resolved, types of top level bindings are kept between calls
+func (c *TypeChecker) Check(program *ast.Program, resolution *Resolution) []Diagnostic {
+	_, diagnostics := c.check(program, resolution)
+
+	return diagnostics
+}
+
+// check returns the type of the program's result next to the diagnostics
+func (c *TypeChecker) check(program *ast.Program, resolution *Resolution) (*Type, []Diagnostic) {
+	c.resolution = resolution
+	c.diagnostics = []Diagnostic{}
+
+	result := c.checkStatements(program.Statements)
+
+	// Function bodies are checked last, like they are resolved, so the types of bindings declared
+	// after the function are known
+	for len(c.pending) > 0 {
+		fn := c.pending[0]
+		c.pending = c.pending[1:]
+
+		c.checkFunctionBody(fn)
+	}
+
+	sortDiagnostics(c.diagnostics)
+
+	return result, c.diagnostics
+}
+
+// TypeOf returns the type a binding was given, or any if it couldn't be inferred
+func (c *TypeChecker) TypeOf(binding *Binding) *Type {
+	if t, ok := c.types[binding]; ok {
+		return t
+	}
+
+	return unknownType
+}
+
+func (c *TypeChecker) checkStatements(statements []ast.Statement) *Type {
+	result := nullType
+
+	// Declared functions are hoisted, their signatures are known before the statements that call them
+	for _, statement := range statements {
+		if declaration, ok := statement.(*ast.FunctionStatement); ok {
+			signature := c.checkFunctionLiteral(declaration.Function)
+
+			if binding, ok := c.resolution.Bindings[declaration.Function.Name]; ok {
+				c.types[binding] = signature
+			}
+		}
+	}
+
+	for _, statement := range statements {
+		result = c.checkStatement(statement)
+	}
+
+	return result
+}
+
+// checkStatement returns the type of the value the statement produces as the last statement of a block
+func (c *TypeChecker) checkStatement(statement ast.Statement) *Type {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		if statement == nil {
+			return unknownType
+		}
+
+		c.checkLetStatement(statement)
+
+		return nullType
+	case *ast.FunctionStatement:
+		return nullType
+	case *ast.ReturnStatement:
+		valueType := c.checkExpression(statement.ReturnValue)
+
+		if len(c.results) > 0 {
+			c.checkResult(statement.ReturnValue, valueType)
+		}
+
+		return unknownType
+	case *ast.ExpressionStatement:
+		return c.checkExpression(statement.Expression)
+	}
+
+	return unknownType
+}
+
+func (c *TypeChecker) checkLetStatement(statement *ast.LetStatement) {
+	valueType := c.checkExpression(statement.Value)
+	annotated := c.annotationType(statement.Type)
+
+	if statement.Type != nil && !valueType.assignableTo(annotated) {
+		c.diagnostics = append(c.diagnostics, newError(ast.StartToken(statement.Value),
+			"cannot use %s value as %s in binding of %s", valueType, annotated, statement.Target()))
+	}
+
+	switch {
+	case statement.Type != nil:
+		c.bindPatternTypes(statement.Target(), annotated)
+	case !statement.IsMutable:
+		c.bindPatternTypes(statement.Target(), valueType)
+	default:
+		// Unannotated mutable bindings can be reassigned to values of any type, the value is still
+		// checked to be something that can be destructured
+		c.bindPatternTypes(statement.Target(), valueType)
+
+		for _, identifier := range ast.PatternIdentifiers(statement.Target()) {
+			if binding, ok := c.resolution.Bindings[identifier]; ok {
+				c.types[binding] = unknownType
+			}
+		}
+	}
+}
+
+// bindPatternTypes gives the names of a pattern the types of the parts of a value of type t
+func (c *TypeChecker) bindPatternTypes(pattern ast.Pattern, t *Type) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if binding, ok := c.resolution.Bindings[pattern]; ok {
+			c.types[binding] = t
+		}
+	case *ast.ArrayPattern:
+		element := unknownType
+
+		if t.isKnown() && t.Kind != ArrayType {
+			c.diagnostics = append(c.diagnostics, newError(pattern.Token, "cannot destructure %s value into %s", t, pattern))
+		} else if t.isKnown() {
+			element = t.Element
+		}
+
+		for _, e := range pattern.Elements {
+			c.bindPatternTypes(e, element)
+		}
+
+		if pattern.Rest != nil {
+			c.bindPatternTypes(pattern.Rest, newArrayType(element))
+		}
+	}
+}
+
+func (c *TypeChecker) checkExpression(expression ast.Expression) *Type {
+	switch expression := expression.(type) {
+	case *ast.IntegerLiteral:
+		return intType
+	case *ast.StringLiteral:
+		return stringType
+	case *ast.InterpolatedString:
+		for _, embedded := range expression.Expressions {
+			c.checkExpression(embedded)
+		}
+
+		return stringType
+	case *ast.BooleanLiteral:
+		return boolType
+	case *ast.NullLiteral:
+		return nullType
+	case *ast.ArrayLiteral:
+		return c.checkArrayLiteral(expression)
+	case *ast.Identifier:
+		if evaluator.IsBuiltin(expression.Value) {
+			return namedTypes["fn"]
+		}
+
+		if binding, ok := c.resolution.Bindings[expression]; ok {
+			return c.TypeOf(binding)
+		}
+
+		return unknownType
+	case *ast.FunctionLiteral:
+		return c.checkFunctionLiteral(expression)
+	case *ast.PrefixExpression:
+		return c.checkPrefixExpression(expression)
+	case *ast.InfixExpression:
+		return c.checkInfixExpression(expression)
+	case *ast.CallExpression:
+		return c.checkCallExpression(expression)
+	case *ast.SpreadExpression:
+		c.checkExpression(expression.Value)
+
+		return unknownType
+	case *ast.NamedArgument:
+		return c.checkExpression(expression.Value)
+	case *ast.AssignExpression:
+		return c.checkAssignExpression(expression)
+	case *ast.ArrayAccessExpression:
+		return c.checkArrayAccessExpression(expression)
+	case *ast.MemberAccessExpression:
+		left := c.checkExpression(expression.Expression)
+
+		// Only strings, arrays and integers have members, ?. is fine on null
+		if left.Kind == FunctionType || left.Kind == NullType && !expression.IsOptional {
+			c.diagnostics = append(c.diagnostics, newError(expression.AccessedMember.Token,
+				"cannot access member %s of %s value %s", expression.AccessedMember.Value, left, expression.Expression.String()))
+		}
+
+		return unknownType
+	case *ast.IfExpression:
+		return c.checkIfExpression(expression)
+	case *ast.ForLoopLiteral:
+		c.checkExpression(expression.Condition)
+		c.checkStatements(expression.Body.Statements)
+
+		return nullType
+	case *ast.ForInLoopLiteral:
+		c.checkForInLoop(expression)
+
+		return nullType
+	}
+
+	return unknownType
+}
+
+func (c *TypeChecker) checkForInLoop(expression *ast.ForInLoopLiteral) {
+	iterable := c.checkExpression(expression.Iterable)
+	element := unknownType
+
+	if iterable.isKnown() && iterable.Kind != ArrayType {
+		c.diagnostics = append(c.diagnostics, newError(ast.StartToken(expression.Iterable),
+			"cannot iterate over %s value", iterable))
+	} else if iterable.isKnown() {
+		element = iterable.Element
+	}
+
+	c.bindPatternTypes(expression.Pattern, element)
+	c.checkStatements(expression.Body.Statements)
+}
+
+func (c *TypeChecker) checkArrayLiteral(expression *ast.ArrayLiteral) *Type {
+	var element *Type
+
+	for _, value := range expression.Values {
+		valueType := c.checkExpression(value)
+
+		if element == nil {
+			element = valueType
+		} else if element.Kind != valueType.Kind {
+			element = unknownType
+		}
+	}
+
+	if element == nil {
+		element = unknownType
+	}
+
+	return newArrayType(element)
+}
+
+func (c *TypeChecker) checkFunctionLiteral(expression *ast.FunctionLiteral) *Type {
+	signature := &Type{Kind: FunctionType, Parameters: []*Type{}, Result: c.annotationType(expression.ReturnType)}
+
+	parameters := []*Type{}
+
+	// The rest parameter isn't part of the signature, it is the array of the arguments after the others
+	for _, parameter := range expression.Parameters {
+		parameterType := c.annotationType(parameter.Type)
+
+		if parameter.IsRest {
+			if parameter.Type == nil {
+				parameterType = newArrayType(unknownType)
+			}
+		} else {
+			signature.Parameters = append(signature.Parameters, parameterType)
+		}
+
+		parameters = append(parameters, parameterType)
+	}
+
+	c.pending = append(c.pending, pendingTypedFunction{literal: expression, signature: signature, parameters: parameters})
+
+	return signature
+}
+
+func (c *TypeChecker) checkFunctionBody(fn pendingTypedFunction) {
+	for i, parameter := range fn.literal.Parameters {
+		if parameter.Default != nil {
+			if value := c.checkExpression(parameter.Default); !value.assignableTo(fn.parameters[i]) {
+				c.diagnostics = append(c.diagnostics, newError(ast.StartToken(parameter.Default),
+					"cannot use %s value as default of %s parameter %s", value, fn.parameters[i], parameter.Target()))
+			}
+		}
+
+		c.bindPatternTypes(parameter.Target(), fn.parameters[i])
+	}
+
+	c.results = append(c.results, fn.signature.Result)
+
+	statements := fn.literal.Body.Statements
+	result := c.checkStatements(statements)
+
+	// The value of the last expression is returned implicitly
+	if len(statements) > 0 {
+		if last, ok := statements[len(statements)-1].(*ast.ExpressionStatement); ok && last.Expression != nil {
+			c.checkResult(last.Expression, result)
+		}
+	}
+
+	c.results = c.results[:len(c.results)-1]
+}
+
+func (c *TypeChecker) checkResult(expression ast.Expression, valueType *Type) {
+	expected := c.results[len(c.results)-1]
+
+	if !valueType.assignableTo(expected) {
+		c.diagnostics = append(c.diagnostics, newError(ast.StartToken(expression),
+			"cannot return %s value from function returning %s", valueType, expected))
+	}
+}
+
+func (c *TypeChecker) checkPrefixExpression(expression *ast.PrefixExpression) *Type {
+	right := c.checkExpression(expression.Right)
+
+	switch expression.Operator {
+	case "!":
+		return boolType
+	case "-":
+		if right.isKnown() && right.Kind != IntType {
+			c.diagnostics = append(c.diagnostics, newError(expression.Token, "invalid operation: -%s", right))
+		}
+
+		return intType
+	}
+
+	return unknownType
+}
+
+// checkInfixExpression follows evaluateInfixExpression, strings and integers can be mixed
+func (c *TypeChecker) checkInfixExpression(expression *ast.InfixExpression) *Type {
+	left := c.checkExpression(expression.Left)
+	right := c.checkExpression(expression.Right)
+	operator := expression.Operator
+
+	if operator == "==" || operator == "!=" {
+		return boolType
+	}
+
+	if operator == "??" {
+		return coalescedType(left, right)
+	}
+
+	if !left.isKnown() || !right.isKnown() {
+		if operator == "<" || operator == ">" {
+			return boolType
+		}
+
+		return unknownType
+	}
+
+	isText := func(t *Type) bool { return t.Kind == StringType || t.Kind == IntType }
+
+	switch {
+	case left.Kind == IntType && right.Kind == IntType:
+		switch operator {
+		case "+", "-", "*", "/":
+			return intType
+		case "<", ">":
+			return boolType
+		}
+	case isText(left) && isText(right):
+		switch operator {
+		case "+":
+			return stringType
+		case "<", ">":
+			return boolType
+		}
+	}
+
+	if left.Kind != right.Kind {
+		c.diagnostics = append(c.diagnostics, newError(expression.Token,
+			"type mismatch: %s %s %s", left, operator, right))
+	} else {
+		c.diagnostics = append(c.diagnostics, newError(expression.Token,
+			"invalid operation: operator %s not defined on %s", operator, left))
+	}
+
+	return unknownType
+}
+
+// coalescedType is the type of left ?? right, a value of a known type other than null is never null
+func coalescedType(left *Type, right *Type) *Type {
+	switch {
+	case left.Kind == NullType:
+		return right
+	case left.isKnown():
+		return left
+	}
+
+	return unknownType
+}
+
+func (c *TypeChecker) checkCallExpression(expression *ast.CallExpression) *Type {
+	function := c.checkExpression(expression.Function)
+	arguments := make([]*Type, len(expression.Arguments))
+
+	for i, argument := range expression.Arguments {
+		arguments[i] = c.checkExpression(argument)
+	}
+
+	if !function.isKnown() {
+		return unknownType
+	}
+
+	if function.Kind != FunctionType {
+		c.diagnostics = append(c.diagnostics, newError(ast.StartToken(expression.Function),
+			"cannot call %s value %s", function, expression.Function.String()))
+
+		return unknownType
+	}
+
+	for i, parameter := range function.Parameters {
+		// Spread and named arguments don't line up with the parameters
+		if i >= len(arguments) || isSpreadOrNamed(expression.Arguments[i]) {
+			break
+		}
+
+		if !arguments[i].assignableTo(parameter) {
+			c.diagnostics = append(c.diagnostics, newError(ast.StartToken(expression.Arguments[i]),
+				"cannot use %s value as %s in argument %d to %s", arguments[i], parameter, i+1, expression.Function.String()))
+		}
+	}
+
+	return function.Result
+}
+
+func isSpreadOrNamed(argument ast.Expression) bool {
+	switch argument.(type) {
+	case *ast.SpreadExpression, *ast.NamedArgument:
+		return true
+	}
+
+	return false
+}
+
+func (c *TypeChecker) checkAssignExpression(expression *ast.AssignExpression) *Type {
+	value := c.checkExpression(expression.Value)
+
+	switch assignee := expression.Assignee.(type) {
+	case *ast.Identifier:
+		binding, ok := c.resolution.Bindings[assignee]
+
+		if !ok {
+			return value
+		}
+
+		if expected := c.TypeOf(binding); !value.assignableTo(expected) {
+			c.diagnostics = append(c.diagnostics, newError(ast.StartToken(expression.Value),
+				"cannot assign %s value to %s of type %s", value, assignee.Value, expected))
+		}
+	case *ast.ArrayAccessExpression:
+		array := c.checkArrayAccessExpression(assignee)
+
+		if !value.assignableTo(array) {
+			c.diagnostics = append(c.diagnostics, newError(ast.StartToken(expression.Value),
+				"cannot assign %s value to element of type %s", value, array))
+		}
+	default:
+		c.checkExpression(assignee)
+	}
+
+	return value
+}
+
+func (c *TypeChecker) checkArrayAccessExpression(expression *ast.ArrayAccessExpression) *Type {
+	array := c.checkExpression(expression.Expression)
+	index := c.checkExpression(expression.Index)
+
+	if index.isKnown() && index.Kind != IntType {
+		c.diagnostics = append(c.diagnostics, newError(ast.StartToken(expression.Index),
+			"invalid array index of type %s", index))
+	}
+
+	if !array.isKnown() || expression.IsOptional && array.Kind == NullType {
+		return unknownType
+	}
+
+	if array.Kind != ArrayType {
+		c.diagnostics = append(c.diagnostics, newError(ast.StartToken(expression.Expression),
+			"cannot index %s value %s", array, expression.Expression.String()))
+
+		return unknownType
+	}
+
+	return array.Element
+}
+
+// checkIfExpression only knows the type of the value when both branches produce the same type
+func (c *TypeChecker) checkIfExpression(expression *ast.IfExpression) *Type {
+	c.checkExpression(expression.Condition)
+
+	consequence := c.checkStatements(expression.Consequence.Statements)
+
+	if expression.Alternative == nil {
+		return unknownType
+	}
+
+	alternative := c.checkStatements(expression.Alternative.Statements)
+
+	if consequence.isKnown() && alternative.isKnown() && consequence.String() == alternative.String() {
+		return consequence
+	}
+
+	return unknownType
+}
+
+func (c *TypeChecker) annotationType(annotation *ast.TypeAnnotation) *Type {
+	t, ok := typeFromAnnotation(annotation)
+
+	if !ok {
+		c.diagnostics = append(c.diagnostics, newError(annotation.Token, "unknown type %s", annotation.String()))
+	}
+
+	return t
+}
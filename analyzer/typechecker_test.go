@@ -0,0 +1,79 @@
+package analyzer
+
+import "testing"
+
+func TestTypeChecker(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x: int = 1", []string{}},
+		{`let x: int = "a"`, []string{"1:14: cannot use string value as int in binding of x"}},
+		{"let xs: [int] = [1, 2]", []string{}},
+		{`let xs: [int] = [1, "a"]`, []string{}},
+		{`let xs: [int] = ["a"]`, []string{"1:17: cannot use [string] value as [int] in binding of xs"}},
+		{"let x: number = 1", []string{"1:8: unknown type number"}},
+		{"let mut x: int = 1 x = true", []string{"1:24: cannot assign bool value to x of type int"}},
+		{"let mut x = 1 x = true", []string{}},
+		{"let x = 1 let y: bool = x", []string{"1:25: cannot use int value as bool in binding of y"}},
+		{"1 + true", []string{"1:3: type mismatch: int + bool"}},
+		{"true + false", []string{"1:6: invalid operation: operator + not defined on bool"}},
+		{`"a" + 1`, []string{}},
+		{`let s: string = "a" + 1`, []string{}},
+		{`"a" - "b"`, []string{"1:5: invalid operation: operator - not defined on string"}},
+		{"-true", []string{"1:1: invalid operation: -bool"}},
+		{"let f = fn (a: int) { a } f(1)", []string{}},
+		{`let f = fn (a: int) { a } f("a")`, []string{"1:29: cannot use string value as int in argument 1 to f"}},
+		{"let f = fn (a: int) -> bool { a }", []string{"1:31: cannot return int value from function returning bool"}},
+		{"let f = fn (a: int) -> bool { return a > 1 }", []string{}},
+		{"let f = fn (a) -> bool { if a { return 1 } true }", []string{"1:40: cannot return int value from function returning bool"}},
+		{"let f = fn () -> int { if true { 1 } else { 2 } }", []string{}},
+		{"let f = fn () -> int { 1 } let x: string = f()", []string{"1:44: cannot use int value as string in binding of x"}},
+		{"let f = fn () { g(true) } let g = fn (b: int) { b }", []string{"1:19: cannot use bool value as int in argument 1 to g"}},
+		{"let x = 1 x()", []string{"1:11: cannot call int value x"}},
+		{"let n: null = null", []string{}},
+		{"let x: int = null", []string{"1:14: cannot use null value as int in binding of x"}},
+		{"let x: int = null ?? 1", []string{}},
+		{`let x: int = 1 ?? "a"`, []string{}},
+		{`let x: int = null ?? "a"`, []string{"1:14: cannot use string value as int in binding of x"}},
+		{"let n = null n.length()", []string{"1:16: cannot access member length of null value n"}},
+		{"let n = null n?.length() n?.[0]", []string{}},
+		{"let n = null n[0]", []string{"1:14: cannot index null value n"}},
+		{"let f = fn () { 1 } f.x", []string{"1:23: cannot access member x of fn () -> any value f"}},
+		{`let xs = [1] xs["a"]`, []string{"1:17: invalid array index of type string"}},
+		{`let xs = [1] let s: string = xs[0]`, []string{"1:30: cannot use int value as string in binding of s"}},
+		{"let apply = fn (f: fn, x: int) { f(x) } apply(fn (a: int) -> int { a }, 1)", []string{}},
+		{`let f = fn (a: int = "a") { a }`, []string{"1:22: cannot use string value as default of int parameter a"}},
+		{"let f = fn (a: int, b = a) -> int { b }", []string{}},
+		{"let f = fn (...xs) -> int { xs }", []string{"1:29: cannot return array value from function returning int"}},
+		{"let f = fn (...xs: [int]) { let x: string = xs[0] }", []string{"1:45: cannot use int value as string in binding of x"}},
+		{`let f = fn (a: int, b: int) { a } let xs = ["a"] f(1, ...xs) f(b: "a", a: 1)`, []string{}},
+		{`f("a") fn f(a: int) -> int { a }`, []string{"1:3: cannot use string value as int in argument 1 to f"}},
+		{`fn f() -> int { 1 } let s: string = f()`, []string{"1:37: cannot use int value as string in binding of s"}},
+		{`let [a, b] = [1, 2] let s: string = a`, []string{"1:37: cannot use int value as string in binding of s"}},
+		{`let [a, ...rest] = ["a"] let xs: [int] = rest`, []string{"1:42: cannot use [string] value as [int] in binding of xs"}},
+		{"let [a] = 1", []string{"1:5: cannot destructure int value into [a]"}},
+		{"let mut [a] = [1] a = true", []string{}},
+		{"let f = fn ([a, b]: int) { a }", []string{"1:13: cannot destructure int value into [a, b]"}},
+		{"let f = fn ([a]: [string]) -> int { a }", []string{"1:37: cannot return string value from function returning int"}},
+		{"for x in 1 { x }", []string{"1:10: cannot iterate over int value"}},
+		{`let n = 1 let s: string = "${n}" let m: int = "${n}"`, []string{"1:47: cannot use string value as int in binding of m"}},
+		{`"${1 + true}"`, []string{"1:6: type mismatch: int + bool"}},
+		{`for x in ["a"] { let n: int = x }`, []string{"1:31: cannot use string value as int in binding of n"}},
+	}
+
+	for _, tt := range tests {
+		diagnostics := Errors(New().Analyze(parseProgram(t, tt.input)))
+
+		if len(diagnostics) != len(tt.expected) {
+			t.Errorf("wrong number of diagnostics for %q. want=%d, got=%d (%v)", tt.input, len(tt.expected), len(diagnostics), diagnostics)
+			continue
+		}
+
+		for i, diagnostic := range diagnostics {
+			if diagnostic.String() != tt.expected[i] {
+				t.Errorf("wrong diagnostic for %q. want=%q, got=%q", tt.input, tt.expected[i], diagnostic.String())
+			}
+		}
+	}
+}
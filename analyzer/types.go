@@ -0,0 +1,144 @@
+package analyzer
+
+import (
+	"go++/ast"
+	"strings"
+)
+
+type TypeKind int
+
+const (
+	UnknownType TypeKind = iota
+	IntType
+	StringType
+	BoolType
+	NullType
+	ArrayType
+	FunctionType
+)
+
+// Type is the statically known type of a value, UnknownType is compatible with every other type
+type Type struct {
+	Kind TypeKind
+
+	// Element is the type of the elements of an array
+	Element *Type
+
+	// Parameters is nil when the signature of the function isn't known
+	Parameters []*Type
+	Result     *Type
+}
+
+var (
+	unknownType = &Type{Kind: UnknownType}
+	intType     = &Type{Kind: IntType}
+	stringType  = &Type{Kind: StringType}
+	boolType    = &Type{Kind: BoolType}
+	nullType    = &Type{Kind: NullType}
+)
+
+var namedTypes = map[string]*Type{
+	"any":    unknownType,
+	"int":    intType,
+	"string": stringType,
+	"bool":   boolType,
+	"null":   nullType,
+	"array":  {Kind: ArrayType, Element: unknownType},
+	"fn":     {Kind: FunctionType, Result: unknownType},
+}
+
+func newArrayType(element *Type) *Type {
+	return &Type{Kind: ArrayType, Element: element}
+}
+
+func (t *Type) String() string {
+	switch t.Kind {
+	case IntType:
+		return "int"
+	case StringType:
+		return "string"
+	case BoolType:
+		return "bool"
+	case NullType:
+		return "null"
+	case ArrayType:
+		if t.Element.Kind == UnknownType {
+			return "array"
+		}
+
+		return "[" + t.Element.String() + "]"
+	case FunctionType:
+		if t.Parameters == nil {
+			return "fn"
+		}
+
+		params := []string{}
+
+		for _, parameter := range t.Parameters {
+			params = append(params, parameter.String())
+		}
+
+		return "fn (" + strings.Join(params, ", ") + ") -> " + t.Result.String()
+	default:
+		return "any"
+	}
+}
+
+func (t *Type) isKnown() bool {
+	return t.Kind != UnknownType
+}
+
+// assignableTo reports whether a value of type t can be used where `to` is expected
+func (t *Type) assignableTo(to *Type) bool {
+	if !t.isKnown() || !to.isKnown() {
+		return true
+	}
+
+	if t.Kind != to.Kind {
+		return false
+	}
+
+	switch t.Kind {
+	case ArrayType:
+		return t.Element.assignableTo(to.Element)
+	case FunctionType:
+		if t.Parameters == nil || to.Parameters == nil {
+			return true
+		}
+
+		if len(t.Parameters) != len(to.Parameters) {
+			return false
+		}
+
+		for i, parameter := range t.Parameters {
+			if !to.Parameters[i].assignableTo(parameter) {
+				return false
+			}
+		}
+
+		return t.Result.assignableTo(to.Result)
+	}
+
+	return true
+}
+
+// typeFromAnnotation returns false when the annotation names a type that doesn't exist
+func typeFromAnnotation(annotation *ast.TypeAnnotation) (*Type, bool) {
+	if annotation == nil {
+		return unknownType, true
+	}
+
+	if annotation.Element != nil {
+		element, ok := typeFromAnnotation(annotation.Element)
+
+		return newArrayType(element), ok
+	}
+
+	t, ok := namedTypes[annotation.Name]
+
+	if !ok {
+		return unknownType, false
+	}
+
+	return t, true
+}
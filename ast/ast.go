@@ -13,6 +13,11 @@ type Node interface {
 type Identifier struct {
 	Token token.Token
 	Value string
+
+	// Set by the resolver, the binding lives Depth environments up at index Slot
+	Depth      int
+	Slot       int
+	IsResolved bool
 }
 
 func (i *Identifier) expressionNode() {}
@@ -42,3 +47,65 @@ func (p *Program) String() string {
 
 	return out.String()
 }
+
+// StartToken returns the token a node starts at in the source, an infix expression starts at its left operand
+func StartToken(node Node) token.Token {
+	switch node := node.(type) {
+	case *InfixExpression:
+		return StartToken(node.Left)
+	case *CallExpression:
+		return StartToken(node.Function)
+	case *MemberAccessExpression:
+		return StartToken(node.Expression)
+	case *ArrayAccessExpression:
+		return StartToken(node.Expression)
+	case *AssignExpression:
+		return StartToken(node.Assignee)
+	case *SpreadExpression:
+		return node.Token
+	case *NamedArgument:
+		return node.Token
+	case *ExpressionStatement:
+		return StartToken(node.Expression)
+	case *Program:
+		if len(node.Statements) > 0 {
+			return StartToken(node.Statements[0])
+		}
+	case *Identifier:
+		return node.Token
+	case *PrefixExpression:
+		return node.Token
+	case *IfExpression:
+		return node.Token
+	case *IntegerLiteral:
+		return node.Token
+	case *BooleanLiteral:
+		return node.Token
+	case *NullLiteral:
+		return node.Token
+	case *StringLiteral:
+		return node.Token
+	case *InterpolatedString:
+		return node.Token
+	case *ArrayLiteral:
+		return node.Token
+	case *FunctionLiteral:
+		return node.Token
+	case *ForLoopLiteral:
+		return node.Token
+	case *ForInLoopLiteral:
+		return node.Token
+	case *ArrayPattern:
+		return node.Token
+	case *LetStatement:
+		return node.Token
+	case *FunctionStatement:
+		return node.Token
+	case *ReturnStatement:
+		return node.Token
+	case *BlockStatement:
+		return node.Token
+	}
+
+	return token.Token{}
+}
@@ -0,0 +1,85 @@
+package ast
+
+import (
+	"fmt"
+	"go++/token"
+	"io"
+	"reflect"
+	"strings"
+)
+
+var tokenType = reflect.TypeOf(token.Token{})
+
+// Dump writes the tree below node with one field per line, nodes are followed by their position
+func Dump(w io.Writer, node Node) error {
+	d := &dumper{w: w}
+	d.value("", reflect.ValueOf(node), 0)
+
+	return d.err
+}
+
+type dumper struct {
+	w   io.Writer
+	err error
+}
+
+func (d *dumper) printf(indent int, format string, a ...interface{}) {
+	if d.err != nil {
+		return
+	}
+
+	_, d.err = fmt.Fprintf(d.w, strings.Repeat("  ", indent)+format+"\n", a...)
+}
+
+func (d *dumper) value(label string, v reflect.Value, indent int) {
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			d.printf(indent, "%snil", label)
+			return
+		}
+
+		d.value(label, v.Elem(), indent)
+	case reflect.Struct:
+		d.node(label, v, indent)
+	case reflect.Slice:
+		if v.Len() == 0 {
+			d.printf(indent, "%s[]", label)
+			return
+		}
+
+		d.printf(indent, "%s[", label)
+
+		for i := 0; i < v.Len(); i++ {
+			d.value("", v.Index(i), indent+1)
+		}
+
+		d.printf(indent, "]")
+	case reflect.String:
+		d.printf(indent, "%s%q", label, v.String())
+	default:
+		d.printf(indent, "%s%v", label, v.Interface())
+	}
+}
+
+func (d *dumper) node(label string, v reflect.Value, indent int) {
+	t := v.Type()
+	position := ""
+
+	if tok := v.FieldByName("Token"); tok.IsValid() && tok.Type() == tokenType {
+		position = fmt.Sprintf(" %d:%d", tok.FieldByName("Line").Int(), tok.FieldByName("Column").Int())
+	}
+
+	d.printf(indent, "%s%s%s", label, t.Name(), position)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// Tokens are summed up by the position and the resolver's annotations aren't part of the tree
+		if field.Type == tokenType || field.Name == "Depth" || field.Name == "Slot" || field.Name == "IsResolved" {
+			continue
+		}
+
+		d.value(field.Name+": ", v.Field(i), indent+1)
+	}
+}
@@ -82,6 +82,7 @@ type CallExpression struct {
 	Token     token.Token
 	Function  Expression
 	Arguments []Expression
+	EndToken  token.Token
 }
 
 func (c *CallExpression) expressionNode()      {}
@@ -103,6 +104,27 @@ func (c *CallExpression) String() string {
 	return out.String()
 }
 
+// SpreadExpression is `...array` in the arguments of a call, the elements are passed as separate arguments
+type SpreadExpression struct {
+	Token token.Token
+	Value Expression
+}
+
+func (s *SpreadExpression) expressionNode()      {}
+func (s *SpreadExpression) TokenLiteral() string { return s.Token.Literal }
+func (s *SpreadExpression) String() string       { return "..." + s.Value.String() }
+
+// NamedArgument is `name: value` in the arguments of a call, it is bound to the parameter of that name
+type NamedArgument struct {
+	Token token.Token
+	Name  *Identifier
+	Value Expression
+}
+
+func (n *NamedArgument) expressionNode()      {}
+func (n *NamedArgument) TokenLiteral() string { return n.Token.Literal }
+func (n *NamedArgument) String() string       { return n.Name.String() + ": " + n.Value.String() }
+
 type AssignExpression struct {
 	Token    token.Token
 	Assignee Expression
@@ -115,10 +137,12 @@ func (a *AssignExpression) String() string {
 	return a.Assignee.String() + " = " + a.Value.String()
 }
 
+// MemberAccessExpression is a.b, or a?.b when IsOptional, which ends the chain it is in with null when a is null
 type MemberAccessExpression struct {
 	Token          token.Token
 	Expression     Expression
 	AccessedMember Identifier
+	IsOptional     bool
 }
 
 func (ma *MemberAccessExpression) expressionNode()      {}
@@ -128,17 +152,19 @@ func (ma *MemberAccessExpression) String() string {
 
 	out.WriteString("(")
 	out.WriteString(ma.Expression.String())
-	out.WriteString(".")
+	out.WriteString(ma.Token.Literal)
 	out.WriteString(ma.AccessedMember.Value)
 	out.WriteString(")")
 
 	return out.String()
 }
 
+// ArrayAccessExpression is a[i], or a?.[i] when IsOptional
 type ArrayAccessExpression struct {
 	Token      token.Token
 	Expression Expression
 	Index      Expression
+	IsOptional bool
 }
 
 func (aa *ArrayAccessExpression) expressionNode()      {}
@@ -146,7 +172,13 @@ func (aa *ArrayAccessExpression) TokenLiteral() string { return aa.Token.Literal
 func (aa *ArrayAccessExpression) String() string {
 	var out bytes.Buffer
 
-	out.WriteString(aa.Expression.String() + "[" + aa.Index.String() + "]")
+	out.WriteString(aa.Expression.String())
+
+	if aa.IsOptional {
+		out.WriteString("?.")
+	}
+
+	out.WriteString("[" + aa.Index.String() + "]")
 
 	return out.String()
 }
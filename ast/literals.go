@@ -24,9 +24,18 @@ func (bl *BooleanLiteral) expressionNode()      {}
 func (bl *BooleanLiteral) TokenLiteral() string { return bl.Token.Literal }
 func (bl *BooleanLiteral) String() string       { return bl.Token.Literal }
 
+type NullLiteral struct {
+	Token token.Token
+}
+
+func (nl *NullLiteral) expressionNode()      {}
+func (nl *NullLiteral) TokenLiteral() string { return nl.Token.Literal }
+func (nl *NullLiteral) String() string       { return nl.Token.Literal }
+
 type ArrayLiteral struct {
-	Token  token.Token
-	Values []Expression
+	Token    token.Token
+	Values   []Expression
+	EndToken token.Token
 }
 
 func (a *ArrayLiteral) expressionNode()      {}
@@ -48,8 +57,12 @@ func (a *ArrayLiteral) String() string {
 }
 
 type FunctionLiteral struct {
-	Token      token.Token
-	Parameters []*Identifier
+	Token token.Token
+
+	// Name is only set for functions declared with a FunctionStatement
+	Name       *Identifier
+	Parameters []*Parameter
+	ReturnType *TypeAnnotation
 	Body       *BlockStatement
 }
 
@@ -60,6 +73,10 @@ func (fl *FunctionLiteral) String() string {
 
 	out.WriteString("fn ")
 
+	if fl.Name != nil {
+		out.WriteString(fl.Name.String())
+	}
+
 	params := []string{}
 
 	for _, p := range fl.Parameters {
@@ -70,6 +87,10 @@ func (fl *FunctionLiteral) String() string {
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(")")
 
+	if fl.ReturnType != nil {
+		out.WriteString(" -> " + fl.ReturnType.String())
+	}
+
 	out.WriteString(fl.Body.String())
 
 	return out.String()
@@ -78,12 +99,38 @@ func (fl *FunctionLiteral) String() string {
 type StringLiteral struct {
 	Token token.Token
 	Value string
+
+	// IsRaw is set for strings between backticks, they are printed back the same way
+	IsRaw bool
 }
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
 func (sl *StringLiteral) String() string       { return sl.Value }
 
+// InterpolatedString is `"text ${expression} text"`, the text before and after every embedded expression
+// is in Strings so there is one more of them than there are expressions
+type InterpolatedString struct {
+	Token       token.Token
+	Strings     []*StringLiteral
+	Expressions []Expression
+}
+
+func (is *InterpolatedString) expressionNode()      {}
+func (is *InterpolatedString) TokenLiteral() string { return is.Token.Literal }
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+
+	for i, expression := range is.Expressions {
+		out.WriteString(is.Strings[i].String())
+		out.WriteString("${" + expression.String() + "}")
+	}
+
+	out.WriteString(is.Strings[len(is.Strings)-1].String())
+
+	return out.String()
+}
+
 type ForLoopLiteral struct {
 	Token     token.Token
 	Condition Expression
@@ -101,3 +148,25 @@ func (fl *ForLoopLiteral) String() string {
 
 	return out.String()
 }
+
+// ForInLoopLiteral is `for pattern in array { }`, the body runs for every element with the element bound to the pattern
+type ForInLoopLiteral struct {
+	Token    token.Token
+	Pattern  Pattern
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fl *ForInLoopLiteral) expressionNode()      {}
+func (fl *ForInLoopLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *ForInLoopLiteral) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("for ")
+	out.WriteString(fl.Pattern.String())
+	out.WriteString(" in ")
+	out.WriteString(fl.Iterable.String())
+	out.WriteString(fl.Body.String())
+
+	return out.String()
+}
@@ -0,0 +1,67 @@
+package ast
+
+import (
+	"bytes"
+	"go++/token"
+	"strings"
+)
+
+// Pattern is what a value is bound to, a name or an ArrayPattern taking the value apart
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+func (i *Identifier) patternNode() {}
+
+// ArrayPattern is `[a, [b, c], ...rest]`, it binds the elements of an array to the patterns at the same
+// index and the elements after them to Rest
+type ArrayPattern struct {
+	Token    token.Token
+	Elements []Pattern
+	Rest     *Identifier
+}
+
+func (ap *ArrayPattern) patternNode()         {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) String() string {
+	var out bytes.Buffer
+
+	elements := []string{}
+
+	for _, element := range ap.Elements {
+		elements = append(elements, element.String())
+	}
+
+	if ap.Rest != nil {
+		elements = append(elements, "..."+ap.Rest.String())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+// PatternIdentifiers returns the names a pattern binds in the order they are bound
+func PatternIdentifiers(pattern Pattern) []*Identifier {
+	switch pattern := pattern.(type) {
+	case *Identifier:
+		return []*Identifier{pattern}
+	case *ArrayPattern:
+		identifiers := []*Identifier{}
+
+		for _, element := range pattern.Elements {
+			identifiers = append(identifiers, PatternIdentifiers(element)...)
+		}
+
+		if pattern.Rest != nil {
+			identifiers = append(identifiers, pattern.Rest)
+		}
+
+		return identifiers
+	}
+
+	return nil
+}
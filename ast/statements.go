@@ -12,12 +12,25 @@ type Statement interface {
 }
 
 type LetStatement struct {
-	Token     token.Token
-	Name      *Identifier
+	Token token.Token
+	Name  *Identifier
+
+	// Pattern replaces Name when the value is destructured
+	Pattern   *ArrayPattern
+	Type      *TypeAnnotation
 	Value     Expression
 	IsMutable bool
 }
 
+// Target returns what the value is bound to
+func (ls *LetStatement) Target() Pattern {
+	if ls.Pattern != nil {
+		return ls.Pattern
+	}
+
+	return ls.Name
+}
+
 func (ls *LetStatement) statementNode() {}
 func (ls *LetStatement) TokenLiteral() string {
 	return ls.Token.Literal
@@ -26,7 +39,12 @@ func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
 	out.WriteString(ls.TokenLiteral() + " ")
-	out.WriteString(ls.Name.String())
+	out.WriteString(ls.Target().String())
+
+	if ls.Type != nil {
+		out.WriteString(": " + ls.Type.String())
+	}
+
 	out.WriteString(" = ")
 
 	if ls.Value != nil {
@@ -38,6 +56,17 @@ func (ls *LetStatement) String() string {
 	return out.String()
 }
 
+// FunctionStatement is `fn name(params) { }`, the function is bound to its name before the other
+// statements of its block run
+type FunctionStatement struct {
+	Token    token.Token
+	Function *FunctionLiteral
+}
+
+func (fs *FunctionStatement) statementNode()       {}
+func (fs *FunctionStatement) TokenLiteral() string { return fs.Token.Literal }
+func (fs *FunctionStatement) String() string       { return fs.Function.String() }
+
 type ReturnStatement struct {
 	Token       token.Token
 	ReturnValue Expression
@@ -76,6 +105,7 @@ func (es *ExpressionStatement) String() string {
 type BlockStatement struct {
 	Token      token.Token
 	Statements []Statement
+	EndToken   token.Token
 }
 
 func (bs *BlockStatement) statementNode()       {}
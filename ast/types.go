@@ -0,0 +1,58 @@
+package ast
+
+import "go++/token"
+
+// TypeAnnotation is an optional type like `int` or `[string]` written after a binding
+type TypeAnnotation struct {
+	Token   token.Token
+	Name    string
+	Element *TypeAnnotation
+}
+
+func (ta *TypeAnnotation) TokenLiteral() string { return ta.Token.Literal }
+func (ta *TypeAnnotation) String() string {
+	if ta.Element != nil {
+		return "[" + ta.Element.String() + "]"
+	}
+
+	return ta.Name
+}
+
+// Parameter is `name`, `name = default` or `...name`, a rest parameter collects the remaining arguments into an array
+type Parameter struct {
+	Name *Identifier
+
+	// Pattern replaces Name when the argument is destructured
+	Pattern *ArrayPattern
+	Type    *TypeAnnotation
+	Default Expression
+	IsRest  bool
+}
+
+// Target returns what the argument is bound to
+func (p *Parameter) Target() Pattern {
+	if p.Pattern != nil {
+		return p.Pattern
+	}
+
+	return p.Name
+}
+
+func (p *Parameter) TokenLiteral() string { return p.Target().TokenLiteral() }
+func (p *Parameter) String() string {
+	out := p.Target().String()
+
+	if p.IsRest {
+		out = "..." + out
+	}
+
+	if p.Type != nil {
+		out += ": " + p.Type.String()
+	}
+
+	if p.Default != nil {
+		out += " = " + p.Default.String()
+	}
+
+	return out
+}
@@ -0,0 +1,129 @@
+package ast
+
+// Inspect calls fn for node and then for every node below it in source order,
+// the children of a node are skipped when fn returns false
+func Inspect(node Node, fn func(Node) bool) {
+	if node == nil || !fn(node) {
+		return
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		for _, statement := range node.Statements {
+			Inspect(statement, fn)
+		}
+	case *LetStatement:
+		if node == nil {
+			return
+		}
+
+		Inspect(node.Target(), fn)
+
+		if node.Value != nil {
+			Inspect(node.Value, fn)
+		}
+	case *FunctionStatement:
+		if node.Function != nil {
+			Inspect(node.Function, fn)
+		}
+	case *ReturnStatement:
+		if node.ReturnValue != nil {
+			Inspect(node.ReturnValue, fn)
+		}
+	case *ExpressionStatement:
+		if node.Expression != nil {
+			Inspect(node.Expression, fn)
+		}
+	case *BlockStatement:
+		for _, statement := range node.Statements {
+			Inspect(statement, fn)
+		}
+	case *InterpolatedString:
+		for i, expression := range node.Expressions {
+			Inspect(node.Strings[i], fn)
+			inspectExpression(expression, fn)
+		}
+
+		Inspect(node.Strings[len(node.Strings)-1], fn)
+	case *PrefixExpression:
+		inspectExpression(node.Right, fn)
+	case *InfixExpression:
+		inspectExpression(node.Left, fn)
+		inspectExpression(node.Right, fn)
+	case *IfExpression:
+		inspectExpression(node.Condition, fn)
+
+		if node.Consequence != nil {
+			Inspect(node.Consequence, fn)
+		}
+
+		if node.Alternative != nil {
+			Inspect(node.Alternative, fn)
+		}
+	case *CallExpression:
+		inspectExpression(node.Function, fn)
+
+		for _, argument := range node.Arguments {
+			inspectExpression(argument, fn)
+		}
+	case *SpreadExpression:
+		inspectExpression(node.Value, fn)
+	case *NamedArgument:
+		inspectExpression(node.Value, fn)
+	case *AssignExpression:
+		inspectExpression(node.Assignee, fn)
+		inspectExpression(node.Value, fn)
+	case *MemberAccessExpression:
+		inspectExpression(node.Expression, fn)
+	case *ArrayAccessExpression:
+		inspectExpression(node.Expression, fn)
+		inspectExpression(node.Index, fn)
+	case *ArrayLiteral:
+		for _, value := range node.Values {
+			inspectExpression(value, fn)
+		}
+	case *FunctionLiteral:
+		if node.Name != nil {
+			Inspect(node.Name, fn)
+		}
+
+		for _, parameter := range node.Parameters {
+			Inspect(parameter.Target(), fn)
+			inspectExpression(parameter.Default, fn)
+		}
+
+		if node.Body != nil {
+			Inspect(node.Body, fn)
+		}
+	case *ForLoopLiteral:
+		inspectExpression(node.Condition, fn)
+
+		if node.Body != nil {
+			Inspect(node.Body, fn)
+		}
+	case *ForInLoopLiteral:
+		if node.Pattern != nil {
+			Inspect(node.Pattern, fn)
+		}
+
+		inspectExpression(node.Iterable, fn)
+
+		if node.Body != nil {
+			Inspect(node.Body, fn)
+		}
+	case *ArrayPattern:
+		for _, element := range node.Elements {
+			Inspect(element, fn)
+		}
+
+		if node.Rest != nil {
+			Inspect(node.Rest, fn)
+		}
+	}
+}
+
+func inspectExpression(expression Expression, fn func(Node) bool) {
+	if expression != nil {
+		Inspect(expression, fn)
+	}
+}
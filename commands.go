@@ -0,0 +1,377 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go++/analyzer"
+	"go++/ast"
+	"go++/coverage"
+	"go++/dap"
+	"go++/debugger"
+	"go++/evaluator"
+	"go++/format"
+	"go++/lexer"
+	"go++/lsp"
+	"go++/object"
+	"go++/profiler"
+	"go++/repl"
+	"go++/tester"
+	"go++/token"
+	"os"
+	"regexp"
+)
+
+// runCommand implements `gopp run [-assert-types] [-profile] [-pprof file] [-e expr | file] [args...]`,
+// the arguments after the file are given to the program as `args`
+func runCommand(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	expression := flags.String("e", "", "run the expression instead of a file")
+	flags.BoolVar(&evaluator.TypeAssertions, "assert-types", false, "check type annotations when functions are applied")
+	profile := flags.Bool("profile", false, "print where the program spent its time to stderr")
+	pprofFile := flags.String("pprof", "", "write a profile of the program for go tool pprof to the file")
+
+	if err := flags.Parse(args); err != nil {
+		return usageError{err.Error()}
+	}
+
+	src, scriptArgs, err := readSource(*expression, flags.Args())
+
+	if err != nil {
+		return err
+	}
+
+	if *profile || *pprofFile != "" {
+		return profileSource(src, scriptArgs, *profile, *pprofFile)
+	}
+
+	_, err = runSource(src, scriptArgs)
+
+	return err
+}
+
+// profileSource runs a program under the profiler, the report is printed even when the program fails
+func profileSource(src source, scriptArgs []string, report bool, pprofFile string) error {
+	program, env, err := prepareSource(src, scriptArgs)
+
+	if err != nil {
+		return err
+	}
+
+	prof := profiler.New(src.name, src.code)
+	result := prof.Run(program, env)
+
+	if report {
+		prof.Report(os.Stderr)
+	}
+
+	if pprofFile != "" {
+		file, err := os.Create(pprofFile)
+
+		if err != nil {
+			return err
+		}
+
+		if err := prof.WritePprof(file); err != nil {
+			_ = file.Close()
+			return err
+		}
+
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	if errorObj, ok := result.(*object.Error); ok {
+		return errors.New(errorObj.Message)
+	}
+
+	return nil
+}
+
+func replCommand(args []string) error {
+	if len(args) > 0 {
+		return usageError{"repl takes no arguments"}
+	}
+
+	repl.Start(os.Stdin, os.Stdout)
+
+	return nil
+}
+
+// checkCommand implements `gopp check [-e expr | files...]`, warnings are printed but only errors fail
+func checkCommand(args []string) error {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	expression := flags.String("e", "", "check the expression instead of files")
+
+	if err := flags.Parse(args); err != nil {
+		return usageError{err.Error()}
+	}
+
+	sources := []source{}
+
+	if *expression != "" {
+		sources = append(sources, source{name: "-e", code: *expression})
+	} else if flags.NArg() == 0 {
+		return usageError{"no files to check given"}
+	}
+
+	for _, file := range flags.Args() {
+		src, _, err := readSource("", []string{file})
+
+		if err != nil {
+			return err
+		}
+
+		sources = append(sources, src)
+	}
+
+	failed := false
+
+	for _, src := range sources {
+		program, err := parseSource(src)
+
+		if err != nil {
+			_, _ = fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+
+		_, staticAnalyzer := newGlobals(nil)
+		diagnostics := staticAnalyzer.Analyze(program)
+
+		for _, diagnostic := range diagnostics {
+			_, _ = fmt.Fprintln(os.Stderr, src.name+":"+diagnostic.String())
+		}
+
+		failed = failed || analyzer.HasErrors(diagnostics)
+	}
+
+	if failed {
+		return errors.New("check failed")
+	}
+
+	return nil
+}
+
+// tokensCommand prints one token per line with its position
+func tokensCommand(args []string) error {
+	flags := flag.NewFlagSet("tokens", flag.ContinueOnError)
+	expression := flags.String("e", "", "lex the expression instead of a file")
+
+	if err := flags.Parse(args); err != nil {
+		return usageError{err.Error()}
+	}
+
+	src, _, err := readSource(*expression, flags.Args())
+
+	if err != nil {
+		return err
+	}
+
+	lex := lexer.New(src.code)
+
+	for tok := lex.NextToken(); ; tok = lex.NextToken() {
+		fmt.Printf("%d:%d\t%s\t%q\n", tok.Line, tok.Column, tok.Type, tok.Literal)
+
+		if tok.Type == token.EOF {
+			return nil
+		}
+	}
+}
+
+func astCommand(args []string) error {
+	flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+	expression := flags.String("e", "", "parse the expression instead of a file")
+
+	if err := flags.Parse(args); err != nil {
+		return usageError{err.Error()}
+	}
+
+	src, _, err := readSource(*expression, flags.Args())
+
+	if err != nil {
+		return err
+	}
+
+	program, err := parseSource(src)
+
+	if err != nil {
+		return err
+	}
+
+	return ast.Dump(os.Stdout, program)
+}
+
+// formatFiles implements `gopp fmt [-w] files...`, without -w the formatted files are printed
+func formatFiles(args []string) error {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	write := flags.Bool("w", false, "write the result to the file instead of printing it")
+
+	if err := flags.Parse(args); err != nil {
+		return usageError{err.Error()}
+	}
+
+	if flags.NArg() == 0 {
+		return usageError{"no files to format given"}
+	}
+
+	failed := false
+
+	for _, file := range flags.Args() {
+		src, err := os.ReadFile(file)
+
+		if err != nil {
+			return errors.New("no file found named " + file)
+		}
+
+		formatted, err := format.Source(src)
+
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "%s: %s\n", file, err)
+			failed = true
+			continue
+		}
+
+		if !*write {
+			_, _ = os.Stdout.Write(formatted)
+			continue
+		}
+
+		if bytes.Equal(src, formatted) {
+			continue
+		}
+
+		if err := os.WriteFile(file, formatted, 0644); err != nil {
+			return err
+		}
+	}
+
+	if failed {
+		return errors.New("some files could not be formatted")
+	}
+
+	return nil
+}
+
+// lspCommand implements `gopp lsp`, a language server talking to the editor over stdin and stdout
+func lspCommand(args []string) error {
+	if len(args) > 0 {
+		return usageError{"lsp takes no arguments"}
+	}
+
+	return lsp.NewServer(os.Stdin, os.Stdout).Serve()
+}
+
+// debugCommand implements `gopp debug file [args...]`, it reads debugger commands from stdin
+func debugCommand(args []string) error {
+	src, scriptArgs, err := readSource("", args)
+
+	if err != nil {
+		return err
+	}
+
+	program, env, err := prepareSource(src, scriptArgs)
+
+	if err != nil {
+		return err
+	}
+
+	debugger.NewConsole(src.name, src.code, os.Stdin, os.Stdout).Run(program, env)
+
+	return nil
+}
+
+// dapCommand implements `gopp dap`, a debug adapter talking to the editor over stdin and stdout
+func dapCommand(args []string) error {
+	if len(args) > 0 {
+		return usageError{"dap takes no arguments"}
+	}
+
+	return dap.NewServer(os.Stdin, os.Stdout, loadProgram).Serve()
+}
+
+// loadProgram reads, parses and analyzes a program for the debug adapter
+func loadProgram(path string, scriptArgs []string) (*ast.Program, *object.Environment, error) {
+	src, _, err := readSource("", []string{path})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return prepareSource(src, scriptArgs)
+}
+
+// testCommand implements `gopp test [-run regexp] [-v] [-cover] [-coverhtml file] [paths...]`, it runs
+// the test functions of every *_test.gopp file in the paths, the current directory by default
+func testCommand(args []string) error {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	run := flags.String("run", "", "only run the tests whose name matches the regular expression")
+	verbose := flags.Bool("v", false, "report the tests that pass too")
+	cover := flags.Bool("cover", false, "print how much of the test files ran")
+	coverHTML := flags.String("coverhtml", "", "write a page highlighting the lines of the test files that ran to the file")
+
+	if err := flags.Parse(args); err != nil {
+		return usageError{err.Error()}
+	}
+
+	runner := &tester.Runner{
+		Out:     os.Stdout,
+		Verbose: *verbose,
+		Globals: func() (*object.Environment, *analyzer.Analyzer) { return newGlobals(nil) },
+	}
+
+	if *run != "" {
+		filter, err := regexp.Compile(*run)
+
+		if err != nil {
+			return usageError{"invalid -run expression: " + err.Error()}
+		}
+
+		runner.Filter = filter
+	}
+
+	if *cover || *coverHTML != "" {
+		runner.Coverage = coverage.New()
+	}
+
+	files, err := tester.Discover(flags.Args())
+
+	if err != nil {
+		return err
+	}
+
+	passed := runner.Run(files)
+
+	if *cover {
+		runner.Coverage.Summary(os.Stdout)
+	}
+
+	if *coverHTML != "" {
+		if err := writeCoverageHTML(runner.Coverage, *coverHTML); err != nil {
+			return err
+		}
+	}
+
+	if !passed {
+		return errors.New("tests failed")
+	}
+
+	return nil
+}
+
+func writeCoverageHTML(profile *coverage.Profile, path string) error {
+	file, err := os.Create(path)
+
+	if err != nil {
+		return err
+	}
+
+	if err := profile.WriteHTML(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
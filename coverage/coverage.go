@@ -0,0 +1,159 @@
+// Package coverage records which statements and branches of gopp programs ran
+package coverage
+
+import (
+	"fmt"
+	"go++/ast"
+	"io"
+)
+
+// Profile counts how often the statements and branches of its files ran, it is hooked into the
+// evaluator as evaluator.Coverage
+type Profile struct {
+	files      []*File
+	statements map[ast.Statement]int
+
+	// branches holds how often the consequence and the alternative of every if were taken
+	branches map[*ast.IfExpression]*[2]int
+}
+
+// File is a program whose coverage is measured
+type File struct {
+	Name   string
+	Source string
+
+	statements []ast.Statement
+	ifs        []*ast.IfExpression
+}
+
+// Counts is how many of the statements and branches of a file ran
+type Counts struct {
+	Statements        int
+	CoveredStatements int
+	Branches          int
+	CoveredBranches   int
+}
+
+func New() *Profile {
+	return &Profile{statements: make(map[ast.Statement]int), branches: make(map[*ast.IfExpression]*[2]int)}
+}
+
+// Add starts measuring the coverage of a program, only the statements of added programs are counted
+func (p *Profile) Add(name string, source string, program *ast.Program) *File {
+	file := &File{Name: name, Source: source}
+
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.Program:
+			file.statements = append(file.statements, node.Statements...)
+		case *ast.BlockStatement:
+			file.statements = append(file.statements, node.Statements...)
+		case *ast.IfExpression:
+			file.ifs = append(file.ifs, node)
+		}
+
+		return true
+	})
+
+	for _, statement := range file.statements {
+		p.statements[statement] = 0
+	}
+
+	for _, expression := range file.ifs {
+		p.branches[expression] = &[2]int{}
+	}
+
+	p.files = append(p.files, file)
+
+	return file
+}
+
+// Files returns the files in the order they were added
+func (p *Profile) Files() []*File {
+	return p.files
+}
+
+func (p *Profile) Statement(statement ast.Statement) {
+	if _, ok := p.statements[statement]; ok {
+		p.statements[statement] += 1
+	}
+}
+
+func (p *Profile) Branch(expression *ast.IfExpression, consequence bool) {
+	branches, ok := p.branches[expression]
+
+	if !ok {
+		return
+	}
+
+	if consequence {
+		branches[0] += 1
+	} else {
+		branches[1] += 1
+	}
+}
+
+// Count returns how often a statement ran
+func (p *Profile) Count(statement ast.Statement) int {
+	return p.statements[statement]
+}
+
+// Counts sums up the coverage of a file
+func (p *Profile) Counts(file *File) Counts {
+	counts := Counts{Statements: len(file.statements), Branches: 2 * len(file.ifs)}
+
+	for _, statement := range file.statements {
+		if p.statements[statement] > 0 {
+			counts.CoveredStatements += 1
+		}
+	}
+
+	for _, expression := range file.ifs {
+		for _, taken := range p.branches[expression] {
+			if taken > 0 {
+				counts.CoveredBranches += 1
+			}
+		}
+	}
+
+	return counts
+}
+
+func (c Counts) add(other Counts) Counts {
+	return Counts{
+		Statements:        c.Statements + other.Statements,
+		CoveredStatements: c.CoveredStatements + other.CoveredStatements,
+		Branches:          c.Branches + other.Branches,
+		CoveredBranches:   c.CoveredBranches + other.CoveredBranches,
+	}
+}
+
+func (c Counts) String() string {
+	return fmt.Sprintf("%s of statements (%d/%d), %s of branches (%d/%d)",
+		percent(c.CoveredStatements, c.Statements), c.CoveredStatements, c.Statements,
+		percent(c.CoveredBranches, c.Branches), c.CoveredBranches, c.Branches)
+}
+
+func percent(covered, total int) string {
+	if total == 0 {
+		return "100.0%"
+	}
+
+	return fmt.Sprintf("%.1f%%", 100*float64(covered)/float64(total))
+}
+
+// Summary prints the coverage of every file and the total of all of them
+func (p *Profile) Summary(w io.Writer) {
+	total := Counts{}
+
+	for _, file := range p.files {
+		counts := p.Counts(file)
+		total = total.add(counts)
+
+		fmt.Fprintf(w, "coverage: %s in %s\n", counts, file.Name)
+	}
+
+	if len(p.files) > 1 {
+		fmt.Fprintf(w, "coverage: %s in total\n", total)
+	}
+}
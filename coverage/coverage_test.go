@@ -0,0 +1,100 @@
+package coverage
+
+import (
+	"bytes"
+	"go++/evaluator"
+	"go++/lexer"
+	"go++/object"
+	"go++/parser"
+	"strings"
+	"testing"
+)
+
+const program = `let clamp = fn (x) {
+	if x < 0 {
+		return 0
+	}
+
+	if x > 10 { 10 } else { x }
+}
+
+let unused = fn () {
+	println("never")
+}
+
+clamp(-1)
+clamp(5)
+`
+
+func measure(t *testing.T) *Profile {
+	pars := parser.New(lexer.New(program))
+	parsed := pars.ParseProgram()
+
+	if len(pars.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", pars.Errors())
+	}
+
+	profile := New()
+	profile.Add("clamp.gopp", program, parsed)
+
+	evaluator.Coverage = profile
+	defer func() { evaluator.Coverage = nil }()
+
+	evaluator.Evaluate(parsed, object.NewEnvironment())
+
+	return profile
+}
+
+func TestCounts(t *testing.T) {
+	profile := measure(t)
+	counts := profile.Counts(profile.Files()[0])
+
+	expected := Counts{Statements: 10, CoveredStatements: 8, Branches: 4, CoveredBranches: 3}
+
+	if counts != expected {
+		t.Errorf("wrong counts. want=%+v, got=%+v", expected, counts)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	var out bytes.Buffer
+	measure(t).Summary(&out)
+
+	expected := "coverage: 80.0% of statements (8/10), 75.0% of branches (3/4) in clamp.gopp\n"
+
+	if out.String() != expected {
+		t.Errorf("wrong summary. want=%q, got=%q", expected, out.String())
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var out bytes.Buffer
+
+	if err := measure(t).WriteHTML(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		line  string
+		class string
+	}{
+		{"1", "covered"},
+		{"3", "covered"},
+		{"4", "neutral"},
+		{"6", "partial"},
+		{"10", "uncovered"},
+		{"13", "covered"},
+	}
+
+	for _, tt := range tests {
+		row := `<tr class="` + tt.class + `"><td class="number">` + tt.line + `</td>`
+
+		if !strings.Contains(out.String(), row) {
+			t.Errorf("line %s is not %s. got=\n%s", tt.line, tt.class, out.String())
+		}
+	}
+
+	if !strings.Contains(out.String(), "println(&#34;never&#34;)") {
+		t.Errorf("the source isn't escaped")
+	}
+}
@@ -0,0 +1,131 @@
+package coverage
+
+import (
+	"go++/ast"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// The state of a line in the HTML report
+const (
+	lineNeutral   = "neutral"
+	lineCovered   = "covered"
+	lineUncovered = "uncovered"
+
+	// linePartial has statements that ran and ones that didn't, or an if that only ever took one of its branches
+	linePartial = "partial"
+)
+
+type htmlLine struct {
+	Number int
+	Class  string
+	Count  int
+	Text   string
+}
+
+type htmlFile struct {
+	Name   string
+	Counts Counts
+	Lines  []htmlLine
+}
+
+var page = template.Must(template.New("coverage").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gopp coverage</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+pre { font-family: monospace; }
+table { border-collapse: collapse; }
+td { padding: 0 0.5em; font-family: monospace; white-space: pre; }
+td.number, td.count { color: #888; text-align: right; }
+tr.covered td.text { background: #c8f0c8; }
+tr.uncovered td.text { background: #f4c4c4; }
+tr.partial td.text { background: #f4e8b0; }
+</style>
+</head>
+<body>
+{{range .}}<h2>{{.Name}}</h2>
+<p>{{.Counts}}</p>
+<table>
+{{range .Lines}}<tr class="{{.Class}}"><td class="number">{{.Number}}</td><td class="count">{{if .Count}}{{.Count}}{{end}}</td><td class="text">{{.Text}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes a page showing the source of every file with the lines that ran and the ones that
+// didn't highlighted
+func (p *Profile) WriteHTML(w io.Writer) error {
+	files := make([]htmlFile, len(p.files))
+
+	for i, file := range p.files {
+		files[i] = htmlFile{Name: file.Name, Counts: p.Counts(file), Lines: p.lines(file)}
+	}
+
+	return page.Execute(w, files)
+}
+
+func (p *Profile) lines(file *File) []htmlLine {
+	source := strings.Split(strings.TrimSuffix(file.Source, "\n"), "\n")
+	lines := make([]htmlLine, len(source))
+
+	for i, text := range source {
+		lines[i] = htmlLine{Number: i + 1, Class: lineNeutral, Text: text}
+	}
+
+	at := func(line int) *htmlLine {
+		if line < 1 || line > len(lines) {
+			return nil
+		}
+
+		return &lines[line-1]
+	}
+
+	covered := make(map[int]bool)
+	uncovered := make(map[int]bool)
+
+	for _, statement := range file.statements {
+		number := ast.StartToken(statement).Line
+		line := at(number)
+
+		if line == nil {
+			continue
+		}
+
+		count := p.statements[statement]
+
+		if count == 0 {
+			uncovered[number] = true
+		} else {
+			covered[number] = true
+		}
+
+		if count > line.Count {
+			line.Count = count
+		}
+	}
+
+	for _, expression := range file.ifs {
+		if branches := p.branches[expression]; branches[0] == 0 || branches[1] == 0 {
+			uncovered[expression.Token.Line] = true
+		}
+	}
+
+	for i := range lines {
+		number := lines[i].Number
+
+		switch {
+		case covered[number] && uncovered[number]:
+			lines[i].Class = linePartial
+		case covered[number]:
+			lines[i].Class = lineCovered
+		case uncovered[number]:
+			lines[i].Class = lineUncovered
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,473 @@
+// Package dap implements a debug adapter for gopp speaking the Debug Adapter Protocol
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go++/ast"
+	"go++/debugger"
+	"go++/evaluator"
+	"go++/framing"
+	"go++/object"
+	"io"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// threadID is the id of the only thread a program runs on
+const threadID = 1
+
+// Loader parses and analyzes the program at path and creates the environment it runs in
+type Loader func(path string, args []string) (*ast.Program, *object.Environment, error)
+
+type handler func(s *Server, arguments json.RawMessage) (interface{}, error)
+
+var handlers = map[string]handler{
+	"initialize":        (*Server).initialize,
+	"launch":            (*Server).launch,
+	"setBreakpoints":    (*Server).setBreakpoints,
+	"configurationDone": (*Server).configurationDone,
+	"threads":           (*Server).threads,
+	"stackTrace":        (*Server).stackTrace,
+	"scopes":            (*Server).scopes,
+	"variables":         (*Server).variables,
+	"evaluate":          (*Server).evaluate,
+	"continue":          resumeWith(debugger.Continue),
+	"next":              resumeWith(debugger.StepOver),
+	"stepIn":            resumeWith(debugger.StepIn),
+	"stepOut":           resumeWith(debugger.StepOut),
+	"disconnect":        (*Server).disconnect,
+	"terminate":         (*Server).disconnect,
+}
+
+// Server debugs a single program, requests are read on the goroutine calling Serve while the
+// program runs on its own and blocks in the debugger when it pauses
+type Server struct {
+	in   *bufio.Reader
+	out  io.Writer
+	load Loader
+
+	mu  sync.Mutex
+	seq int
+
+	debugger    *debugger.Debugger
+	program     *ast.Program
+	env         *object.Environment
+	source      Source
+	stopOnEntry bool
+	isPaused    bool
+	resume      chan debugger.Resume
+	done        chan struct{}
+
+	// handles are the environments and values variables can be expanded into, their reference is
+	// their index plus one and they're only valid while the program stays paused
+	handles []interface{}
+}
+
+func NewServer(in io.Reader, out io.Writer, load Loader) *Server {
+	s := &Server{in: bufio.NewReader(in), out: out, load: load, resume: make(chan debugger.Resume, 1)}
+	s.debugger = debugger.New(s.paused)
+
+	return s
+}
+
+// Serve handles requests until the client disconnects or closes the input
+func (s *Server) Serve() error {
+	for {
+		body, err := framing.Read(s.in)
+
+		if errors.Is(err, io.EOF) {
+			s.stop()
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var request message
+
+		if err := json.Unmarshal(body, &request); err != nil {
+			return err
+		}
+
+		result, err := s.handle(request)
+
+		reply := response{Type: "response", RequestSeq: request.Seq, Success: err == nil, Command: request.Command, Body: result}
+
+		if err != nil {
+			reply.Message = err.Error()
+		}
+
+		if err := s.write(&reply, &reply.Seq); err != nil {
+			return err
+		}
+
+		switch request.Command {
+		case "initialize":
+			if err := s.event("initialized", nil); err != nil {
+				return err
+			}
+		case "disconnect", "terminate":
+			return nil
+		}
+	}
+}
+
+func (s *Server) handle(request message) (interface{}, error) {
+	handle, ok := handlers[request.Command]
+
+	if !ok {
+		return nil, fmt.Errorf("request not supported: %s", request.Command)
+	}
+
+	return handle(s, request.Arguments)
+}
+
+// write numbers the message with the next sequence number and sends it, responses and
+// events are written from both goroutines
+func (s *Server) write(v interface{}, seq *int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq += 1
+	*seq = s.seq
+
+	body, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	return framing.Write(s.out, body)
+}
+
+func (s *Server) event(name string, body interface{}) error {
+	e := event{Type: "event", Event: name, Body: body}
+
+	return s.write(&e, &e.Seq)
+}
+
+func decode(arguments json.RawMessage, v interface{}) error {
+	if len(arguments) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(arguments, v)
+}
+
+func (s *Server) initialize(arguments json.RawMessage) (interface{}, error) {
+	return Capabilities{SupportsConfigurationDoneRequest: true, SupportsEvaluateForHovers: true, SupportsTerminateRequest: true}, nil
+}
+
+func (s *Server) launch(arguments json.RawMessage) (interface{}, error) {
+	var args LaunchArguments
+
+	if err := decode(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	program, env, err := s.load(args.Program, args.Args)
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.program = program
+	s.env = env
+	s.stopOnEntry = args.StopOnEntry
+	s.source = Source{Name: filepath.Base(args.Program), Path: args.Program}
+
+	return nil, nil
+}
+
+func (s *Server) setBreakpoints(arguments json.RawMessage) (interface{}, error) {
+	var args SetBreakpointsArguments
+
+	if err := decode(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	s.debugger.ClearBreakpoints()
+
+	breakpoints := []Breakpoint{}
+
+	for _, breakpoint := range args.Breakpoints {
+		s.debugger.SetBreakpoint(breakpoint.Line)
+		breakpoints = append(breakpoints, Breakpoint{Verified: true, Line: breakpoint.Line})
+	}
+
+	return SetBreakpointsResponseBody{Breakpoints: breakpoints}, nil
+}
+
+// configurationDone starts the program, every breakpoint has been set by now
+func (s *Server) configurationDone(arguments json.RawMessage) (interface{}, error) {
+	if s.program == nil {
+		return nil, errors.New("no program was launched")
+	}
+
+	if s.done != nil {
+		return nil, nil
+	}
+
+	s.done = make(chan struct{})
+
+	go s.run()
+
+	return nil, nil
+}
+
+func (s *Server) run() {
+	defer close(s.done)
+
+	output := evaluator.Output
+	evaluator.Output = &outputWriter{server: s, category: "stdout"}
+	defer func() { evaluator.Output = output }()
+
+	result := s.debugger.Run(s.program, s.env, s.stopOnEntry)
+	exitCode := 0
+
+	if err, ok := result.(*object.Error); ok && err != debugger.ErrQuit {
+		_ = s.event("output", OutputEventBody{Category: "stderr", Output: err.Message + "\n"})
+		exitCode = 1
+	}
+
+	_ = s.event("exited", ExitedEventBody{ExitCode: exitCode})
+	_ = s.event("terminated", nil)
+}
+
+// paused runs on the program's goroutine, it waits for a request resuming the program
+func (s *Server) paused(reason string) debugger.Resume {
+	if s.stopOnEntry {
+		reason = "entry"
+		s.stopOnEntry = false
+	} else if reason == "return" {
+		reason = "step"
+	}
+
+	s.mu.Lock()
+	s.isPaused = true
+	s.mu.Unlock()
+
+	_ = s.event("stopped", StoppedEventBody{Reason: reason, ThreadID: threadID, AllThreadsStopped: true})
+
+	return <-s.resume
+}
+
+// pausedFrames returns the call stack, which can only be looked at while the program is paused
+func (s *Server) pausedFrames() ([]*debugger.Frame, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.isPaused {
+		return nil, errors.New("the program is not paused")
+	}
+
+	return s.debugger.Frames(), nil
+}
+
+func resumeWith(resume debugger.Resume) handler {
+	return func(s *Server, arguments json.RawMessage) (interface{}, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if !s.isPaused {
+			return nil, errors.New("the program is not paused")
+		}
+
+		s.isPaused = false
+		s.handles = nil
+		s.resume <- resume
+
+		return ContinueResponseBody{AllThreadsContinued: true}, nil
+	}
+}
+
+func (s *Server) threads(arguments json.RawMessage) (interface{}, error) {
+	return ThreadsResponseBody{Threads: []Thread{{ID: threadID, Name: "main"}}}, nil
+}
+
+func (s *Server) stackTrace(arguments json.RawMessage) (interface{}, error) {
+	frames, err := s.pausedFrames()
+
+	if err != nil {
+		return nil, err
+	}
+
+	stackFrames := []StackFrame{}
+
+	for i, frame := range frames {
+		stackFrames = append(stackFrames, StackFrame{ID: i + 1, Name: frame.Name, Source: s.source, Line: frame.Line, Column: 1})
+	}
+
+	return StackTraceResponseBody{StackFrames: stackFrames, TotalFrames: len(stackFrames)}, nil
+}
+
+// frame looks up a frame by the id stackTrace gave it
+func (s *Server) frame(id int) (*debugger.Frame, error) {
+	frames, err := s.pausedFrames()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if id < 1 || id > len(frames) {
+		return nil, fmt.Errorf("no frame with id %d", id)
+	}
+
+	return frames[id-1], nil
+}
+
+func (s *Server) newHandle(value interface{}) int {
+	s.handles = append(s.handles, value)
+
+	return len(s.handles)
+}
+
+// scopes gives a scope to the frame's environment and every environment enclosing it
+func (s *Server) scopes(arguments json.RawMessage) (interface{}, error) {
+	var args FrameArguments
+
+	if err := decode(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	frame, err := s.frame(args.FrameID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	environments := debugger.Environments(frame)
+	scopes := []Scope{}
+
+	for i, env := range environments {
+		name := "Locals"
+
+		if i == len(environments)-1 {
+			name = "Globals"
+		} else if i > 0 {
+			name = fmt.Sprintf("Enclosing %d", i)
+		}
+
+		scopes = append(scopes, Scope{Name: name, VariablesReference: s.newHandle(env), Expensive: i == len(environments)-1})
+	}
+
+	return ScopesResponseBody{Scopes: scopes}, nil
+}
+
+// variable describes a value, arrays and values with members can be expanded
+func (s *Server) variable(name string, value object.Object) Variable {
+	variable := Variable{Name: name, Value: value.Inspect(), Type: string(value.Type())}
+
+	if array, ok := value.(*object.Array); ok && len(array.Values) > 0 || len(value.GetMembers().Names()) > 0 {
+		variable.VariablesReference = s.newHandle(value)
+	}
+
+	return variable
+}
+
+func (s *Server) variables(arguments json.RawMessage) (interface{}, error) {
+	var args VariablesArguments
+
+	if err := decode(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.pausedFrames(); err != nil {
+		return nil, err
+	}
+
+	if args.VariablesReference < 1 || args.VariablesReference > len(s.handles) {
+		return nil, fmt.Errorf("no variables with reference %d", args.VariablesReference)
+	}
+
+	variables := []Variable{}
+
+	switch value := s.handles[args.VariablesReference-1].(type) {
+	case *object.Environment:
+		for _, binding := range value.Bindings() {
+			variables = append(variables, s.variable(binding.Name, binding.Object))
+		}
+	case *object.Array:
+		for i, element := range value.Values {
+			variables = append(variables, s.variable("["+strconv.Itoa(i)+"]", element))
+		}
+	case object.Object:
+		members := value.GetMembers()
+
+		for _, name := range members.Names() {
+			member, _ := members.Get(name)
+			variables = append(variables, s.variable(name, member))
+		}
+	}
+
+	return VariablesResponseBody{Variables: variables}, nil
+}
+
+func (s *Server) evaluate(arguments json.RawMessage) (interface{}, error) {
+	var args EvaluateArguments
+
+	if err := decode(arguments, &args); err != nil {
+		return nil, err
+	}
+
+	frame, err := s.frame(max(args.FrameID, 1))
+
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.debugger.Evaluate(args.Expression, frame)
+
+	if err != nil {
+		return nil, err
+	}
+
+	variable := s.variable("", result)
+
+	return EvaluateResponseBody{Result: variable.Value, Type: variable.Type, VariablesReference: variable.VariablesReference}, nil
+}
+
+func (s *Server) disconnect(arguments json.RawMessage) (interface{}, error) {
+	s.stop()
+
+	return nil, nil
+}
+
+// stop quits a running program and waits for it to end
+func (s *Server) stop() {
+	if s.done == nil {
+		return
+	}
+
+	s.debugger.Stop()
+
+	s.mu.Lock()
+
+	if s.isPaused {
+		s.isPaused = false
+		s.resume <- debugger.Quit
+	}
+
+	s.mu.Unlock()
+
+	<-s.done
+}
+
+// outputWriter sends what the program prints to the client as output events
+type outputWriter struct {
+	server   *Server
+	category string
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	if err := w.server.event("output", OutputEventBody{Category: w.category, Output: string(p)}); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
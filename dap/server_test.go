@@ -0,0 +1,339 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"go++/ast"
+	"go++/framing"
+	"go++/lexer"
+	"go++/object"
+	"go++/parser"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const program = `let scale = 10
+let sum = fn (values) {
+	let mut total = 0
+	let mut i = 0
+	for i < values.length() {
+		total = total + values[i] * scale
+		i = i + 1
+	}
+	total
+}
+let numbers = [1, 2]
+println(sum(numbers))
+`
+
+func loadProgram(path string, args []string) (*ast.Program, *object.Environment, error) {
+	if path != "test.gopp" {
+		return nil, nil, errors.New("no file found named " + path)
+	}
+
+	pars := parser.New(lexer.New(program))
+	parsed := pars.ParseProgram()
+
+	if len(pars.Errors()) > 0 {
+		return nil, nil, errors.New(strings.Join(pars.Errors(), "\n"))
+	}
+
+	return parsed, object.NewEnvironment(), nil
+}
+
+type received struct {
+	Seq        int             `json:"seq"`
+	Type       string          `json:"type"`
+	Event      string          `json:"event"`
+	Command    string          `json:"command"`
+	RequestSeq int             `json:"request_seq"`
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// client drives a server running in the same process through pipes
+type client struct {
+	t        *testing.T
+	out      io.Writer
+	seq      int
+	messages chan received
+	events   []received
+	done     chan error
+}
+
+func newClient(t *testing.T) *client {
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+
+	c := &client{t: t, out: clientOut, messages: make(chan received, 100), done: make(chan error, 1)}
+
+	go func() {
+		c.done <- NewServer(serverIn, serverOut, loadProgram).Serve()
+		_ = serverOut.Close()
+	}()
+
+	go func() {
+		defer close(c.messages)
+
+		in := bufio.NewReader(clientIn)
+
+		for {
+			body, err := framing.Read(in)
+
+			if err != nil {
+				return
+			}
+
+			var msg received
+
+			if err := json.Unmarshal(body, &msg); err != nil {
+				return
+			}
+
+			c.messages <- msg
+		}
+	}()
+
+	return c
+}
+
+func (c *client) next() received {
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			c.t.Fatal("the server closed the connection")
+		}
+
+		return msg
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("timed out waiting for the server")
+	}
+
+	return received{}
+}
+
+// request sends a request and decodes the body of its response, events sent in between are kept
+func (c *client) request(command string, arguments interface{}, body interface{}) received {
+	c.seq += 1
+
+	data, _ := json.Marshal(map[string]interface{}{"seq": c.seq, "type": "request", "command": command, "arguments": arguments})
+
+	if err := framing.Write(c.out, data); err != nil {
+		c.t.Fatalf("sending %s failed: %v", command, err)
+	}
+
+	for {
+		msg := c.next()
+
+		if msg.Type == "event" {
+			c.events = append(c.events, msg)
+			continue
+		}
+
+		if msg.RequestSeq != c.seq || msg.Command != command {
+			c.t.Fatalf("unexpected response to %s. got=%+v", command, msg)
+		}
+
+		if msg.Success && body != nil {
+			if err := json.Unmarshal(msg.Body, body); err != nil {
+				c.t.Fatalf("invalid body of %s %s: %v", command, msg.Body, err)
+			}
+		}
+
+		return msg
+	}
+}
+
+// waitFor returns the next event with the name, other events are kept
+func (c *client) waitFor(name string) received {
+	for i, event := range c.events {
+		if event.Event == name {
+			c.events = append(c.events[:i], c.events[i+1:]...)
+			return event
+		}
+	}
+
+	for {
+		msg := c.next()
+
+		if msg.Type == "event" && msg.Event == name {
+			return msg
+		}
+
+		c.events = append(c.events, msg)
+	}
+}
+
+func (c *client) stoppedReason() string {
+	var body StoppedEventBody
+
+	if err := json.Unmarshal(c.waitFor("stopped").Body, &body); err != nil {
+		c.t.Fatal(err)
+	}
+
+	return body.Reason
+}
+
+func (c *client) stackTrace() []StackFrame {
+	var body StackTraceResponseBody
+	c.request("stackTrace", map[string]int{"threadId": threadID}, &body)
+
+	return body.StackFrames
+}
+
+func (c *client) variables(reference int) []Variable {
+	var body VariablesResponseBody
+	c.request("variables", VariablesArguments{VariablesReference: reference}, &body)
+
+	return body.Variables
+}
+
+func (c *client) start(stopOnEntry bool, breakpoints ...int) {
+	c.request("initialize", map[string]string{"adapterID": "gopp"}, nil)
+	c.waitFor("initialized")
+
+	if response := c.request("launch", LaunchArguments{Program: "test.gopp", StopOnEntry: stopOnEntry}, nil); !response.Success {
+		c.t.Fatalf("launch failed: %s", response.Message)
+	}
+
+	lines := []SourceBreakpoint{}
+
+	for _, line := range breakpoints {
+		lines = append(lines, SourceBreakpoint{Line: line})
+	}
+
+	c.request("setBreakpoints", SetBreakpointsArguments{Source: Source{Path: "test.gopp"}, Breakpoints: lines}, nil)
+	c.request("configurationDone", nil, nil)
+}
+
+func (c *client) finish() {
+	c.request("disconnect", nil, nil)
+
+	if err := <-c.done; err != nil {
+		c.t.Errorf("server failed: %v", err)
+	}
+}
+
+func TestBreakpointsAndStepping(t *testing.T) {
+	c := newClient(t)
+	defer c.finish()
+
+	c.start(true, 6)
+
+	if reason := c.stoppedReason(); reason != "entry" {
+		t.Fatalf("expected to stop on entry. got=%s", reason)
+	}
+
+	c.request("continue", map[string]int{"threadId": threadID}, nil)
+
+	if reason := c.stoppedReason(); reason != "breakpoint" {
+		t.Fatalf("expected to stop at the breakpoint. got=%s", reason)
+	}
+
+	frames := c.stackTrace()
+
+	if len(frames) != 2 || frames[0].Name != "sum" || frames[0].Line != 6 || frames[1].Name != "main" || frames[1].Line != 12 {
+		t.Fatalf("wrong stack trace. got=%+v", frames)
+	}
+
+	c.request("next", map[string]int{"threadId": threadID}, nil)
+	c.stoppedReason()
+
+	if frames := c.stackTrace(); frames[0].Line != 7 {
+		t.Errorf("expected next to stop at line 7. got=%+v", frames[0])
+	}
+
+	// The breakpoint would be hit again by the next iteration of the loop
+	c.request("setBreakpoints", SetBreakpointsArguments{Source: Source{Path: "test.gopp"}}, nil)
+	c.request("stepOut", map[string]int{"threadId": threadID}, nil)
+	c.stoppedReason()
+
+	if frames := c.stackTrace(); len(frames) != 1 || frames[0].Line != 12 {
+		t.Errorf("expected step out to stop in main. got=%+v", frames)
+	}
+
+	c.request("continue", map[string]int{"threadId": threadID}, nil)
+
+	var output OutputEventBody
+
+	if err := json.Unmarshal(c.waitFor("output").Body, &output); err != nil || output.Output != "30\n" {
+		t.Errorf("wrong program output. got=%+v", output)
+	}
+
+	var exited ExitedEventBody
+
+	if err := json.Unmarshal(c.waitFor("exited").Body, &exited); err != nil || exited.ExitCode != 0 {
+		t.Errorf("wrong exit. got=%+v", exited)
+	}
+
+	c.waitFor("terminated")
+}
+
+func TestScopesAndVariables(t *testing.T) {
+	c := newClient(t)
+	defer c.finish()
+
+	c.start(false, 6)
+	c.stoppedReason()
+
+	var scopes ScopesResponseBody
+	c.request("scopes", FrameArguments{FrameID: 1}, &scopes)
+
+	names := []string{}
+
+	for _, scope := range scopes.Scopes {
+		names = append(names, scope.Name)
+	}
+
+	if strings.Join(names, ",") != "Locals,Enclosing 1,Globals" {
+		t.Fatalf("wrong scopes. got=%v", names)
+	}
+
+	locals := c.variables(scopes.Scopes[1].VariablesReference)
+
+	if len(locals) != 3 || locals[0].Name != "values" || locals[1].Name != "total" || locals[2].Name != "i" || locals[0].Value != "[1, 2]" {
+		t.Fatalf("wrong variables of the function. got=%+v", locals)
+	}
+
+	elements := c.variables(locals[0].VariablesReference)
+
+	if len(elements) != 2 || elements[1].Name != "[1]" || elements[1].Value != "2" || elements[1].Type != "INTEGER" {
+		t.Errorf("wrong elements of values. got=%+v", elements)
+	}
+
+	members := c.variables(elements[1].VariablesReference)
+
+	if len(members) != 1 || members[0].Name != "add" {
+		t.Errorf("wrong members of an integer. got=%+v", members)
+	}
+
+	var evaluated EvaluateResponseBody
+	c.request("evaluate", EvaluateArguments{Expression: "values[1] * scale", FrameID: 1}, &evaluated)
+
+	if evaluated.Result != "20" {
+		t.Errorf("wrong evaluation in the paused frame. got=%+v", evaluated)
+	}
+
+	if response := c.request("evaluate", EvaluateArguments{Expression: "nope", FrameID: 1}, nil); response.Success {
+		t.Errorf("expected evaluating an undefined name to fail")
+	}
+}
+
+func TestLaunchErrors(t *testing.T) {
+	c := newClient(t)
+	defer c.finish()
+
+	c.request("initialize", nil, nil)
+
+	if response := c.request("launch", LaunchArguments{Program: "missing.gopp"}, nil); response.Success || response.Message != "no file found named missing.gopp" {
+		t.Errorf("expected launching a missing file to fail. got=%+v", response)
+	}
+
+	if response := c.request("stackTrace", map[string]int{"threadId": threadID}, nil); response.Success {
+		t.Errorf("expected a stack trace to need a paused program. got=%+v", response)
+	}
+}
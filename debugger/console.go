@@ -0,0 +1,196 @@
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"go++/ast"
+	"go++/object"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const PROMPT = "(gopp) "
+
+const help = `break line     set a breakpoint, b for short
+clear line     remove a breakpoint
+breakpoints    list the breakpoints
+continue       run until the next breakpoint, c for short
+step           step into the next statement, s for short
+next           step over function calls, n for short
+out            run until the function returns, o for short
+locals         print the bindings of the frame and the environments enclosing it, l for short
+print expr     evaluate an expression in the frame, p for short
+backtrace      print the call stack, bt for short
+frame n        select a frame of the backtrace, f for short
+quit           stop the program, q for short`
+
+// Console is the command line front end of gopp debug
+type Console struct {
+	name     string
+	lines    []string
+	in       *bufio.Scanner
+	out      io.Writer
+	debugger *Debugger
+	frame    int
+}
+
+func NewConsole(name string, source string, in io.Reader, out io.Writer) *Console {
+	console := &Console{name: name, lines: strings.Split(source, "\n"), in: bufio.NewScanner(in), out: out}
+	console.debugger = New(console.paused)
+
+	return console
+}
+
+// Run debugs the program, it pauses before the first statement so breakpoints can be set
+func (c *Console) Run(program *ast.Program, env *object.Environment) object.Object {
+	result := c.debugger.Run(program, env, true)
+
+	if err, ok := result.(*object.Error); ok {
+		fmt.Fprintf(c.out, "program stopped: %s\n", err.Message)
+	} else {
+		fmt.Fprintln(c.out, "program finished")
+	}
+
+	return result
+}
+
+func (c *Console) paused(reason string) Resume {
+	c.frame = 0
+	c.printLocation(reason)
+
+	for {
+		fmt.Fprint(c.out, PROMPT)
+
+		if !c.in.Scan() {
+			fmt.Fprintln(c.out)
+			return Quit
+		}
+
+		command, argument, _ := strings.Cut(strings.TrimSpace(c.in.Text()), " ")
+		argument = strings.TrimSpace(argument)
+
+		switch command {
+		case "":
+		case "c", "continue":
+			return Continue
+		case "s", "step":
+			return StepIn
+		case "n", "next":
+			return StepOver
+		case "o", "out":
+			return StepOut
+		case "q", "quit":
+			return Quit
+		case "b", "break":
+			if line, ok := c.line(argument); ok {
+				c.debugger.SetBreakpoint(line)
+				fmt.Fprintf(c.out, "breakpoint at %s:%d\n", c.name, line)
+			}
+		case "clear":
+			if line, ok := c.line(argument); ok {
+				c.debugger.ClearBreakpoint(line)
+			}
+		case "breakpoints":
+			c.printBreakpoints()
+		case "l", "locals":
+			c.printLocals()
+		case "p", "print":
+			c.print(argument)
+		case "bt", "backtrace":
+			c.printBacktrace()
+		case "f", "frame":
+			if n, err := strconv.Atoi(argument); err == nil && n >= 0 && n < len(c.debugger.Frames()) {
+				c.frame = n
+				c.printBacktrace()
+			} else {
+				fmt.Fprintf(c.out, "no frame %q\n", argument)
+			}
+		case "h", "help":
+			fmt.Fprintln(c.out, help)
+		default:
+			fmt.Fprintf(c.out, "unknown command %s, help lists the commands\n", command)
+		}
+	}
+}
+
+func (c *Console) line(argument string) (int, bool) {
+	line, err := strconv.Atoi(argument)
+
+	if err != nil || line < 1 || line > len(c.lines) {
+		fmt.Fprintf(c.out, "invalid line %q\n", argument)
+		return 0, false
+	}
+
+	return line, true
+}
+
+func (c *Console) source(line int) string {
+	if line < 1 || line > len(c.lines) {
+		return ""
+	}
+
+	return strings.TrimSpace(c.lines[line-1])
+}
+
+func (c *Console) printLocation(reason string) {
+	frame := c.debugger.Frames()[0]
+
+	fmt.Fprintf(c.out, "%s at %s:%d in %s\n", reason, c.name, frame.Line, frame.Name)
+	fmt.Fprintf(c.out, "%5d\t%s\n", frame.Line, c.source(frame.Line))
+}
+
+func (c *Console) printBreakpoints() {
+	for _, line := range c.debugger.Breakpoints() {
+		fmt.Fprintf(c.out, "%s:%d\t%s\n", c.name, line, c.source(line))
+	}
+}
+
+// printLocals prints the bindings of the selected frame, then those of every enclosing environment
+func (c *Console) printLocals() {
+	environments := Environments(c.debugger.Frames()[c.frame])
+
+	for i, env := range environments {
+		switch {
+		case i == len(environments)-1:
+			fmt.Fprintln(c.out, "globals:")
+		case i == 0:
+			fmt.Fprintln(c.out, "locals:")
+		default:
+			fmt.Fprintf(c.out, "enclosing %d:\n", i)
+		}
+
+		for _, binding := range env.Bindings() {
+			keyword := "let"
+
+			if binding.IsMutable {
+				keyword = "let mut"
+			}
+
+			fmt.Fprintf(c.out, "\t%s %s = %s\n", keyword, binding.Name, binding.Object.Inspect())
+		}
+	}
+}
+
+func (c *Console) print(input string) {
+	result, err := c.debugger.Evaluate(input, c.debugger.Frames()[c.frame])
+
+	if err != nil {
+		fmt.Fprintln(c.out, "error:", err)
+		return
+	}
+
+	fmt.Fprintln(c.out, result.Inspect())
+}
+
+func (c *Console) printBacktrace() {
+	for i, frame := range c.debugger.Frames() {
+		marker := " "
+
+		if i == c.frame {
+			marker = "*"
+		}
+
+		fmt.Fprintf(c.out, "%s %d %s at %s:%d\n", marker, i, frame.Name, c.name, frame.Line)
+	}
+}
@@ -0,0 +1,253 @@
+// Package debugger pauses gopp programs at breakpoints and steps through them statement by statement
+package debugger
+
+import (
+	"errors"
+	"go++/ast"
+	"go++/evaluator"
+	"go++/lexer"
+	"go++/object"
+	"go++/parser"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Resume tells the debugger how to go on after a pause
+type Resume int
+
+const (
+	Continue Resume = iota
+	StepIn
+	StepOver
+	StepOut
+	Quit
+)
+
+// ErrQuit is the error a program stops with when it is quit from the debugger
+var ErrQuit = &object.Error{Message: "debugging stopped"}
+
+// Frame is a function being applied, the first frame is the program itself
+type Frame struct {
+	Name     string
+	Function *object.Function
+
+	// Env is the environment of the statement about to run, it may be enclosed by the function's one
+	Env       *object.Environment
+	Statement ast.Statement
+	Line      int
+}
+
+// Debugger is hooked into the evaluator while it runs a program
+type Debugger struct {
+	// Paused is called when the program pauses with the reason it did, it doesn't return until the program should go on
+	Paused func(reason string) Resume
+
+	frames     []*Frame
+	mode       Resume
+	depth      int
+	evaluating bool
+	stopped    atomic.Bool
+
+	// paused is the statement the program last paused at until another statement runs or a loop goes around
+	// again, a breakpoint on its line doesn't pause the program at it twice
+	paused ast.Statement
+
+	// Breakpoints may be changed by a front end while the program runs
+	mu          sync.Mutex
+	breakpoints map[int]bool
+}
+
+func New(paused func(reason string) Resume) *Debugger {
+	return &Debugger{Paused: paused, breakpoints: make(map[int]bool)}
+}
+
+// Run evaluates the program in env, it pauses before the first statement when stopOnEntry is set
+func (d *Debugger) Run(program *ast.Program, env *object.Environment, stopOnEntry bool) object.Object {
+	d.frames = []*Frame{{Name: "main", Env: env}}
+	d.mode = Continue
+	d.paused = nil
+
+	if stopOnEntry {
+		d.mode = StepIn
+	}
+
+	evaluator.Debugger = d
+	defer func() { evaluator.Debugger = nil }()
+
+	return evaluator.Evaluate(program, env)
+}
+
+func (d *Debugger) SetBreakpoint(line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.breakpoints[line] = true
+}
+
+func (d *Debugger) ClearBreakpoint(line int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.breakpoints, line)
+}
+
+// ClearBreakpoints removes every breakpoint
+func (d *Debugger) ClearBreakpoints() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.breakpoints = make(map[int]bool)
+}
+
+// Breakpoints returns the sorted lines with a breakpoint
+func (d *Debugger) Breakpoints() []int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	lines := []int{}
+
+	for line := range d.breakpoints {
+		lines = append(lines, line)
+	}
+
+	sort.Ints(lines)
+
+	return lines
+}
+
+func (d *Debugger) hasBreakpoint(line int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.breakpoints[line]
+}
+
+// Stop makes the program stop at its next statement, it can be called while the program runs
+func (d *Debugger) Stop() {
+	d.stopped.Store(true)
+}
+
+// Frames returns the call stack with the innermost frame first
+func (d *Debugger) Frames() []*Frame {
+	frames := make([]*Frame, len(d.frames))
+
+	for i, frame := range d.frames {
+		frames[len(d.frames)-1-i] = frame
+	}
+
+	return frames
+}
+
+func (d *Debugger) Statement(statement ast.Statement, env *object.Environment) *object.Error {
+	if d.evaluating {
+		return nil
+	}
+
+	// A program can't be stopped from Return, it stops at the statement after
+	if d.mode == Quit || d.stopped.Load() {
+		return ErrQuit
+	}
+
+	frame := d.frames[len(d.frames)-1]
+	line := ast.StartToken(statement).Line
+
+	reason := ""
+
+	switch {
+	case d.mode == StepIn,
+		d.mode == StepOver && len(d.frames) <= d.depth,
+		d.mode == StepOut && len(d.frames) < d.depth:
+		reason = "step"
+	case statement != d.paused && d.hasBreakpoint(line):
+		reason = "breakpoint"
+	}
+
+	if statement != d.paused {
+		d.paused = nil
+	}
+
+	frame.Env = env
+	frame.Statement = statement
+	frame.Line = line
+
+	if reason == "" {
+		return nil
+	}
+
+	d.paused = statement
+
+	if d.pause(reason) == Quit {
+		return ErrQuit
+	}
+
+	return nil
+}
+
+// Iteration lets a breakpoint pause the program at the same statement again in the next pass of a loop
+func (d *Debugger) Iteration(loop ast.Expression) {
+	if !d.evaluating {
+		d.paused = nil
+	}
+}
+
+func (d *Debugger) pause(reason string) Resume {
+	d.mode = d.Paused(reason)
+	d.depth = len(d.frames)
+
+	return d.mode
+}
+
+func (d *Debugger) Call(fn *object.Function, env *object.Environment) {
+	if d.evaluating {
+		return
+	}
+
+	d.frames = append(d.frames, &Frame{Name: evaluator.FunctionName(fn), Function: fn, Env: env})
+}
+
+func (d *Debugger) Return(fn *object.Function, result object.Object) {
+	if d.evaluating || len(d.frames) == 1 {
+		return
+	}
+
+	d.frames = d.frames[:len(d.frames)-1]
+
+	// Stepping out pauses in the caller right after the call instead of at its next statement
+	if d.mode == StepOut && len(d.frames) < d.depth {
+		d.pause("return")
+	}
+}
+
+// Evaluate evaluates an expression in the environment of a paused frame without stopping at breakpoints
+func (d *Debugger) Evaluate(input string, frame *Frame) (object.Object, error) {
+	pars := parser.New(lexer.New(input))
+	program := pars.ParseProgram()
+
+	if len(pars.Errors()) > 0 {
+		return nil, errors.New(strings.Join(pars.Errors(), "\n"))
+	}
+
+	d.evaluating = true
+	defer func() { d.evaluating = false }()
+
+	result := evaluator.Evaluate(program, frame.Env)
+
+	if err, ok := result.(*object.Error); ok {
+		return nil, errors.New(err.Message)
+	}
+
+	return result, nil
+}
+
+// Environments returns the environment of the frame followed by every environment enclosing it
+func Environments(frame *Frame) []*object.Environment {
+	environments := []*object.Environment{}
+
+	for env := frame.Env; env != nil; env = env.Outer() {
+		environments = append(environments, env)
+	}
+
+	return environments
+}
@@ -0,0 +1,151 @@
+package debugger
+
+import (
+	"bytes"
+	"fmt"
+	"go++/ast"
+	"go++/lexer"
+	"go++/object"
+	"go++/parser"
+	"strings"
+	"testing"
+)
+
+const program = `let add = fn (a, b) {
+	let sum = a + b
+	sum
+}
+let mut total = 0
+total = add(total, 1)
+total = add(total, 2)
+total`
+
+func parseProgram(t *testing.T, input string) *ast.Program {
+	pars := parser.New(lexer.New(input))
+	program := pars.ParseProgram()
+
+	if len(pars.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", pars.Errors())
+	}
+
+	return program
+}
+
+func TestStepping(t *testing.T) {
+	tests := []struct {
+		name        string
+		breakpoints []int
+		resumes     []Resume
+		expected    []string
+	}{
+		{"step in", nil, []Resume{StepIn, StepIn, StepIn, StepIn, StepIn, Continue}, []string{"main:1", "main:5", "main:6", "add:2", "add:3", "main:7"}},
+		{"step over", nil, []Resume{StepOver, StepOver, StepOver, StepOver, Continue}, []string{"main:1", "main:5", "main:6", "main:7", "main:8"}},
+		{"step out", []int{2}, []Resume{Continue, StepOut, Continue, Continue}, []string{"main:1", "add:2", "main:6", "add:2"}},
+		{"breakpoints", []int{3, 7}, []Resume{Continue, Continue, Continue, Continue}, []string{"main:1", "add:3", "main:7", "add:3"}},
+	}
+
+	for _, tt := range tests {
+		stops := []string{}
+
+		var d *Debugger
+
+		d = New(func(reason string) Resume {
+			frame := d.Frames()[0]
+			stops = append(stops, fmt.Sprintf("%s:%d", frame.Name, frame.Line))
+
+			resume := tt.resumes[0]
+			tt.resumes = tt.resumes[1:]
+
+			return resume
+		})
+
+		for _, line := range tt.breakpoints {
+			d.SetBreakpoint(line)
+		}
+
+		result := d.Run(parseProgram(t, program), object.NewEnvironment(), true)
+
+		if integer, ok := result.(*object.Integer); !ok || integer.Value != 3 {
+			t.Errorf("%s: wrong result. got=%T (%+v)", tt.name, result, result)
+		}
+
+		if strings.Join(stops, " ") != strings.Join(tt.expected, " ") {
+			t.Errorf("%s: wrong stops. want=%v, got=%v", tt.name, tt.expected, stops)
+		}
+	}
+}
+
+func TestBreakpointInLoop(t *testing.T) {
+	tests := []struct {
+		input      string
+		breakpoint int
+		expected   string
+	}{
+		{"let mut i = 0\nfor i < 3 {\n  i = i + 1\n}\ni", 3, "3 3 3"},
+		{"let mut total = 0\nfor v in [1, 2] {\n  total = total + v\n}\ntotal", 3, "3 3"},
+		{"let mut total = 0\nfor v in [1, 2] { total = total + v }\ntotal", 2, "2 2 2"},
+	}
+
+	for _, tt := range tests {
+		lines := []string{}
+
+		var d *Debugger
+
+		d = New(func(reason string) Resume {
+			lines = append(lines, fmt.Sprint(d.Frames()[0].Line))
+
+			return Continue
+		})
+
+		d.SetBreakpoint(tt.breakpoint)
+
+		result := d.Run(parseProgram(t, tt.input), object.NewEnvironment(), false)
+
+		if integer, ok := result.(*object.Integer); !ok || integer.Value != 3 {
+			t.Errorf("wrong result of %q. got=%T (%+v)", tt.input, result, result)
+		}
+
+		if strings.Join(lines, " ") != tt.expected {
+			t.Errorf("wrong stops in %q. want=%q, got=%q", tt.input, tt.expected, strings.Join(lines, " "))
+		}
+	}
+}
+
+func TestConsole(t *testing.T) {
+	input := strings.Join([]string{"b 2", "c", "bt", "l", "p a + b * 10", "frame 1", "p total", "p nope", "o", "q"}, "\n")
+
+	var out bytes.Buffer
+
+	result := NewConsole("test.gopp", program, strings.NewReader(input), &out).Run(parseProgram(t, program), object.NewEnvironment())
+
+	if _, ok := result.(*object.Error); !ok {
+		t.Errorf("expected quitting to stop the program. got=%T (%+v)", result, result)
+	}
+
+	expected := `step at test.gopp:1 in main
+    1	let add = fn (a, b) {
+(gopp) breakpoint at test.gopp:2
+(gopp) breakpoint at test.gopp:2 in add
+    2	let sum = a + b
+(gopp) * 0 add at test.gopp:2
+  1 main at test.gopp:6
+(gopp) locals:
+	let mut a = 0
+	let mut b = 1
+globals:
+	let add = fn(a, b)
+	let mut total = 0
+(gopp) 10
+(gopp)   0 add at test.gopp:2
+* 1 main at test.gopp:6
+(gopp) 0
+(gopp) error: identifier not found: nope
+(gopp) return at test.gopp:6 in main
+    6	total = add(total, 1)
+(gopp) program stopped: debugging stopped
+`
+
+	if out.String() != expected {
+		t.Errorf("wrong console output. want=\n%s\ngot=\n%s", expected, out.String())
+	}
+}
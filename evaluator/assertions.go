@@ -0,0 +1,160 @@
+package evaluator
+
+import (
+	"fmt"
+	"go++/object"
+	"strconv"
+	"strings"
+)
+
+// The assertion builtins used by gopp test, a failed assertion is an error that stops the test
+
+func init() {
+	builtins["assert"] = &object.Builtin{Fn: assert}
+	builtins["assertEqual"] = &object.Builtin{Fn: assertEqual}
+
+	// assertError applies functions which reach the builtins again, so it can't be in their initializer
+	builtins["assertError"] = &object.Builtin{Fn: assertError}
+}
+
+// assert(condition[, message]) fails when the condition isn't truthy
+func assert(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("ERROR: assert takes a condition and an optional message, got %d arguments", len(args))
+	}
+
+	if isObjectTruthy(args[0]) {
+		return NULL
+	}
+
+	if len(args) == 2 {
+		return newError("assertion failed: %s", args[1].Inspect())
+	}
+
+	return newError("assertion failed")
+}
+
+// assertEqual(actual, expected) fails when the values differ, the message shows both and where they differ
+func assertEqual(args ...object.Object) object.Object {
+	if len(args) != 2 {
+		return newError("ERROR: assertEqual takes an actual and an expected value, got %d arguments", len(args))
+	}
+
+	actual, expected := args[0], args[1]
+
+	if objectsEqual(actual, expected) {
+		return NULL
+	}
+
+	var out strings.Builder
+
+	out.WriteString("assertEqual failed\n")
+	out.WriteString("\twant: " + describe(expected) + "\n")
+	out.WriteString("\tgot:  " + describe(actual))
+
+	if path, want, got, ok := firstDifference("", expected, actual); ok && path != "" {
+		out.WriteString(fmt.Sprintf("\n\tat %s: want %s, got %s", path, describe(want), describe(got)))
+	}
+
+	return &object.Error{Message: out.String()}
+}
+
+// assertError(fn[, substring]) applies fn without arguments and fails unless it results in an error,
+// which has to contain the substring when one is given
+func assertError(args ...object.Object) object.Object {
+	if len(args) != 1 && len(args) != 2 {
+		return newError("ERROR: assertError takes a function and an optional message, got %d arguments", len(args))
+	}
+
+	result := applyFunction(args[0], []object.Object{})
+
+	err, ok := result.(*object.Error)
+
+	if !ok {
+		return newError("assertError failed: expected an error, got %s", describe(result))
+	}
+
+	if len(args) == 2 && !strings.Contains(err.Message, args[1].Inspect()) {
+		return newError("assertError failed: expected an error containing %s, got %s", strconv.Quote(args[1].Inspect()), strconv.Quote(err.Message))
+	}
+
+	return NULL
+}
+
+// objectsEqual compares values by type and content, arrays element by element and everything else by identity
+func objectsEqual(a, b object.Object) bool {
+	if a.Type() != b.Type() {
+		return false
+	}
+
+	switch a := a.(type) {
+	case *object.Integer:
+		return a.Value == b.(*object.Integer).Value
+	case *object.String:
+		return a.Value == b.(*object.String).Value
+	case *object.Boolean:
+		return a.Value == b.(*object.Boolean).Value
+	case *object.Null:
+		return true
+	case *object.Array:
+		other := b.(*object.Array)
+
+		if len(a.Values) != len(other.Values) {
+			return false
+		}
+
+		for i := range a.Values {
+			if !objectsEqual(a.Values[i], other.Values[i]) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return a == b
+	}
+}
+
+// firstDifference finds the innermost array element where want and got differ
+func firstDifference(path string, want, got object.Object) (string, object.Object, object.Object, bool) {
+	if objectsEqual(want, got) {
+		return "", nil, nil, false
+	}
+
+	wantArray, wantOk := want.(*object.Array)
+	gotArray, gotOk := got.(*object.Array)
+
+	if !wantOk || !gotOk || len(wantArray.Values) != len(gotArray.Values) {
+		return path, want, got, true
+	}
+
+	for i := range wantArray.Values {
+		if elementPath, w, g, ok := firstDifference(path+"["+strconv.Itoa(i)+"]", wantArray.Values[i], gotArray.Values[i]); ok {
+			return elementPath, w, g, true
+		}
+	}
+
+	return path, want, got, true
+}
+
+// describe shows a value the way it would be written, so 1 and "1" can be told apart
+func describe(obj object.Object) string {
+	switch obj := obj.(type) {
+	case *object.String:
+		return strconv.Quote(obj.Value)
+	case *object.Array:
+		items := make([]string, len(obj.Values))
+
+		for i, value := range obj.Values {
+			items[i] = describe(value)
+		}
+
+		return "[" + strings.Join(items, ", ") + "]"
+	case *object.Function:
+		return "fn"
+	case *object.Error:
+		return "error " + strconv.Quote(obj.Message)
+	default:
+		return obj.Inspect()
+	}
+}
@@ -0,0 +1,78 @@
+package evaluator
+
+import (
+	lex "go++/lexer"
+	"go++/object"
+	parse "go++/parser"
+	"testing"
+)
+
+var benchmarks = []struct {
+	name  string
+	input string
+}{
+	{"Arithmetic", `
+let mut sum = 0
+let mut i = 0
+
+for i < 1000 {
+	sum = sum + i * 2 - 1
+	i = i + 1
+}
+`},
+	{"Recursion", `
+let fib = fn (n) {
+	if n < 2 {
+		return n
+	}
+
+	fib(n - 1) + fib(n - 2)
+}
+
+fib(15)
+`},
+	{"Strings", `
+let mut s = ""
+let mut i = 0
+
+for i < 200 {
+	s = s + "x"
+	i = i + 1
+}
+
+s.length()
+`},
+	{"Methods", `
+let values = [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]
+let mut i = 0
+
+for i < 100 {
+	values.map(fn (k, v) { v.add(i) })
+	i = i + 1
+}
+`},
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	for _, bm := range benchmarks {
+		program := parse.New(lex.New(bm.input)).ParseProgram()
+
+		b.Run(bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				if result := Evaluate(program, object.NewEnvironment()); isError(result) {
+					b.Fatalf("evaluation failed: %s", result.Inspect())
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkNewInteger(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		newInteger(int64(i))
+	}
+}
@@ -0,0 +1,58 @@
+package evaluator
+
+import "go++/object"
+
+// Budget limits how much work a program may do, a program that exceeds it stops with an error.
+// A zero limit is no limit.
+type Budget struct {
+	// Steps counts statements, loop iterations and function calls
+	Steps int
+
+	// Depth is how deeply function calls may nest
+	Depth int
+
+	steps int
+	depth int
+}
+
+// Limits is nil unless the program being evaluated runs under a budget, it is used up by a single program
+var Limits *Budget
+
+func spendStep() *object.Error {
+	if Limits == nil || Limits.Steps == 0 {
+		return nil
+	}
+
+	Limits.steps += 1
+
+	if Limits.steps > Limits.Steps {
+		return newError("ERROR: execution budget of %d steps exceeded", Limits.Steps)
+	}
+
+	return nil
+}
+
+func enterCall() *object.Error {
+	if err := spendStep(); err != nil {
+		return err
+	}
+
+	if Limits == nil {
+		return nil
+	}
+
+	Limits.depth += 1
+
+	if Limits.Depth != 0 && Limits.depth > Limits.Depth {
+		Limits.depth -= 1
+		return newError("ERROR: call depth of %d exceeded", Limits.Depth)
+	}
+
+	return nil
+}
+
+func leaveCall() {
+	if Limits != nil {
+		Limits.depth -= 1
+	}
+}
@@ -4,38 +4,67 @@ import (
 	"bytes"
 	"fmt"
 	"go++/object"
+	"io"
+	"os"
+	"sort"
 )
 
+// Output is where the printing builtins write to, tools running programs can redirect it
+var Output io.Writer = os.Stdout
+
 var builtins = map[string]*object.Builtin{
 	"println": {
 		Fn: func(args ...object.Object) object.Object {
-			fmt.Println(getStringFromArgs(args...))
+			fmt.Fprintln(Output, getStringFromArgs(args...))
 
 			return NULL
 		},
 	},
 	"print": {
 		Fn: func(args ...object.Object) object.Object {
-			fmt.Print(getStringFromArgs(args...))
+			fmt.Fprint(Output, getStringFromArgs(args...))
 
 			return NULL
 		},
 	},
 	"printf": {
 		Fn: func(args ...object.Object) object.Object {
+			if len(args) == 0 {
+				return newError("ERROR: printf takes a format string and its arguments, got 0 arguments")
+			}
+
 			format, ok := args[0].(*object.String)
 
 			if !ok {
 				return newError("ERROR: first argument in printf must be a format string")
 			}
 
-			fmt.Printf(format.Value, getStringFromArgs(args[1:]...))
+			fmt.Fprintf(Output, format.Value, getStringFromArgs(args[1:]...))
 
 			return NULL
 		},
 	},
 }
 
+func IsBuiltin(name string) bool {
+	_, ok := builtins[name]
+
+	return ok
+}
+
+// BuiltinNames returns the names of every builtin in alphabetical order
+func BuiltinNames() []string {
+	names := make([]string, 0, len(builtins))
+
+	for name := range builtins {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
 func getStringFromArgs(args ...object.Object) string {
 	var out bytes.Buffer
 
@@ -0,0 +1,27 @@
+package evaluator
+
+import "go++/ast"
+
+// CoverageHook is told about every statement that runs and every branch of an if that is taken
+type CoverageHook interface {
+	Statement(statement ast.Statement)
+
+	// Branch is called with whether the consequence was taken, an if without an else takes its
+	// alternative by doing nothing
+	Branch(expression *ast.IfExpression, consequence bool)
+}
+
+// Coverage is nil unless the coverage of a program is measured
+var Coverage CoverageHook
+
+func coverStatement(statement ast.Statement) {
+	if Coverage != nil {
+		Coverage.Statement(statement)
+	}
+}
+
+func coverBranch(expression *ast.IfExpression, consequence bool) {
+	if Coverage != nil {
+		Coverage.Branch(expression, consequence)
+	}
+}
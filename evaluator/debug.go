@@ -0,0 +1,51 @@
+package evaluator
+
+import (
+	"go++/ast"
+	"go++/object"
+)
+
+// DebugHook is told about every statement, function call and loop iteration while a program runs, a debugger pauses
+// the program by not returning. Returning an error from Statement stops the program with it.
+type DebugHook interface {
+	Statement(statement ast.Statement, env *object.Environment) *object.Error
+	Call(fn *object.Function, env *object.Environment)
+	Return(fn *object.Function, result object.Object)
+
+	// Iteration is called before every pass through the body of a loop
+	Iteration(loop ast.Expression)
+}
+
+// Debugger is nil unless a program is being debugged
+var Debugger DebugHook
+
+func debugStatement(statement ast.Statement, env *object.Environment) *object.Error {
+	if Debugger == nil {
+		return nil
+	}
+
+	return Debugger.Statement(statement, env)
+}
+
+func debugIteration(loop ast.Expression) {
+	if Debugger != nil {
+		Debugger.Iteration(loop)
+	}
+}
+
+// FunctionName returns the name a function was declared with, or finds the one it was bound to where it was defined
+func FunctionName(fn *object.Function) string {
+	if fn.Name != "" {
+		return fn.Name
+	}
+
+	for env := fn.Env; env != nil; env = env.Outer() {
+		for _, binding := range env.Bindings() {
+			if binding.Object == object.Object(fn) {
+				return binding.Name
+			}
+		}
+	}
+
+	return "fn"
+}
@@ -24,8 +24,12 @@ func Evaluate(node ast.Node, env *object.Environment) object.Object {
 		return newInteger(node.Value)
 	case *ast.StringLiteral:
 		return newString(node.Value)
+	case *ast.InterpolatedString:
+		return evaluateInterpolatedString(node, env)
 	case *ast.BooleanLiteral:
 		return nativeBoolToBooleanObject(node.Value)
+	case *ast.NullLiteral:
+		return NULL
 	case *ast.ArrayLiteral:
 		elements := make([]object.Object, len(node.Values))
 
@@ -39,14 +43,18 @@ func Evaluate(node ast.Node, env *object.Environment) object.Object {
 		return evaluateIdentifier(node, env)
 
 	case *ast.FunctionLiteral:
-		params := node.Parameters
-		body := node.Body
-		return newFunction(params, body, env)
+		return newFunction(node, env)
 
 	case *ast.ForLoopLiteral:
 		outerEnv := object.NewEnclosedEnvironment(env)
 
 		for isObjectTruthy(Evaluate(node.Condition, env)) {
+			if err := spendStep(); err != nil {
+				return err
+			}
+
+			debugIteration(node)
+
 			evaluated := evaluateBlockStatement(node.Body, outerEnv)
 
 			if isError(evaluated) {
@@ -54,13 +62,16 @@ func Evaluate(node ast.Node, env *object.Environment) object.Object {
 			}
 		}
 
+	case *ast.ForInLoopLiteral:
+		return evaluateForInLoop(node, env)
+
 	// ------- EXPRESSIONS -------
 
 	case *ast.AssignExpression:
 		return evaluateAssignExpression(node, env)
 
 	case *ast.CallExpression:
-		return evaluateCallExpression(node, env)
+		return evaluateChain(node, env)
 
 	case *ast.PrefixExpression:
 		right := Evaluate(node.Right, env)
@@ -78,6 +89,15 @@ func Evaluate(node ast.Node, env *object.Environment) object.Object {
 			return left
 		}
 
+		// The right operand of ?? is only evaluated when it's needed
+		if node.Operator == "??" {
+			if left != NULL {
+				return left
+			}
+
+			return Evaluate(node.Right, env)
+		}
+
 		right := Evaluate(node.Right, env)
 
 		if isError(right) {
@@ -87,10 +107,10 @@ func Evaluate(node ast.Node, env *object.Environment) object.Object {
 		return evaluateInfixExpression(node.Operator, left, right)
 
 	case *ast.MemberAccessExpression:
-		return evaluateMemberAccessExpression(node, env)
+		return evaluateChain(node, env)
 
 	case *ast.ArrayAccessExpression:
-		return evaluateArrayAccessExpression(node, env)
+		return evaluateChain(node, env)
 
 	case *ast.IfExpression:
 		return evaluateIfExpression(node, env)
@@ -111,6 +131,10 @@ func Evaluate(node ast.Node, env *object.Environment) object.Object {
 
 	case *ast.LetStatement:
 		return evaluateLetStatement(node, env)
+
+	// The function was bound when its block started
+	case *ast.FunctionStatement:
+		return NULL
 	}
 
 	return NULL
@@ -119,7 +143,20 @@ func Evaluate(node ast.Node, env *object.Environment) object.Object {
 func evaluateProgram(statements []ast.Statement, env *object.Environment) object.Object {
 	var result object.Object
 
+	hoistFunctions(statements, env)
+
 	for _, statement := range statements {
+		if err := debugStatement(statement, env); err != nil {
+			return err
+		}
+
+		if err := spendStep(); err != nil {
+			return err
+		}
+
+		coverStatement(statement)
+		profileStatement(statement)
+
 		result = Evaluate(statement, env)
 
 		switch result := result.(type) {
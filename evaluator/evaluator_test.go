@@ -21,6 +21,9 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"5 + 5 + 5 + 5 + 5 - 10", 15},
 		{"2 * 2 * 2 * 2 * 2", 32},
 		{"-50 + 100 - 50", 0},
+		{"0xFF + 0o17 + 0b1010", 280},
+		{"1_000_000 / 1e3", 1000},
+		{"-1.5e3", -1500},
 		{"5 * 2 + 10", 20},
 		{"50 / 2 * 2 + 10", 60},
 		{"2 * (5 + 10)", 30},
@@ -242,9 +245,132 @@ func TestErrorHandling(t *testing.T) {
 			`let x = 0 x = 5`,
 			"ERROR: Can't reassign immutable object: x",
 		},
+		{
+			"5.add.x",
+			"ERROR: cannot access x, METHOD values have no members",
+		},
+		{
+			"let n = if false { 1 } n.x",
+			"ERROR: cannot access x, NULL values have no members",
+		},
+		{
+			"let f = fn () { 1 } f.x()",
+			"ERROR: cannot access x, FUNCTION values have no members",
+		},
+		{
+			"let n = null n?.x[0].y + 1",
+			"type mismatch: NULL + INTEGER",
+		},
+		{
+			"5.x",
+			"Error: x is not member of 5",
+		},
+		{
+			"10 / (5 - 5)",
+			"ERROR: division by zero",
+		},
+		{
+			"let f = fn () { f() } f()",
+			"ERROR: call depth of 100 exceeded",
+		},
+		{
+			"for true { }",
+			"ERROR: execution budget of 1000 steps exceeded",
+		},
+		{
+			"let f = fn (x, y) { x + y } f(1)",
+			"ERROR: wrong number of arguments to f: want=2, got=1",
+		},
+		{
+			"let f = fn () { 1 } f(1, 2)",
+			"ERROR: wrong number of arguments to f: want=0, got=2",
+		},
+		{
+			"let f = fn (x, y = 1) { x } f(1, 2, 3)",
+			"ERROR: wrong number of arguments to f: want=1 to 2, got=3",
+		},
+		{
+			"let f = fn (x, ...rest) { x } f()",
+			"ERROR: wrong number of arguments to f: want=at least 1, got=0",
+		},
+		{
+			"let f = fn (x, y) { x } f(1, z: 2)",
+			"ERROR: f has no parameter named z",
+		},
+		{
+			"let f = fn (x, y) { x } f(1, x: 2)",
+			"ERROR: argument x given twice to f",
+		},
+		{
+			"let f = fn (x, y) { x } f(y: 2)",
+			"ERROR: missing argument x to f",
+		},
+		{
+			"let f = fn (x, ...rest) { x } f(1, rest: 2)",
+			"ERROR: f has no parameter named rest",
+		},
+		{
+			"let f = fn (x) { x } f(...1)",
+			"ERROR: cannot spread INTEGER, it is not an array",
+		},
+		{
+			"let f = fn (x = y) { x } f()",
+			"identifier not found: y",
+		},
+		{
+			"println(x: 1)",
+			"ERROR: builtin functions don't take named arguments",
+		},
+		{
+			"5.add(x: 1)",
+			"ERROR: add doesn't take named arguments",
+		},
+		{
+			"5.add()",
+			"ERROR: wrong number of arguments to add: want=1, got=0",
+		},
+		{
+			`"hello".length(1)`,
+			"ERROR: wrong number of arguments to length: want=0, got=1",
+		},
+		{
+			"[1, 2].map(fn (v) { v })",
+			"ERROR: wrong number of arguments to fn(v): want=1, got=2",
+		},
+		{
+			"[1, 2].forEach(fn (k, v) { v + true })",
+			"type mismatch: INTEGER + BOOLEAN",
+		},
+		{
+			"[1][-1]",
+			"ERROR: index -1 out of range",
+		},
+		{
+			"printf()",
+			"ERROR: printf takes a format string and its arguments, got 0 arguments",
+		},
+		{
+			"let mut a = [1] a[1] = 2",
+			"index out of range: 1",
+		},
+		{
+			"let mut a = [1] a[-1] = 2",
+			"index out of range: -1",
+		},
+		{
+			"let mut x = 1 x[0] = 2",
+			"not an array: INTEGER",
+		},
+		{
+			`let mut a = [1] a["0"] = 2`,
+			"not an integer: STRING",
+		},
 	}
 
+	defer func() { Limits = nil }()
+
 	for _, tt := range tests {
+		Limits = &Budget{Steps: 1000, Depth: 100}
 		evaluated := testEvaluation(tt.input)
 
 		errObj, ok := evaluated.(*object.Error)
@@ -271,6 +397,8 @@ func TestLetStatements(t *testing.T) {
 		{"let x = 5 * 5; let y = x; y;", 25},
 		{"let x = 5 * 5; let y = x + 5; y;", 30},
 		{"let x = 5 * 5; let y = 5; let z = x - 4 * y; x * 2 + y * 3 / 5 + z", 58},
+		{"let x1 = 2; let x2 = 3; x1 * x2", 6},
+		{"let größe = 4; let π = 3; größe * π", 12},
 	}
 
 	for _, tt := range tests {
@@ -331,6 +459,7 @@ func TestStringConcatenation(t *testing.T) {
 	}{
 		{`"hello" + " " + "world"`, "hello world"},
 		{`"amount: " + 2`, "amount: 2"},
+		{`2 + " apples"`, "2 apples"},
 	}
 
 	for _, tt := range tests {
@@ -347,6 +476,41 @@ func TestStringConcatenation(t *testing.T) {
 	}
 }
 
+func TestStringInterpolation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let name = "gopp" let n = 2 "hello ${name}, you have ${n + 1} items"`, "hello gopp, you have 3 items"},
+		{`"${1}${2}"`, "12"},
+		{`"${[1, "a"]} ${true} ${fn (x) { x }}"`, "[1, a] true fn(x)"},
+		{`let n = 3 "outer ${"inner ${n * 2}"}"`, "outer inner 6"},
+		{`let f = fn () { "x" } "${f()}${if true { "y" }}"`, "xy"},
+		{`"\${n}"`, "${n}"},
+		{`"$ {} $"`, "$ {} $"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluation(tt.input)
+		str, ok := evaluated.(*object.String)
+
+		if !ok {
+			t.Errorf("object is not String for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		if str.Value != tt.expected {
+			t.Errorf("string has wrong value for %q. want=%q, got=%q", tt.input, tt.expected, str.Value)
+		}
+	}
+
+	evaluated := testEvaluation(`"a ${missing} b"`)
+
+	if err, ok := evaluated.(*object.Error); !ok || err.Message != "identifier not found: missing" {
+		t.Errorf("wrong error. got=%T (%+v)", evaluated, evaluated)
+	}
+}
+
 func TestIntegerAssignment(t *testing.T) {
 	input := `
 	let mut x = 0
@@ -439,6 +603,8 @@ func TestMethods(t *testing.T) {
 	}{
 		{`"hello".length()`, "5"},
 		{`5.add(3)`, "8"},
+		{"let add = 5.add\n6.add(1)\nadd(1)", "6"},
+		{"let length = [1, 2].length\nlet lengths = [[1, 2, 3]].map(fn (k, v) { v.length() })\nlength()", "2"},
 	}
 
 	for _, tt := range tests {
@@ -480,6 +646,285 @@ func TestBuiltinMethods(t *testing.T) {
 	}
 }
 
+func TestTypeAssertions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let add = fn (a: int, b: int) -> int { a + b } add(1, 2)", 3},
+		{`let add = fn (a: int, b: int) -> int { a + b } add("1", 2)`, "type assertion failed: argument a of fn(a: int, b: int) -> int must be int, got STRING"},
+		{`let f = fn (a) -> string { a } f(1)`, "type assertion failed: fn(a) -> string must return string, got INTEGER"},
+		{`let f = fn (xs: [int]) { 1 } f([1, "a"])`, "type assertion failed: argument xs of fn(xs: [int]) must be [int], got ARRAY"},
+		{"let f = fn (xs: [int], g: fn) { 1 } f([1, 2], f)", 1},
+		{"let f = fn () -> null { } f()", nil},
+		{"let f = fn ([a, b]: [int]) { a + b } f([1, 2])", 3},
+		{`let f = fn ([a, b]: [int]) { a } f(["1", 2])`, "type assertion failed: argument [a, b] of fn([a, b]: [int]) must be [int], got ARRAY"},
+	}
+
+	TypeAssertions = true
+	defer func() { TypeAssertions = false }()
+
+	for _, tt := range tests {
+		evaluated := testEvaluation(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			errObj, ok := evaluated.(*object.Error)
+
+			if !ok {
+				t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+
+			if errObj.Message != expected {
+				t.Errorf("wrong error message. got=%q, want=%q", errObj.Message, expected)
+			}
+		default:
+			if evaluated != nil && evaluated != NULL {
+				t.Errorf("object is not NULL. got=%T (%+v)", evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"assert(1 < 2)", ""},
+		{"assert(1 > 2)", "assertion failed"},
+		{`assert(false, "one is not two")`, "assertion failed: one is not two"},
+		{"assertEqual([1, [2, 3]], [1, [2, 3]])", ""},
+		{`assertEqual(1, "1")`, "assertEqual failed\n\twant: \"1\"\n\tgot:  1"},
+		{"assertEqual([1, [2, 3]], [1, [2, 4]])", "assertEqual failed\n\twant: [1, [2, 4]]\n\tgot:  [1, [2, 3]]\n\tat [1][1]: want 4, got 3"},
+		{"assertEqual([1], [1, 2])", "assertEqual failed\n\twant: [1, 2]\n\tgot:  [1]"},
+		{`assertError(fn () { [1][2] }, "out of range")`, ""},
+		{"assertError(fn () { 1 })", "assertError failed: expected an error, got 1"},
+		{`assertError(fn () { [1][2] }, "not a function")`, `assertError failed: expected an error containing "not a function", got "ERROR: index 2 out of range"`},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluation(tt.input)
+
+		if tt.expected == "" {
+			testNullObject(t, evaluated)
+			continue
+		}
+
+		errObj, ok := evaluated.(*object.Error)
+
+		if !ok {
+			t.Errorf("object is not Error. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. got=%q, want=%q", errObj.Message, tt.expected)
+		}
+	}
+}
+
+func TestCallingConvention(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let fact = fn (n) { if n < 2 { return 1 } n * fact(n - 1) } fact(10)", "3628800"},
+		{"let even = fn (n) { if n == 0 { return true } odd(n - 1) } let odd = fn (n) { if n == 0 { return false } even(n - 1) } even(10)", "true"},
+		{"let length = fn (xs) { xs.length() } let xs = [[1], [1, 2], [1, 2, 3]] xs.map(fn (k, v) { length(v) })", "[1, 2, 3]"},
+		{"let xs = [1, 2] xs.map(fn (k, v) { xs.map(fn (j, w) { v * 10 + w }) })", "[[11, 12], [21, 22]]"},
+		{"let add = 1.add let xs = [2, 3] xs.map(fn (k, v) { add(v.add(add(0))) })", "[4, 5]"},
+		{"let depth = fn (xs) { xs.map(fn (k, v) { if k == 0 { v } else { depth([v]) } }) } depth([1, 2])", "[1, [2]]"},
+		{`let s = "aa" let t = s.replace("a", "b") s + t`, "aabb"},
+		{"let mut sum = 0 let square = fn (x) { x * x } let xs = [1, 2, 3] xs.forEach(fn (k, v) { sum = sum + square(v) }) sum", "14"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluation(tt.input)
+
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. want=%s got=%T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestParametersAndArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let f = fn (x, y = 10) { x + y } f(1)", "11"},
+		{"let f = fn (x, y = 10) { x + y } f(1, 2)", "3"},
+		{"let f = fn (x, y = x * 2) { y } f(4)", "8"},
+		{"let f = fn (first, ...rest) { rest } f(1, 2, 3)", "[2, 3]"},
+		{"let f = fn (first, ...rest) { rest } f(1)", "[]"},
+		{"let f = fn (x, y, z) { x * 100 + y * 10 + z } let xs = [1, 2, 3] f(...xs)", "123"},
+		{"let f = fn (x, y, z) { x * 100 + y * 10 + z } let xs = [2, 3] f(1, ...xs)", "123"},
+		{"let f = fn (...xs) { xs } let ys = [2] f(1, ...ys, 3, ...ys)", "[1, 2, 3, 2]"},
+		{"let f = fn (x, y = 2, z = 3) { x * 100 + y * 10 + z } f(1, z: 5)", "125"},
+		{"let f = fn (x, y) { x - y } f(y: 1, x: 5)", "4"},
+		{"let f = fn (x, y = 0, ...rest) { [x, y, rest] } f(1, 2, 3, 4)", "[1, 2, [3, 4]]"},
+		{"let f = fn (x, y = 0, ...rest) { [x, y, rest] } f(1, y: 5)", "[1, 5, []]"},
+		{"let sum = fn (...xs) { let mut total = 0 xs.forEach(fn (k, v) { total = total + v }) total } let xs = [1, 2, 3] sum(...xs, 4)", "10"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluation(tt.input)
+
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. want=%s got=%T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestFunctionStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn add(x, y) { x + y } add(1, 2)", "3"},
+		{"let x = double(4) fn double(n) { n * 2 } x", "8"},
+		{"fn even(n) { if n == 0 { return true } odd(n - 1) } fn odd(n) { if n == 0 { return false } even(n - 1) } odd(7)", "true"},
+		{"fn outer() { let x = inner(2) fn inner(n) { n + 1 } x } outer()", "3"},
+		{"let mut i = 0 let mut sum = 0 for i < 3 { sum = sum + step(i) fn step(n) { n * 10 } i = i + 1 } sum", "30"},
+		{"fn add(x, y) { x + y } add", "fn add(x, y)"},
+		{"let add = fn (x, y) { x + y } add", "fn(x, y)"},
+		{"fn f() { }", "null"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluation(tt.input)
+
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. want=%s got=%T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"fn add(x, y) { x + y } add(1)", "ERROR: wrong number of arguments to add: want=2, got=1"},
+		{"let f = fn () { fn g(x) { x } g } let h = f() h()", "ERROR: wrong number of arguments to g: want=1, got=0"},
+		{"fn f() { 1 } f = 2", "ERROR: Can't reassign immutable object: f"},
+		{"fn f() { } if true { inner() } fn g() { fn inner() { } }", "identifier not found: inner"},
+	}
+
+	for _, tt := range errors {
+		evaluated := testEvaluation(tt.input)
+		err, ok := evaluated.(*object.Error)
+
+		if !ok || err.Message != tt.expected {
+			t.Errorf("wrong error for %q. want=%q got=%T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = [1, 2] a + b", "3"},
+		{"let [first, ...rest] = [1, 2, 3] rest", "[2, 3]"},
+		{"let [...all] = [] all", "[]"},
+		{"let [[a, b], c] = [[1, 2], 3] a * b * c", "6"},
+		{"let mut [a, b] = [1, 2] a = b a", "2"},
+		{"fn add([x, y]) { x + y } add([3, 4])", "7"},
+		{"fn f(n, [x, ...xs] = [0]) { n + x + xs.length() } f(1)", "1"},
+		{"let mut sum = 0 for x in [1, 2, 3] { sum = sum + x } sum", "6"},
+		{"let mut sum = 0 for [k, v] in [[1, 2], [3, 4]] { sum = sum + k * v } sum", "14"},
+		{"fn find(xs) { for x in xs { if x > 1 { return x } } 0 } find([1, 2, 3])", "2"},
+		{"let mut f = fn () { 0 } for n in [1, 2, 3] { if n == 1 { f = fn () { n } } } f()", "1"},
+		{"for x in [] { x }", "null"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluation(tt.input)
+
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. want=%s got=%T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = [1]", "ERROR: cannot destructure an array of 1 elements into [a, b]"},
+		{"let [a] = [1, 2]", "ERROR: cannot destructure an array of 2 elements into [a]"},
+		{"let [a, ...b] = []", "ERROR: cannot destructure an array of 0 elements into [a, ...b]"},
+		{"let [[a]] = [1]", "ERROR: cannot destructure INTEGER into [a]"},
+		{"let [a, b] = \"ab\"", "ERROR: cannot destructure STRING into [a, b]"},
+		{"let [a] = [1] a = 2", "ERROR: Can't reassign immutable object: a"},
+		{"fn f([x, y]) { x } f([1, 2, 3])", "ERROR: cannot destructure an array of 3 elements into [x, y]"},
+		{"fn f([x, y]) { x } f(x: 1)", "ERROR: f has no parameter named x"},
+		{"for [a, b] in [[1, 2], [3]] { a }", "ERROR: cannot destructure an array of 1 elements into [a, b]"},
+		{"for x in 5 { x }", "ERROR: cannot iterate over INTEGER"},
+		{"for x in [1] { x = 2 }", "ERROR: Can't reassign immutable object: x"},
+	}
+
+	for _, tt := range errors {
+		evaluated := testEvaluation(tt.input)
+		err, ok := evaluated.(*object.Error)
+
+		if !ok || err.Message != tt.expected {
+			t.Errorf("wrong error for %q. want=%q got=%T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
+func TestNullAndOptionalChaining(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"null", "null"},
+		{"null == null", "true"},
+		{"let n = null n?.x", "null"},
+		{"let n = null n?.x.y(1)[2]", "null"},
+		{"let n = null n?.[0]", "null"},
+		{"let xs = [[1, 2]] xs?.[0]?.[1]", "2"},
+		{`"abc"?.length()`, "3"},
+		{"null ?? 5", "5"},
+		{"0 ?? 5", "0"},
+		{"false ?? true", "false"},
+		{"null ?? null ?? 1 + 2", "3"},
+		{"let n = null n?.length() ?? 0", "0"},
+		{"let mut calls = 0 let count = fn () { calls = calls + 1 0 } let n = null n?.[count()] n?.x(count()) null ?? count() 1 ?? count() calls", "1"},
+		{"fn h(v) { v?.[-1] } h(null)", "null"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvaluation(tt.input)
+
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. want=%s got=%T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"fn h(v) { v?.[-1] } h([1])", "ERROR: index -1 out of range"},
+		{"let xs = [1] xs?.[1]", "ERROR: index 1 out of range"},
+		{"let n = null n?.x.y + 1", "type mismatch: NULL + INTEGER"},
+	}
+
+	for _, tt := range errors {
+		evaluated := testEvaluation(tt.input)
+		err, ok := evaluated.(*object.Error)
+
+		if !ok || err.Message != tt.expected {
+			t.Errorf("wrong error for %q. want=%q got=%T (%+v)", tt.input, tt.expected, evaluated, evaluated)
+		}
+	}
+}
+
 func testEvaluation(input string) object.Object {
 	lexer := lex.New(input)
 	parser := parse.New(lexer)
@@ -1,6 +1,7 @@
 package evaluator
 
 import (
+	"bytes"
 	"go++/ast"
 	"go++/object"
 )
@@ -30,7 +31,7 @@ func evaluateMinusPrefixOperatorExpression(right object.Object) object.Object {
 	}
 
 	value := right.(*object.Integer).Value
-	return &object.Integer{Value: -value}
+	return newInteger(-value)
 }
 
 func evaluateInfixExpression(operator string, left, right object.Object) object.Object {
@@ -42,7 +43,7 @@ func evaluateInfixExpression(operator string, left, right object.Object) object.
 	case left.Type() == object.STRING && right.Type() == object.INTEGER:
 		return evaluateStringInfixExpression(operator, left, intToString(right.(*object.Integer)))
 	case left.Type() == object.INTEGER && right.Type() == object.STRING:
-		return evaluateStringInfixExpression(operator, intToString(right.(*object.Integer)), right)
+		return evaluateStringInfixExpression(operator, intToString(left.(*object.Integer)), right)
 	case operator == "==":
 		return nativeBoolToBooleanObject(left == right)
 	case operator == "!=":
@@ -60,13 +61,17 @@ func evaluateIntegerInfixExpression(operator string, left, right object.Object)
 
 	switch operator {
 	case "+":
-		return &object.Integer{Value: leftVal + rightVal}
+		return newInteger(leftVal + rightVal)
 	case "-":
-		return &object.Integer{Value: leftVal - rightVal}
+		return newInteger(leftVal - rightVal)
 	case "*":
-		return &object.Integer{Value: leftVal * rightVal}
+		return newInteger(leftVal * rightVal)
 	case "/":
-		return &object.Integer{Value: leftVal / rightVal}
+		if rightVal == 0 {
+			return newError("ERROR: division by zero")
+		}
+
+		return newInteger(leftVal / rightVal)
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
@@ -86,7 +91,7 @@ func evaluateStringInfixExpression(operator string, left, right object.Object) o
 
 	switch operator {
 	case "+":
-		return &object.String{Value: leftVal + rightVal}
+		return newString(leftVal + rightVal)
 	case "<":
 		return nativeBoolToBooleanObject(leftVal < rightVal)
 	case ">":
@@ -100,6 +105,27 @@ func evaluateStringInfixExpression(operator string, left, right object.Object) o
 	}
 }
 
+// evaluateInterpolatedString puts the embedded values into the string the way they are printed
+func evaluateInterpolatedString(node *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out bytes.Buffer
+
+	for i, expression := range node.Expressions {
+		out.WriteString(node.Strings[i].Value)
+
+		value := Evaluate(expression, env)
+
+		if isError(value) {
+			return value
+		}
+
+		out.WriteString(value.Inspect())
+	}
+
+	out.WriteString(node.Strings[len(node.Strings)-1].Value)
+
+	return newString(out.String())
+}
+
 func evaluateIfExpression(node *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Evaluate(node.Condition, env)
 
@@ -108,6 +134,7 @@ func evaluateIfExpression(node *ast.IfExpression, env *object.Environment) objec
 	}
 
 	outerEnv := object.NewEnclosedEnvironment(env)
+	coverBranch(node, isObjectTruthy(condition))
 
 	if isObjectTruthy(condition) {
 		return Evaluate(node.Consequence, outerEnv)
@@ -118,11 +145,55 @@ func evaluateIfExpression(node *ast.IfExpression, env *object.Environment) objec
 	}
 }
 
+// evaluateForInLoop runs the body once for every element, each run gets its own environment so functions
+// created in the body keep the element they were created with
+func evaluateForInLoop(node *ast.ForInLoopLiteral, env *object.Environment) object.Object {
+	iterable := Evaluate(node.Iterable, env)
+
+	if isError(iterable) {
+		return iterable
+	}
+
+	array, ok := iterable.(*object.Array)
+
+	if !ok {
+		return newError("ERROR: cannot iterate over %s", iterable.Type())
+	}
+
+	for _, element := range array.Values {
+		if err := spendStep(); err != nil {
+			return err
+		}
+
+		debugIteration(node)
+
+		loopEnv := object.NewEnclosedEnvironment(env)
+
+		if err := bindPattern(node.Pattern, element, loopEnv, false); err != nil {
+			return err
+		}
+
+		evaluated := evaluateBlockStatement(node.Body, loopEnv)
+
+		if evaluated != nil && (evaluated.Type() == object.RETURN || evaluated.Type() == object.ERROR) {
+			return evaluated
+		}
+	}
+
+	return NULL
+}
+
 func evaluateIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
 	if builtin, ok := builtins[node.Value]; ok {
 		return builtin
 	}
 
+	if node.IsResolved {
+		if value, ok := env.GetAt(node.Depth, node.Slot, node.Value); ok {
+			return value
+		}
+	}
+
 	value, ok := env.Get(node.Value)
 
 	if !ok {
@@ -191,16 +262,16 @@ func assignArray(arrayAccess *ast.ArrayAccessExpression, evaluated object.Object
 	array, ok := evaluatedArray.(*object.Array)
 
 	if !ok {
-		return newError("not an array: %s", array.Type()), true
+		return newError("not an array: %s", evaluatedArray.Type()), true
 	}
 
 	index, ok := evaluatedIndex.(*object.Integer)
 
 	if !ok {
-		return newError("not an integer: %s", index.Type()), true
+		return newError("not an integer: %s", evaluatedIndex.Type()), true
 	}
 
-	if int(index.Value) > len(array.Values) {
+	if index.Value < 0 || int(index.Value) >= len(array.Values) {
 		return newError("index out of range: %d", index.Value), true
 	}
 
@@ -209,29 +280,113 @@ func assignArray(arrayAccess *ast.ArrayAccessExpression, evaluated object.Object
 	return nil, false
 }
 
-func evaluateCallExpression(node *ast.CallExpression, env *object.Environment) object.Object {
-	function := Evaluate(node.Function, env)
+// evaluateChain evaluates a chain of member accesses, indexes and calls like a?.b[0](), the whole chain is
+// null when a ?. in it meets null
+func evaluateChain(node ast.Expression, env *object.Environment) object.Object {
+	value, _ := evaluateChainLink(node, env)
 
+	return value
+}
+
+// evaluateChainLink reports false when a ?. up to and including this link met null, the rest of the chain is
+// skipped then
+func evaluateChainLink(node ast.Expression, env *object.Environment) (object.Object, bool) {
+	switch node := node.(type) {
+	case *ast.MemberAccessExpression:
+		left, ok := evaluateChainLink(node.Expression, env)
+
+		if !ok || node.IsOptional && left == NULL {
+			return NULL, false
+		}
+
+		return evaluateMemberAccessExpression(node, left), true
+	case *ast.ArrayAccessExpression:
+		array, ok := evaluateChainLink(node.Expression, env)
+
+		if !ok || node.IsOptional && array == NULL {
+			return NULL, false
+		}
+
+		return evaluateArrayAccessExpression(node, array, env), true
+	case *ast.CallExpression:
+		function, ok := evaluateChainLink(node.Function, env)
+
+		if !ok {
+			return NULL, false
+		}
+
+		return evaluateCallExpression(node, function, env), true
+	}
+
+	return Evaluate(node, env), true
+}
+
+func evaluateCallExpression(node *ast.CallExpression, function object.Object, env *object.Environment) object.Object {
 	if isError(function) {
 		return function
 	}
 
-	args := evaluateExpressions(node.Arguments, env)
+	args, named, err := evaluateArguments(node.Arguments, env)
 
-	if len(args) == 1 && isError(args[0]) {
-		return args[0]
+	if err != nil {
+		return err
 	}
 
-	return applyFunction(function, args)
+	return callFunction(function, args, named)
 }
 
-func evaluateMemberAccessExpression(node *ast.MemberAccessExpression, env *object.Environment) object.Object {
-	left := Evaluate(node.Expression, env)
+// evaluateArguments evaluates the arguments of a call in order, spread arrays are passed element by element
+func evaluateArguments(arguments []ast.Expression, env *object.Environment) ([]object.Object, []namedArgument, object.Object) {
+	args := []object.Object{}
+	named := []namedArgument{}
+
+	for _, argument := range arguments {
+		switch argument := argument.(type) {
+		case *ast.SpreadExpression:
+			value := Evaluate(argument.Value, env)
+
+			if isError(value) {
+				return nil, nil, value
+			}
+
+			array, ok := value.(*object.Array)
 
+			if !ok {
+				return nil, nil, newError("ERROR: cannot spread %s, it is not an array", value.Type())
+			}
+
+			args = append(args, array.Values...)
+		case *ast.NamedArgument:
+			value := Evaluate(argument.Value, env)
+
+			if isError(value) {
+				return nil, nil, value
+			}
+
+			named = append(named, namedArgument{name: argument.Name.Value, value: value})
+		default:
+			value := Evaluate(argument, env)
+
+			if isError(value) {
+				return nil, nil, value
+			}
+
+			args = append(args, value)
+		}
+	}
+
+	return args, named, nil
+}
+
+func evaluateMemberAccessExpression(node *ast.MemberAccessExpression, left object.Object) object.Object {
 	if isError(left) {
 		return left
 	}
 
+	if left.GetMembers() == nil {
+		return newError("ERROR: cannot access %s, %s values have no members", node.AccessedMember.Value, left.Type())
+	}
+
 	val, ok := left.GetMembers().Get(node.AccessedMember.Value)
 
 	if !ok {
@@ -243,22 +398,19 @@ func evaluateMemberAccessExpression(node *ast.MemberAccessExpression, env *objec
 	}
 
 	if method, ok := val.(*object.BuiltinMethod); ok {
-		method.It = left
-
-		return method
+		return object.NewBoundMethod(method, left)
 	}
 
 	return val
 }
 
-func evaluateArrayAccessExpression(node *ast.ArrayAccessExpression, env *object.Environment) object.Object {
-	index := Evaluate(node.Index, env)
-	array := Evaluate(node.Expression, env)
-
+func evaluateArrayAccessExpression(node *ast.ArrayAccessExpression, array object.Object, env *object.Environment) object.Object {
 	if isError(array) {
 		return array
 	}
 
+	index := Evaluate(node.Index, env)
+
 	if isError(index) {
 		return index
 	}
@@ -0,0 +1,65 @@
+package evaluator
+
+import (
+	lex "go++/lexer"
+	"go++/object"
+	parse "go++/parser"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Inputs of the evaluator tests, they seed the fuzzer with the programs in testPrograms
+var seeds = []string{
+	"5 + 5 + 5 + 5 - 10 * -2 / 2",
+	"!!true == (1 < 2) != (1 > 2)",
+	"if (1 > 2) { 10 } else { 20 }",
+	"if 10 > 1 { if 10 > 1 { return 10 } return 1 }",
+	"true + false",
+	"foobar",
+	`"Hello" + " " + "World!"`,
+	"let a = 5 let b = a let c = a + b + 5 c",
+	"let add = fn (x, y) { x + y } add(5 + 5, add(5, 5))",
+	"let mut x = 5 x = 10 x",
+	"let mut i = 0 for i < 10 { i = i + 1 } i",
+	`"hello".length()`,
+	"5.add(3)",
+	"let mut arr = [1, 2, 3] arr[0] = arr.map(fn (v, i) { v * i }) arr",
+	"let f = fn (n) { f(n + 1) } f(0)",
+	"for true { }",
+	`assertEqual([1, [2]], [1, [3]])`,
+	"let n = null n?.x.y(1)?.[0] ?? fn () { 1 }.x",
+}
+
+func FuzzEvaluate(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	files, _ := filepath.Glob("../testPrograms/*.gopp")
+
+	for _, file := range files {
+		if src, err := os.ReadFile(file); err == nil {
+			f.Add(string(src))
+		}
+	}
+
+	Output = io.Discard
+	defer func() { Output = os.Stdout }()
+
+	f.Fuzz(func(t *testing.T, input string) {
+		parser := parse.New(lex.New(input))
+		program := parser.ParseProgram()
+
+		if len(parser.Errors()) > 0 {
+			return
+		}
+
+		// Loops and recursion that never end have to stop with an error instead of hanging
+		Limits = &Budget{Steps: 10000, Depth: 200}
+		defer func() { Limits = nil }()
+
+		Evaluate(program, object.NewEnvironment())
+	})
+}
@@ -8,16 +8,42 @@ import (
 	"strconv"
 )
 
+// NewString, NewInteger and NewArray create values with their builtin methods for programs embedding the evaluator
+func NewString(value string) *object.String {
+	return newString(value)
+}
+
+func NewInteger(value int64) *object.Integer {
+	return newInteger(value)
+}
+
+func NewArray(values []object.Object) *object.Array {
+	return newArray(values)
+}
+
+// The method tables are built once, every value looks its methods up by its type
+func init() {
+	object.RegisterMethods(object.STRING, object.NewMembers(methods.GetBuiltinStringMethods(&stringHelperImpl{}), false))
+	object.RegisterMethods(object.INTEGER, object.NewMembers(methods.GetBuiltinNumberMethods(&numberHelperImpl{}), false))
+	object.RegisterMethods(object.ARRAY, object.NewMembers(methods.GetBuiltinArrayMethods(&arrayHelperImpl{}), false))
+}
+
 func newString(value string) *object.String {
-	return &object.String{Value: value, Members: object.ObjectMembers{Members: methods.GetBuiltinStringMethods(&stringHelperImpl{}), MutableMembers: false}}
+	str := &object.String{Value: value}
+	profileAllocation(str)
+
+	return str
 }
 
 func newInteger(value int64) *object.Integer {
-	return &object.Integer{Value: value, Members: object.ObjectMembers{Members: methods.GetBuiltinNumberMethods(&numberHelperImpl{}), MutableMembers: false}}
+	integer := &object.Integer{Value: value}
+	profileAllocation(integer)
+
+	return integer
 }
 
 func newBoolean(value bool) *object.Boolean {
-	return &object.Boolean{Value: value, Members: object.ObjectMembers{}}
+	return &object.Boolean{Value: value}
 }
 
 func newNull() *object.Null {
@@ -28,15 +54,22 @@ func newReturnValue(v object.Object) *object.ReturnValue {
 	return &object.ReturnValue{Value: v}
 }
 
-func newFunction(parameters []*ast.Identifier, body *ast.BlockStatement, env *object.Environment) *object.Function {
-	return &object.Function{Parameters: parameters, Body: body, Env: env}
+func newFunction(node *ast.FunctionLiteral, env *object.Environment) *object.Function {
+	fn := &object.Function{Token: node.Token, Parameters: node.Parameters, ReturnType: node.ReturnType, Body: node.Body, Env: env}
+
+	if node.Name != nil {
+		fn.Name = node.Name.Value
+	}
+	profileAllocation(fn)
+
+	return fn
 }
 
 func newArray(values []object.Object) *object.Array {
-	return &object.Array{
-		Values:  values,
-		Members: object.ObjectMembers{Members: methods.GetBuiltinArrayMethods(&arrayHelperImpl{}), MutableMembers: false},
-	}
+	array := &object.Array{Values: values}
+	profileAllocation(array)
+
+	return array
 }
 
 func newError(format string, a ...interface{}) *object.Error {
@@ -52,5 +85,5 @@ func nativeBoolToBooleanObject(isTrue bool) object.Object {
 }
 
 func intToString(integer *object.Integer) *object.String {
-	return &object.String{Value: strconv.Itoa(int(integer.Value))}
+	return newString(strconv.Itoa(int(integer.Value)))
 }
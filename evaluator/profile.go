@@ -0,0 +1,42 @@
+package evaluator
+
+import (
+	"go++/ast"
+	"go++/object"
+)
+
+// ProfileHook is told about every statement, function call and allocation of a program, it runs next to
+// a debugger and the coverage without taking their place
+type ProfileHook interface {
+	Statement(statement ast.Statement)
+	Call(fn *object.Function)
+	Return(fn *object.Function)
+	Allocated(obj object.Object)
+}
+
+// Profiler is nil unless a program is being profiled
+var Profiler ProfileHook
+
+func profileStatement(statement ast.Statement) {
+	if Profiler != nil {
+		Profiler.Statement(statement)
+	}
+}
+
+func profileCall(fn *object.Function) {
+	if Profiler != nil {
+		Profiler.Call(fn)
+	}
+}
+
+func profileReturn(fn *object.Function) {
+	if Profiler != nil {
+		Profiler.Return(fn)
+	}
+}
+
+func profileAllocation(obj object.Object) {
+	if Profiler != nil {
+		Profiler.Allocated(obj)
+	}
+}
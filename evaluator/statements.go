@@ -12,15 +12,75 @@ func evaluateLetStatement(node *ast.LetStatement, env *object.Environment) objec
 		return value
 	}
 
-	env.Set(node.Name.Value, value, node.IsMutable)
+	if err := bindPattern(node.Target(), value, env, node.IsMutable); err != nil {
+		return err
+	}
 
 	return NULL
 }
 
+// bindPattern binds the parts of a value to the names of a pattern in the order ast.PatternIdentifiers
+// returns them, the resolver gave them their slots in that order. An array has to have exactly as many
+// elements as the pattern, or at least as many when it has a rest element
+func bindPattern(pattern ast.Pattern, value object.Object, env *object.Environment, isMutable bool) object.Object {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		env.Set(pattern.Value, value, isMutable)
+	case *ast.ArrayPattern:
+		array, ok := value.(*object.Array)
+
+		if !ok {
+			return newError("ERROR: cannot destructure %s into %s", value.Type(), pattern)
+		}
+
+		if len(array.Values) < len(pattern.Elements) || pattern.Rest == nil && len(array.Values) > len(pattern.Elements) {
+			return newError("ERROR: cannot destructure an array of %d elements into %s", len(array.Values), pattern)
+		}
+
+		for i, element := range pattern.Elements {
+			if err := bindPattern(element, array.Values[i], env, isMutable); err != nil {
+				return err
+			}
+		}
+
+		if pattern.Rest != nil {
+			rest := make([]object.Object, len(array.Values)-len(pattern.Elements))
+			copy(rest, array.Values[len(pattern.Elements):])
+
+			env.Set(pattern.Rest.Value, newArray(rest), isMutable)
+		}
+	}
+
+	return nil
+}
+
+// hoistFunctions binds the functions declared in a block before its statements run, so they can be called
+// from anywhere in it and call each other regardless of their order
+func hoistFunctions(statements []ast.Statement, env *object.Environment) {
+	for _, statement := range statements {
+		if declaration, ok := statement.(*ast.FunctionStatement); ok {
+			env.Set(declaration.Function.Name.Value, newFunction(declaration.Function, env), false)
+		}
+	}
+}
+
 func evaluateBlockStatement(block *ast.BlockStatement, env *object.Environment) object.Object {
 	var result object.Object
 
+	hoistFunctions(block.Statements, env)
+
 	for _, statement := range block.Statements {
+		if err := debugStatement(statement, env); err != nil {
+			return err
+		}
+
+		if err := spendStep(); err != nil {
+			return err
+		}
+
+		coverStatement(statement)
+		profileStatement(statement)
+
 		result = Evaluate(statement, env)
 
 		if result != nil {
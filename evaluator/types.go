@@ -0,0 +1,86 @@
+package evaluator
+
+import (
+	"go++/ast"
+	"go++/object"
+)
+
+// TypeAssertions makes applying a function check its arguments and result against the
+// annotations in its signature, unannotated parameters accept any value
+var TypeAssertions = false
+
+func matchesAnnotation(obj object.Object, annotation *ast.TypeAnnotation) bool {
+	if annotation == nil {
+		return true
+	}
+
+	switch annotation.Name {
+	case "any":
+		return true
+	case "int":
+		return obj.Type() == object.INTEGER
+	case "string":
+		return obj.Type() == object.STRING
+	case "bool":
+		return obj.Type() == object.BOOLEAN
+	case "null":
+		return obj.Type() == object.NULL
+	case "fn":
+		return obj.Type() == object.FUNCTION || obj.Type() == object.BUILTIN || obj.Type() == object.METHOD
+	case "array":
+		array, ok := obj.(*object.Array)
+
+		if !ok {
+			return false
+		}
+
+		if annotation.Element != nil {
+			for _, value := range array.Values {
+				if !matchesAnnotation(value, annotation.Element) {
+					return false
+				}
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// assertArgumentTypes checks the values the parameters were bound to, the annotation of a rest parameter is
+// the type of the array it collects. Destructured arguments were checked by extendFunctionEnv
+func assertArgumentTypes(fn *object.Function, env *object.Environment) object.Object {
+	for _, param := range fn.Parameters {
+		if param.Pattern != nil {
+			continue
+		}
+
+		value, ok := env.Get(param.Name.Value)
+
+		if !ok {
+			continue
+		}
+
+		if !matchesAnnotation(value, param.Type) {
+			return newError("type assertion failed: argument %s of %s must be %s, got %s",
+				param.Name.Value, fn.Inspect(), param.Type.String(), value.Type())
+		}
+	}
+
+	return nil
+}
+
+func assertResultType(fn *object.Function, result object.Object) object.Object {
+	// An empty body doesn't produce a value
+	if result == nil {
+		result = NULL
+	}
+
+	if !matchesAnnotation(result, fn.ReturnType) {
+		return newError("type assertion failed: %s must return %s, got %s",
+			fn.Inspect(), fn.ReturnType.String(), result.Type())
+	}
+
+	return nil
+}
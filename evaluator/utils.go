@@ -1,36 +1,207 @@
 package evaluator
 
-import "go++/object"
+import (
+	"fmt"
+	"go++/object"
+	"strconv"
+)
+
+// namedArgument is a `name: value` argument of a call
+type namedArgument struct {
+	name  string
+	value object.Object
+}
 
 func applyFunction(fn object.Object, args []object.Object) object.Object {
+	return callFunction(fn, args, nil)
+}
+
+func callFunction(fn object.Object, args []object.Object, named []namedArgument) object.Object {
 	switch fn.(type) {
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn.(*object.Function), args)
-		evaluated := Evaluate(fn.(*object.Function).Body, extendedEnv)
+		if err := enterCall(); err != nil {
+			return err
+		}
+
+		defer leaveCall()
+
+		extendedEnv, err := extendFunctionEnv(fn.(*object.Function), args, named)
+
+		if err != nil {
+			return err
+		}
+
+		if TypeAssertions {
+			if err := assertArgumentTypes(fn.(*object.Function), extendedEnv); err != nil {
+				return err
+			}
+		}
+
+		if Debugger != nil {
+			Debugger.Call(fn.(*object.Function), extendedEnv)
+		}
+
+		profileCall(fn.(*object.Function))
+
+		evaluated := unwrapReturnValue(Evaluate(fn.(*object.Function).Body, extendedEnv))
+
+		// An empty body doesn't produce a value
+		if evaluated == nil {
+			evaluated = NULL
+		}
 
-		return unwrapReturnValue(evaluated)
+		if Debugger != nil {
+			Debugger.Return(fn.(*object.Function), evaluated)
+		}
+
+		profileReturn(fn.(*object.Function))
+
+		if TypeAssertions && !isError(evaluated) {
+			if err := assertResultType(fn.(*object.Function), evaluated); err != nil {
+				return err
+			}
+		}
+
+		return evaluated
 	case *object.Builtin:
+		if len(named) > 0 {
+			return newError("ERROR: builtin functions don't take named arguments")
+		}
+
 		return fn.(*object.Builtin).Fn(args...)
-	case *object.BuiltinMethod:
-		arguments := make([]object.Object, 1)
+	case *object.BoundMethod:
+		method := fn.(*object.BoundMethod).Method()
+
+		if len(named) > 0 {
+			return newError("ERROR: %s doesn't take named arguments", method.Name)
+		}
 
-		arguments[0] = fn.(*object.BuiltinMethod).It
-		arguments = append(arguments, args...)
+		if len(args) != method.Arity {
+			return newError("ERROR: wrong number of arguments to %s: want=%d, got=%d", method.Name, method.Arity, len(args))
+		}
 
-		return fn.(*object.BuiltinMethod).Fn(arguments...)
+		return method.Fn(fn.(*object.BoundMethod).Receiver(), args...)
 	default:
 		return newError("not a function: %s", fn.Type())
 	}
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+// extendFunctionEnv binds the parameters in the order they are declared, the resolver gave them their slots
+// in that order. Positional arguments come first, then named ones, the rest parameter gets the arguments
+// that are left over and defaults are evaluated with the parameters before them already bound
+func extendFunctionEnv(fn *object.Function, args []object.Object, named []namedArgument) (*object.Environment, object.Object) {
 	env := object.NewEnclosedEnvironment(fn.Env)
+	values := make([]object.Object, len(fn.Parameters))
+	positional := len(fn.Parameters)
+	rest := []object.Object{}
+
+	if positional > 0 && fn.Parameters[positional-1].IsRest {
+		positional -= 1
+	}
+
+	if len(args) > positional {
+		if positional == len(fn.Parameters) {
+			return nil, newError("ERROR: wrong number of arguments to %s: want=%s, got=%d", describeFunction(fn), arity(fn), len(args))
+		}
+
+		rest = append(rest, args[positional:]...)
+	}
+
+	copy(values, args)
+
+	for _, argument := range named {
+		i := parameterIndex(fn, argument.name)
+
+		if i == -1 {
+			return nil, newError("ERROR: %s has no parameter named %s", describeFunction(fn), argument.name)
+		}
+
+		if values[i] != nil {
+			return nil, newError("ERROR: argument %s given twice to %s", argument.name, describeFunction(fn))
+		}
+
+		values[i] = argument.value
+	}
+
+	for i, param := range fn.Parameters {
+		value := values[i]
+
+		switch {
+		case param.IsRest:
+			value = newArray(rest)
+		case value == nil && param.Default != nil:
+			if value = Evaluate(param.Default, env); isError(value) {
+				return nil, value
+			}
+		case value == nil && len(named) == 0:
+			return nil, newError("ERROR: wrong number of arguments to %s: want=%s, got=%d", describeFunction(fn), arity(fn), len(args))
+		case value == nil:
+			return nil, newError("ERROR: missing argument %s to %s", param.Target(), describeFunction(fn))
+		}
+
+		if param.Pattern == nil {
+			env.Set(param.Name.Value, value, true)
+			continue
+		}
+
+		// The argument has to be checked before it is taken apart
+		if TypeAssertions && !matchesAnnotation(value, param.Type) {
+			return nil, newError("type assertion failed: argument %s of %s must be %s, got %s",
+				param.Pattern, fn.Inspect(), param.Type.String(), value.Type())
+		}
+
+		if err := bindPattern(param.Pattern, value, env, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return env, nil
+}
+
+// parameterIndex returns the index of the parameter a named argument is bound to, rest parameters and
+// destructured ones can't be named
+func parameterIndex(fn *object.Function, name string) int {
+	for i, param := range fn.Parameters {
+		if param.Name != nil && param.Name.Value == name && !param.IsRest {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// arity describes how many arguments a function takes, like `2`, `1 to 3` or `at least 1`
+func arity(fn *object.Function) string {
+	required, optional, rest := 0, 0, false
+
+	for _, param := range fn.Parameters {
+		switch {
+		case param.IsRest:
+			rest = true
+		case param.Default != nil:
+			optional += 1
+		default:
+			required += 1
+		}
+	}
+
+	switch {
+	case rest:
+		return fmt.Sprintf("at least %d", required)
+	case optional > 0:
+		return fmt.Sprintf("%d to %d", required, required+optional)
+	default:
+		return strconv.Itoa(required)
+	}
+}
 
-	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx], true)
+// describeFunction names a function in errors, anonymous functions are described by their parameters
+func describeFunction(fn *object.Function) string {
+	if name := FunctionName(fn); name != "fn" {
+		return name
 	}
 
-	return env
+	return fn.Inspect()
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
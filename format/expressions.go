@@ -0,0 +1,412 @@
+package format
+
+import (
+	"fmt"
+	"go++/ast"
+	"go++/token"
+	"strings"
+	"unicode/utf8"
+)
+
+// Precedences follow the parser, they decide where the parentheses lost while parsing have to go back
+const (
+	lowest = iota
+	assign
+	coalesce
+	equals
+	lessGreater
+	sum
+	product
+	prefix
+	call
+	primary
+)
+
+var operatorPrecedences = map[string]int{
+	"??": coalesce,
+	"==": equals,
+	"!=": equals,
+	"<":  lessGreater,
+	">":  lessGreater,
+	"+":  sum,
+	"-":  sum,
+	"*":  product,
+	"/":  product,
+}
+
+func precedence(expression ast.Expression) int {
+	switch expression := expression.(type) {
+	case *ast.AssignExpression:
+		return assign
+	case *ast.InfixExpression:
+		return operatorPrecedences[expression.Operator]
+	case *ast.PrefixExpression:
+		return prefix
+	case *ast.CallExpression, *ast.MemberAccessExpression, *ast.ArrayAccessExpression:
+		return call
+	default:
+		return primary
+	}
+}
+
+// continuesPrevious reports whether a statement starts with (, [ or -, which continue the expression that ends
+// the statement before it. Only those statements skip a ; in front of the next one
+func continuesPrevious(previous ast.Statement, statement ast.Statement) bool {
+	switch previous := previous.(type) {
+	case *ast.LetStatement, *ast.ReturnStatement:
+	case *ast.ExpressionStatement:
+		switch previous.Expression.(type) {
+		case *ast.ForLoopLiteral, *ast.ForInLoopLiteral:
+			return false
+		}
+	default:
+		return false
+	}
+
+	expression, ok := statement.(*ast.ExpressionStatement)
+
+	if !ok {
+		return false
+	}
+
+	return strings.ContainsRune("([-", leadingCharacter(expression.Expression, lowest))
+}
+
+// leadingCharacter returns the character expression prints first, 0 when it doesn't matter which letter or digit it is
+func leadingCharacter(expression ast.Expression, minimum int) rune {
+	if expression == nil {
+		return 0
+	}
+
+	if precedence(expression) < minimum {
+		return '('
+	}
+
+	switch expression := expression.(type) {
+	case *ast.ArrayLiteral:
+		return '['
+	case *ast.PrefixExpression:
+		return rune(expression.Operator[0])
+	case *ast.InfixExpression:
+		return leadingCharacter(expression.Left, operatorPrecedences[expression.Operator])
+	case *ast.AssignExpression:
+		return leadingCharacter(expression.Assignee, call)
+	case *ast.CallExpression:
+		return leadingCharacter(expression.Function, call)
+	case *ast.MemberAccessExpression:
+		return leadingCharacter(expression.Expression, call)
+	case *ast.ArrayAccessExpression:
+		return leadingCharacter(expression.Expression, call)
+	}
+
+	return 0
+}
+
+// expression prints the expression, wrapped in parentheses when it binds looser than minimum
+func (p *printer) expression(expression ast.Expression, minimum int) {
+	if expression == nil {
+		return
+	}
+
+	if precedence(expression) < minimum {
+		p.write("(")
+		defer p.write(")")
+	}
+
+	switch expression := expression.(type) {
+	case *ast.Identifier:
+		p.mark(expression.Token)
+		p.write(expression.Value)
+	case *ast.IntegerLiteral:
+		p.mark(expression.Token)
+		p.write(expression.Token.Literal)
+	case *ast.BooleanLiteral:
+		p.mark(expression.Token)
+		p.write(expression.Token.Literal)
+	case *ast.NullLiteral:
+		p.mark(expression.Token)
+		p.write(expression.Token.Literal)
+	case *ast.StringLiteral:
+		p.mark(expression.Token)
+
+		if expression.IsRaw {
+			p.write("`" + expression.Value + "`")
+		} else {
+			p.write(`"` + spelling(expression) + `"`)
+		}
+	case *ast.InterpolatedString:
+		p.write(`"`)
+
+		for i, embedded := range expression.Expressions {
+			// Every part but the first follows the } of an expression
+			if i > 0 {
+				p.write("}")
+			}
+
+			p.mark(expression.Strings[i].Token)
+			p.write(spelling(expression.Strings[i]) + "${")
+			p.expression(embedded, lowest)
+		}
+
+		last := expression.Strings[len(expression.Strings)-1]
+
+		p.mark(last.Token)
+		p.write("}" + spelling(last) + `"`)
+	case *ast.ArrayLiteral:
+		p.mark(expression.Token)
+		p.list("[", expression.Values, expression.Token, expression.EndToken, "]")
+	case *ast.PrefixExpression:
+		p.mark(expression.Token)
+		p.write(expression.Operator)
+		p.expression(expression.Right, prefix)
+	case *ast.InfixExpression:
+		operator := operatorPrecedences[expression.Operator]
+
+		// Operators are left associative so only the right operand needs parentheses on equal precedence
+		p.expression(expression.Left, operator)
+		p.mark(expression.Token)
+		p.write(" " + expression.Operator + " ")
+		p.expression(expression.Right, operator+1)
+	case *ast.AssignExpression:
+		p.expression(expression.Assignee, call)
+		p.mark(expression.Token)
+		p.write(" = ")
+		p.expression(expression.Value, lowest)
+	case *ast.CallExpression:
+		p.expression(expression.Function, call)
+		p.mark(expression.Token)
+		p.list("(", expression.Arguments, expression.Token, expression.EndToken, ")")
+	case *ast.SpreadExpression:
+		p.mark(expression.Token)
+		p.write("...")
+		p.expression(expression.Value, lowest)
+	case *ast.NamedArgument:
+		p.mark(expression.Token)
+		p.write(expression.Name.Value + ": ")
+		p.expression(expression.Value, lowest)
+	case *ast.MemberAccessExpression:
+		p.expression(expression.Expression, call)
+		p.mark(expression.AccessedMember.Token)
+		p.write(expression.Token.Literal + expression.AccessedMember.Value)
+	case *ast.ArrayAccessExpression:
+		p.expression(expression.Expression, call)
+		p.mark(expression.Token)
+
+		if expression.IsOptional {
+			p.write("?.")
+		}
+
+		p.write("[")
+		p.expression(expression.Index, lowest)
+		p.write("]")
+	case *ast.IfExpression:
+		p.mark(expression.Token)
+		p.write("if ")
+		p.expression(expression.Condition, lowest)
+		p.write(" ")
+		p.block(expression.Consequence)
+
+		if expression.Alternative != nil {
+			p.write(" else ")
+			p.block(expression.Alternative)
+		}
+	case *ast.ForLoopLiteral:
+		p.mark(expression.Token)
+		p.write("for ")
+		p.expression(expression.Condition, lowest)
+		p.write(" ")
+		p.block(expression.Body)
+	case *ast.ForInLoopLiteral:
+		p.mark(expression.Token)
+		p.write("for ")
+		p.pattern(expression.Pattern)
+		p.write(" in ")
+		p.expression(expression.Iterable, lowest)
+		p.write(" ")
+		p.block(expression.Body)
+	case *ast.FunctionLiteral:
+		p.functionLiteral(expression)
+	}
+}
+
+func (p *printer) pattern(pattern ast.Pattern) {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		p.mark(pattern.Token)
+		p.write(pattern.Value)
+	case *ast.ArrayPattern:
+		p.mark(pattern.Token)
+		p.write("[")
+
+		for i, element := range pattern.Elements {
+			if i > 0 {
+				p.write(", ")
+			}
+
+			p.pattern(element)
+		}
+
+		if pattern.Rest != nil {
+			if len(pattern.Elements) > 0 {
+				p.write(", ")
+			}
+
+			p.mark(pattern.Rest.Token)
+			p.write("..." + pattern.Rest.Value)
+		}
+
+		p.write("]")
+	}
+}
+
+// maxWidth is how wide a line may get before an array literal or an argument list on it is broken up,
+// tabs count as four columns
+const maxWidth = 100
+
+// list prints the values of an array literal or the arguments of a call between the brackets. They go one
+// per line when the first one was on a line of its own, when there are comments among them or when the
+// line would get too wide, comments after a value stay behind it
+func (p *printer) list(open string, expressions []ast.Expression, start token.Token, end token.Token, close string) {
+	p.write(open)
+
+	if !p.isBroken(expressions, start, end) {
+		p.expressionList(expressions)
+		p.mark(end)
+		p.write(close)
+
+		return
+	}
+
+	p.newline()
+	p.indent += 1
+
+	for i, expression := range expressions {
+		p.flushComments(ast.StartToken(expression), i == 0)
+		p.expression(expression, lowest)
+
+		if i < len(expressions)-1 {
+			p.write(",")
+		}
+
+		p.newline()
+	}
+
+	p.flushComments(end, len(expressions) == 0)
+	p.indent -= 1
+
+	p.mark(end)
+	p.write(close)
+}
+
+func (p *printer) isBroken(expressions []ast.Expression, start token.Token, end token.Token) bool {
+	if p.isFlat {
+		return false
+	}
+
+	if len(expressions) > 0 && ast.StartToken(expressions[0]).Line > start.Line {
+		return true
+	}
+
+	for _, comment := range p.comments {
+		if isBefore(start, comment) && isBefore(comment, end) {
+			return true
+		}
+	}
+
+	flat := &printer{isFlat: true}
+	flat.expressionList(expressions)
+	text, _, _ := strings.Cut(flat.String(), "\n")
+
+	// The closing bracket follows the values
+	return p.width()+utf8.RuneCountInString(text)+1 > maxWidth
+}
+
+func (p *printer) expressionList(expressions []ast.Expression) {
+	for i, expression := range expressions {
+		if i > 0 {
+			p.write(", ")
+		}
+
+		p.expression(expression, lowest)
+	}
+}
+
+func (p *printer) functionLiteral(literal *ast.FunctionLiteral) {
+	p.mark(literal.Token)
+	p.write("fn ")
+
+	if literal.Name != nil {
+		p.mark(literal.Name.Token)
+		p.write(literal.Name.Value)
+	}
+
+	p.write("(")
+
+	for i, parameter := range literal.Parameters {
+		if i > 0 {
+			p.write(", ")
+		}
+
+		if parameter.IsRest {
+			p.write("...")
+		}
+
+		p.pattern(parameter.Target())
+
+		if parameter.Type != nil {
+			p.write(": " + parameter.Type.String())
+		}
+
+		if parameter.Default != nil {
+			p.write(" = ")
+			p.expression(parameter.Default, lowest)
+		}
+	}
+
+	p.write(") ")
+
+	if literal.ReturnType != nil {
+		p.write("-> " + literal.ReturnType.String() + " ")
+	}
+
+	p.block(literal.Body)
+}
+
+// spelling returns a string or a part of one the way it was written, a string that wasn't read from source
+// gets the escapes it needs
+func spelling(literal *ast.StringLiteral) string {
+	if literal.Token.Raw != "" || literal.Value == "" {
+		return literal.Token.Raw
+	}
+
+	return escape(literal.Value)
+}
+
+// escape escapes what the lexer would otherwise read as the end of the string or an embedded expression,
+// and the control characters other than tabs
+func escape(value string) string {
+	var out strings.Builder
+
+	for i, character := range value {
+		switch {
+		case character == '\\':
+			out.WriteString(`\\`)
+		case character == '"':
+			out.WriteString(`\"`)
+		case character == '\n':
+			out.WriteString(`\n`)
+		case character == '\r':
+			out.WriteString(`\r`)
+		case character == 0:
+			out.WriteString(`\0`)
+		case character == '$' && strings.HasPrefix(value[i+1:], "{"):
+			out.WriteString(`\$`)
+		case character < ' ' && character != '\t' || character == 0x7f:
+			out.WriteString(fmt.Sprintf(`\x%02x`, character))
+		default:
+			out.WriteRune(character)
+		}
+	}
+
+	return out.String()
+}
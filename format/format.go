@@ -0,0 +1,286 @@
+// Package format prints gopp programs in their canonical form
+package format
+
+import (
+	"errors"
+	"go++/ast"
+	lex "go++/lexer"
+	"go++/parser"
+	"go++/token"
+	"strings"
+	"unicode/utf8"
+)
+
+// Source formats a whole gopp file, comments are kept and at most one blank line is kept
+// between statements. Formatting its own output doesn't change it.
+func Source(src []byte) ([]byte, error) {
+	lexer := lex.New(string(src))
+	pars := parser.New(lexer)
+
+	program := pars.ParseProgram()
+
+	if len(pars.Errors()) > 0 {
+		return nil, errors.New(strings.Join(pars.Errors(), "\n"))
+	}
+
+	p := newPrinter(string(src), lexer.Comments())
+	p.program(program)
+
+	return []byte(p.String()), nil
+}
+
+// Node formats a single node without comments
+func Node(node ast.Node) string {
+	p := newPrinter("", nil)
+
+	switch node := node.(type) {
+	case *ast.Program:
+		p.program(node)
+	case ast.Statement:
+		p.statement(node)
+	case ast.Expression:
+		p.expression(node, lowest)
+	}
+
+	return strings.TrimSuffix(p.String(), "\n")
+}
+
+type printer struct {
+	lines  []string
+	line   strings.Builder
+	indent int
+
+	source   []string
+	comments []token.Token
+
+	// lastLine is the source line of the last token that was printed
+	lastLine int
+
+	// isFlat keeps every list on one line, it's set when measuring how wide a list is
+	isFlat bool
+}
+
+func newPrinter(source string, comments []token.Token) *printer {
+	p := &printer{comments: comments}
+
+	if source != "" {
+		p.source = strings.Split(source, "\n")
+	}
+
+	return p
+}
+
+func (p *printer) String() string {
+	p.flushLine()
+
+	if len(p.lines) == 0 {
+		return ""
+	}
+
+	return strings.Join(p.lines, "\n") + "\n"
+}
+
+func (p *printer) write(s string) {
+	if p.line.Len() == 0 {
+		p.line.WriteString(strings.Repeat("\t", p.indent))
+	}
+
+	p.line.WriteString(s)
+}
+
+// width is the number of columns the line being printed takes up so far
+func (p *printer) width() int {
+	line := p.line.String()
+
+	return utf8.RuneCountInString(line) + 3*strings.Count(line, "\t")
+}
+
+func (p *printer) newline() {
+	p.lines = append(p.lines, p.line.String())
+	p.line.Reset()
+}
+
+func (p *printer) flushLine() {
+	if p.line.Len() > 0 {
+		p.newline()
+	}
+}
+
+func (p *printer) blankLine() {
+	if len(p.lines) > 0 && p.lines[len(p.lines)-1] != "" {
+		p.lines = append(p.lines, "")
+	}
+}
+
+// mark records that a token from the source has been printed
+func (p *printer) mark(tok token.Token) {
+	if tok.Line > p.lastLine {
+		p.lastLine = tok.Line
+	}
+}
+
+// isBlankBefore reports whether the source has a blank line right above the line
+func (p *printer) isBlankBefore(line int) bool {
+	return line >= 2 && line-2 < len(p.source) && strings.TrimSpace(p.source[line-2]) == ""
+}
+
+func isBefore(a token.Token, b token.Token) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+}
+
+// flushComments prints the comments in front of the token, a comment on the same line as the
+// previous token stays at the end of that line
+func (p *printer) flushComments(before token.Token, isFirst bool) {
+	for len(p.comments) > 0 && (before.Line == 0 || isBefore(p.comments[0], before)) {
+		comment := p.comments[0]
+		p.comments = p.comments[1:]
+
+		if comment.Line == p.lastLine && len(p.lines) > 0 && p.line.Len() == 0 {
+			p.lines[len(p.lines)-1] += " " + comment.Literal
+			continue
+		}
+
+		if !isFirst && p.isBlankBefore(comment.Line) {
+			p.blankLine()
+		}
+
+		p.write(comment.Literal)
+		p.newline()
+		p.lastLine = comment.Line
+		isFirst = false
+	}
+}
+
+func (p *printer) program(program *ast.Program) {
+	p.statements(program.Statements, token.Token{})
+}
+
+// statements prints one statement per line followed by the comments in front of end,
+// every remaining comment is printed when end has no position
+func (p *printer) statements(statements []ast.Statement, end token.Token) {
+	isFirst := true
+	var previous ast.Statement
+
+	for _, statement := range statements {
+		start := ast.StartToken(statement)
+
+		p.flushComments(start, isFirst)
+
+		if !isFirst && p.isBlankBefore(start.Line) {
+			p.blankLine()
+		}
+
+		// Without the ; it would be read as a call, index or subtraction of the statement before it
+		if previous != nil && continuesPrevious(previous, statement) {
+			p.write(";")
+		}
+
+		p.statement(statement)
+		p.newline()
+
+		isFirst = false
+		previous = statement
+	}
+
+	p.flushComments(end, isFirst)
+}
+
+func (p *printer) statement(statement ast.Statement) {
+	switch statement := statement.(type) {
+	case *ast.LetStatement:
+		p.mark(statement.Token)
+		p.write("let ")
+
+		if statement.IsMutable {
+			p.write("mut ")
+		}
+
+		p.pattern(statement.Target())
+
+		if statement.Type != nil {
+			p.write(": " + statement.Type.String())
+		}
+
+		p.write(" = ")
+		p.expression(statement.Value, lowest)
+	case *ast.FunctionStatement:
+		p.functionLiteral(statement.Function)
+	case *ast.ReturnStatement:
+		p.mark(statement.Token)
+		p.write("return ")
+		p.expression(statement.ReturnValue, lowest)
+	case *ast.ExpressionStatement:
+		p.expression(statement.Expression, lowest)
+	case *ast.BlockStatement:
+		p.block(statement)
+	}
+}
+
+func (p *printer) block(block *ast.BlockStatement) {
+	p.mark(block.Token)
+
+	if p.isInline(block) && (len(block.Statements) == 0 || p.fitsInline(block.Statements[0])) {
+		if len(block.Statements) == 0 {
+			p.write("{}")
+		} else {
+			p.write("{ ")
+			p.statement(block.Statements[0])
+			p.write(" }")
+		}
+
+		p.mark(block.EndToken)
+
+		return
+	}
+
+	p.write("{")
+	p.newline()
+
+	p.indent += 1
+	p.statements(block.Statements, block.EndToken)
+	p.indent -= 1
+
+	p.mark(block.EndToken)
+	p.write("}")
+}
+
+// fitsInline reports whether the statement of an inline block fits on the line without breaking it up
+func (p *printer) fitsInline(statement ast.Statement) bool {
+	if p.isFlat {
+		return true
+	}
+
+	probe := &printer{indent: p.indent}
+	probe.line.WriteString(p.line.String())
+	probe.write("{ ")
+	probe.statement(statement)
+
+	// The closing " }" follows the statement
+	return len(probe.lines) == 0 && probe.width()+2 <= maxWidth
+}
+
+// isInline reports whether a block stays on a single line, which it does when it was written on
+// one line with at most one statement and neither it or any block inside it contains more
+func (p *printer) isInline(block *ast.BlockStatement) bool {
+	if block.Token.Line != block.EndToken.Line {
+		return false
+	}
+
+	for _, comment := range p.comments {
+		if isBefore(block.Token, comment) && isBefore(comment, block.EndToken) {
+			return false
+		}
+	}
+
+	isInline := true
+
+	ast.Inspect(block, func(node ast.Node) bool {
+		if nested, ok := node.(*ast.BlockStatement); ok && len(nested.Statements) > 1 {
+			isInline = false
+		}
+
+		return isInline
+	})
+
+	return isInline
+}
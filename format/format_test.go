@@ -0,0 +1,153 @@
+package format
+
+import (
+	"go++/ast"
+	lex "go++/lexer"
+	"go++/parser"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let   x=5;", "let x = 5\n"},
+		{"let mut x:int=5", "let mut x: int = 5\n"},
+		{"(1 + 2) * 3 - (4 - 5)", "(1 + 2) * 3 - (4 - 5)\n"},
+		{"((1 * 2)) + 3", "1 * 2 + 3\n"},
+		{"-(x + 1) * !y", "-(x + 1) * !y\n"},
+		{"a == (b == c)", "a == (b == c)\n"},
+		{"(-f)(x)", "(-f)(x)\n"},
+		{"a.b(1,2)[0].c", "a.b(1, 2)[0].c\n"},
+		{`"say \"hi\"\n"`, "\"say \\\"hi\\\"\\n\"\n"},
+		{"x = y = 1", "x = y = 1\n"},
+		{"a;(-f)(x);[1, 2].length();-b;!c=0", "a\n;(-f)(x)\n;[1, 2].length()\n;-b\n;(!c) = 0\n"},
+		{"for v in [1] { println(v) }\n[1, 2].length()", "for v in [1] { println(v) }\n[1, 2].length()\n"},
+		{"let mut i = 0\nfor i < 1 { i = i + 1 }\n-i", "let mut i = 0\nfor i < 1 { i = i + 1 }\n-i\n"},
+		{"fn f() { 1 }\n(-1)", "fn f() { 1 }\n-1\n"},
+		{"let x = 1;(-1)\nreturn x;[1]", "let x = 1\n;-1\nreturn x\n;[1]\n"},
+		{"let n=null a?.b?.[ 0 ].c(1)", "let n = null\na?.b?.[0].c(1)\n"},
+		{"(a??b)??(c??d==e)", "a ?? b ?? (c ?? d == e)\n"},
+		{"(a ?? b) == c", "(a ?? b) == c\n"},
+		{"0xFF+1_000*1.5e3-0b1", "0xFF + 1_000 * 1.5e3 - 0b1\n"},
+		{"`raw\n\\d+`", "`raw\n\\d+`\n"},
+		{`"\t\r\0\x01\u{e9}"`, "\"\\t\\r\\0\\x01\\u{e9}\"\n"},
+		{`"\x41 ${ a } \u{1F600}\t${"\${b}"}\\"`, "\"\\x41 ${a} \\u{1F600}\\t${\"\\${b}\"}\\\\\"\n"},
+		{"\"tab\there\"", "\"tab\there\"\n"},
+		{`"a ${ x+1 } \${b} ${"c ${d}"}"`, "\"a ${x + 1} \\${b} ${\"c ${d}\"}\"\n"},
+		{"let f = fn(a:int,b)->bool{a}", "let f = fn (a: int, b) -> bool { a }\n"},
+		{"fn(x) { x; }(5)", "fn (x) { x }(5)\n"},
+		{"let f = fn(x,y=1+2,...rest){x}", "let f = fn (x, y = 1 + 2, ...rest) { x }\n"},
+		{"f( ...xs,y:(1+2)*3 )", "f(...xs, y: (1 + 2) * 3)\n"},
+		{"fn  add(a:int,b)->int{a+b}", "fn add(a: int, b) -> int { a + b }\n"},
+		{"fn outer() { fn inner() { 1 } inner() }", "fn outer() {\n\tfn inner() { 1 }\n\tinner()\n}\n"},
+		{"if x {} else { y }", "if x {} else { y }\n"},
+		{"if x { a b }", "if x {\n\ta\n\tb\n}\n"},
+		{"let f = fn () { if x { a b } }", "let f = fn () {\n\tif x {\n\t\ta\n\t\tb\n\t}\n}\n"},
+		{"for x > 0 {\nx = x - 1 }", "for x > 0 {\n\tx = x - 1\n}\n"},
+		{"let mut [a,[b],...c]=xs", "let mut [a, [b], ...c] = xs\n"},
+		{"let [ ...all ] = xs", "let [...all] = xs\n"},
+		{"fn f([x,y]:[int],...rest){x}", "fn f([x, y]: [int], ...rest) { x }\n"},
+		{"for [k,v] in pairs {\nk }", "for [k, v] in pairs {\n\tk\n}\n"},
+		{"a\n\n\n\nb\nc", "a\n\nb\nc\n"},
+		{"// leading\na // trailing\n\n// own line\nb\n// end", "// leading\na // trailing\n\n// own line\nb\n// end\n"},
+		{"let f = fn () {\n  // inside\n  1 // one\n  // last\n}", "let f = fn () {\n\t// inside\n\t1 // one\n\t// last\n}\n"},
+		{"let f = fn () { 1 // one\n}", "let f = fn () {\n\t1 // one\n}\n"},
+		{"[\n 1, // one\n 2 // two\n]", "[\n\t1, // one\n\t2 // two\n]\n"},
+		{"add(1, // a\n 2)", "add(\n\t1, // a\n\t2\n)\n"},
+		{"f(\n// first\n1,2\n// after\n)", "f(\n\t// first\n\t1,\n\t2\n\t// after\n)\n"},
+		{"let e = [ // none\n]", "let e = [ // none\n]\n"},
+		{"add(1,\n 2) xs.map(fn (v) {\n v\n}, 2)", "add(1, 2)\nxs.map(fn (v) {\n\tv\n}, 2)\n"},
+		{
+			"let f = fn () { println(\"a long string that goes on and on and on and on\", \"another long string\", [1, 2, 3], call(with, args)) }",
+			"let f = fn () {\n\tprintln(\n\t\t\"a long string that goes on and on and on and on\",\n\t\t\"another long string\",\n\t\t[1, 2, 3],\n\t\tcall(with, args)\n\t)\n}\n",
+		},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		formatted, err := Source([]byte(tt.input))
+
+		if err != nil {
+			t.Errorf("unexpected error for %q: %s", tt.input, err)
+			continue
+		}
+
+		if string(formatted) != tt.expected {
+			t.Errorf("wrong formatting of %q.\nwant=%q\ngot= %q", tt.input, tt.expected, formatted)
+		}
+
+		testIdempotent(t, formatted)
+	}
+}
+
+func TestSourceParseError(t *testing.T) {
+	if _, err := Source([]byte("let = 5")); err == nil {
+		t.Errorf("expected an error for invalid input")
+	}
+}
+
+func TestSourceTestPrograms(t *testing.T) {
+	files, err := filepath.Glob("../testPrograms/*.gopp")
+
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no test programs found: %v", err)
+	}
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+
+		if err != nil {
+			t.Fatalf("could not read %s: %s", file, err)
+		}
+
+		formatted, err := Source(src)
+
+		if err != nil {
+			t.Fatalf("could not format %s: %s", file, err)
+		}
+
+		testIdempotent(t, formatted)
+
+		// Formatting must not change what the program means
+		original := parser.New(lex.New(string(src))).ParseProgram()
+		reformatted := parser.New(lex.New(string(formatted))).ParseProgram()
+
+		if original.String() != reformatted.String() {
+			t.Errorf("formatting changed %s.\nbefore=%s\nafter=%s", file, original.String(), reformatted.String())
+		}
+	}
+}
+
+func TestNode(t *testing.T) {
+	program := parser.New(lex.New("let x = (1 + 2) * y")).ParseProgram()
+
+	if formatted := Node(program); formatted != "let x = (1 + 2) * y" {
+		t.Errorf("wrong formatting. got=%q", formatted)
+	}
+
+	// A string that wasn't read from source has no spelling to keep
+	literal := &ast.StringLiteral{Value: "say \"hi\"\n"}
+
+	if formatted := Node(literal); formatted != `"say \"hi\"\n"` {
+		t.Errorf("wrong formatting of a string without source. got=%q", formatted)
+	}
+}
+
+func testIdempotent(t *testing.T, formatted []byte) {
+	t.Helper()
+
+	again, err := Source(formatted)
+
+	if err != nil {
+		t.Errorf("could not format own output %q: %s", formatted, err)
+		return
+	}
+
+	if string(again) != string(formatted) {
+		t.Errorf("formatting is not idempotent.\nfirst= %q\nsecond=%q", formatted, again)
+	}
+}
@@ -0,0 +1,94 @@
+package format
+
+import (
+	"fmt"
+	lex "go++/lexer"
+	"go++/parser"
+	"go++/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Inputs of the formatting tests, they seed the fuzzer with the programs in testPrograms
+var seeds = []string{
+	"let mut x:int=5",
+	"((1 * 2)) + 3 - (4 - 5)",
+	"-(x + 1) * !y == (b == c)",
+	"(-f)(x) a.b(1,2)[0].c",
+	`"say \"hi\"\n"`,
+	"x = y = 1",
+	`"a ${ x+1 } \${b} ${"c ${d}"}"`,
+	"`raw\n\\d` \"\\t\\r\\0\\x01\\u{e9}\"",
+	"let f = fn(a:int,b)->bool{a}",
+	"fn(x) { x; }(5)",
+	"if x {} else { y }",
+	"let f = fn () { if x { a b } }",
+	"for x > 0 {\nx = x - 1 }",
+	"a\n\n\n\nb\nc",
+	"// leading\na // trailing\n\n// own line\nb\n// end",
+	"let f = fn () {\n  // inside\n  1 // one\n  // last\n}",
+	"[\n 1, // one\n 2 // two\n] add(1, // a\n 2)",
+	"f(\n// first\n1,2\n// after\n)",
+}
+
+// FuzzSource checks that formatting keeps what a program means and that formatting its output changes nothing
+func FuzzSource(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	files, _ := filepath.Glob("../testPrograms/*.gopp")
+
+	for _, file := range files {
+		if src, err := os.ReadFile(file); err == nil {
+			f.Add(string(src))
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		formatted, err := Source([]byte(input))
+
+		if err != nil {
+			return
+		}
+
+		reparser := parser.New(lex.New(string(formatted)))
+		reparsed := reparser.ParseProgram()
+
+		if len(reparser.Errors()) > 0 {
+			t.Fatalf("the formatted program doesn't parse: %v\ninput=%q\nformatted=%q", reparser.Errors(), input, formatted)
+		}
+
+		original := parser.New(lex.New(input)).ParseProgram()
+
+		if original.String() != reparsed.String() {
+			t.Fatalf("formatting changed the program.\ninput=%q\nformatted=%q", input, formatted)
+		}
+
+		// The trees can match while the parser skipped a token it should have rejected, like the ) of a[0)
+		if fmt.Sprint(significantTokens(input)) != fmt.Sprint(significantTokens(string(formatted))) {
+			t.Fatalf("formatting changed the tokens of the program.\ninput=%q\nformatted=%q", input, formatted)
+		}
+
+		again, err := Source(formatted)
+
+		if err != nil || string(again) != string(formatted) {
+			t.Fatalf("formatting is not idempotent.\nfirst= %q\nsecond=%q", formatted, again)
+		}
+	})
+}
+
+// significantTokens lexes a program without the parentheses and semicolons that formatting may add or drop
+func significantTokens(input string) []token.Token {
+	lexer := lex.New(input)
+	tokens := []token.Token{}
+
+	for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
+		if tok.Type != token.LPAREN && tok.Type != token.RPAREN && tok.Type != token.SEMICOLON {
+			tokens = append(tokens, token.Token{Type: tok.Type, Literal: tok.Literal})
+		}
+	}
+
+	return tokens
+}
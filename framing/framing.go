@@ -0,0 +1,46 @@
+// Package framing reads and writes messages framed by a Content-Length header, the transport
+// shared by the language server and the debug adapter
+package framing
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+)
+
+// Read reads the headers of the next message and returns its body
+func Read(in *bufio.Reader) ([]byte, error) {
+	header, err := textproto.NewReader(in).ReadMIMEHeader()
+
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+
+	body := make([]byte, length)
+
+	if _, err := io.ReadFull(in, body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// Write sends body preceded by its Content-Length header, callers writing from several
+// goroutines have to hold a lock around it
+func Write(out io.Writer, body []byte) error {
+	if _, err := fmt.Fprintf(out, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+
+	_, err := out.Write(body)
+
+	return err
+}
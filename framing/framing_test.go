@@ -0,0 +1,56 @@
+package framing
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+
+	for _, body := range []string{`{"a":1}`, "", "line\r\nbreak"} {
+		if err := Write(&buf, []byte(body)); err != nil {
+			t.Fatalf("Write(%q) failed: %v", body, err)
+		}
+	}
+
+	in := bufio.NewReader(&buf)
+
+	for _, expected := range []string{`{"a":1}`, "", "line\r\nbreak"} {
+		body, err := Read(in)
+
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+
+		if string(body) != expected {
+			t.Errorf("wrong body. expected=%q, got=%q", expected, body)
+		}
+	}
+
+	if _, err := Read(in); err != io.EOF {
+		t.Errorf("expected io.EOF at the end, got=%v", err)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Content-Length: abc\r\n\r\n", `invalid Content-Length "abc"`},
+		{"Content-Type: text/plain\r\n\r\n", `invalid Content-Length ""`},
+		{"Content-Length: 10\r\n\r\nshort", "unexpected EOF"},
+	}
+
+	for _, tt := range tests {
+		_, err := Read(bufio.NewReader(strings.NewReader(tt.input)))
+
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("wrong error for %q. expected=%q, got=%v", tt.input, tt.expected, err)
+		}
+	}
+}
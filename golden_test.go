@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"go++/evaluator"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testPrograms with the current output")
+
+// TestPrograms runs every program in testPrograms and compares what it prints with the .out file next to
+// it and the error it fails with with the .err file, a missing golden file is expected to be empty
+func TestPrograms(t *testing.T) {
+	programs := []string{}
+
+	err := filepath.WalkDir("testPrograms", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Test files are run by gopp test instead
+		if !entry.IsDir() && strings.HasSuffix(path, ".gopp") && !strings.HasSuffix(path, "_test.gopp") {
+			programs = append(programs, path)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(programs) == 0 {
+		t.Fatal("no programs found in testPrograms")
+	}
+
+	for _, program := range programs {
+		t.Run(program, func(t *testing.T) {
+			stdout, stderr := runProgram(program)
+			base := strings.TrimSuffix(program, ".gopp")
+
+			compareGolden(t, base+".out", stdout)
+			compareGolden(t, base+".err", stderr)
+		})
+	}
+}
+
+// runProgram runs a file like gopp does and returns what it prints to stdout and stderr
+func runProgram(file string) (string, string) {
+	var stdout bytes.Buffer
+
+	evaluator.Output = &stdout
+	defer func() { evaluator.Output = os.Stdout }()
+
+	if _, err := runFromFile(file); err != nil {
+		return stdout.String(), err.Error() + "\n"
+	}
+
+	return stdout.String(), ""
+}
+
+func compareGolden(t *testing.T, path string, got string) {
+	if *update {
+		updateGolden(t, path, got)
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal(err)
+	}
+
+	if got != string(expected) {
+		t.Errorf("output differs from %s, run go test -update to accept it\nwant:\n%s\ngot:\n%s", path, expected, got)
+	}
+}
+
+// updateGolden writes the golden file, an empty one is removed instead
+func updateGolden(t *testing.T, path string, content string) {
+	if content == "" {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			t.Fatal(err)
+		}
+
+		return
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
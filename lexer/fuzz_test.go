@@ -0,0 +1,52 @@
+package lexer
+
+import (
+	"go++/token"
+	"testing"
+)
+
+// The inputs of the lexer tests, they seed the fuzzer
+var seeds = []string{
+	"let five = 5;\nlet mut ten = 10;\nlet add = fn(x, y) {\n\tx + y;\n};\n!-/*5;\n5 < 10 > 5;\n10 == 10;\n10 != 9;",
+	"if (5 < 10) {\n\treturn true;\n} else {\n\treturn false;\n}",
+	`"foobar" "foo bar" for true { "hello".ident() } ["hey", 1, 3]`,
+	"let x = 5;\n  x = \"hi\"\n",
+	"fn (a: int) -> [int] { a - 1 }",
+	"// first\nlet x = 10 / 2 // second\nx",
+	`"a \"quoted\" \\ string\n"`,
+	`"unterminated`,
+	`"ends with a backslash\`,
+	`"a ${f({}) + "${x}"} b ${y}" }`,
+	"let größe1 = x_2 + π @ \xff \"€\"",
+	"`raw\r\n${x}` \"\\t\\u{1F600}\\x41\\q\" \"\xff\"",
+	"0xFF 0o17 0b1010 1_000_000 1.5e3 2E-3 0x1G 1__0 1e+ 017 0xbe+1 2.add(3)",
+	"a?.b?.[0] ?? null ? x?",
+}
+
+func FuzzNextToken(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		lexer := New(input)
+		line, column := 1, 0
+
+		// Every token but EOF takes at least one byte of the input, so there can't be more of them than bytes
+		for i := 0; i <= len(input); i++ {
+			tok := lexer.NextToken()
+
+			if tok.Line < line || tok.Line == line && tok.Column <= column && tok.Type != token.EOF {
+				t.Fatalf("token %q at %d:%d is not after the one at %d:%d", tok.Literal, tok.Line, tok.Column, line, column)
+			}
+
+			if tok.Type == token.EOF {
+				return
+			}
+
+			line, column = tok.Line, tok.Column
+		}
+
+		t.Fatalf("the lexer didn't reach EOF after %d tokens", len(input)+1)
+	})
+}
@@ -2,32 +2,82 @@ package lexer
 
 import (
 	"bytes"
+	"fmt"
 	"go++/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// Lexer reads the UTF-8 encoded input a character at a time, positions in the input are byte offsets and
+// columns count characters
 type Lexer struct {
 	input        string
 	position     int
 	readPosition int
-	currentChar  byte
+	currentChar  rune
+
+	line   int
+	column int
+
+	comments []token.Token
+	errors   []Error
+
+	// interpolations holds how many braces are open in every embedded expression being lexed, the }
+	// that closes the expression continues its string
+	interpolations []int
+}
+
+// Error is a malformed token, the lexer still returns a token for it so parsing can go on
+type Error struct {
+	Token   token.Token
+	Message string
+}
+
+func (e Error) String() string {
+	return fmt.Sprintf("%d:%d: %s", e.Token.Line, e.Token.Column, e.Message)
 }
 
 func New(input string) *Lexer {
-	lexer := &Lexer{input: input}
+	lexer := &Lexer{input: input, line: 1}
 	lexer.readCharacter()
 
 	return lexer
 }
 
 func (lexer *Lexer) readCharacter() {
+	if lexer.currentChar == '\n' {
+		lexer.line += 1
+		lexer.column = 0
+	}
+
+	lexer.column += 1
+	lexer.position = lexer.readPosition
+
 	if lexer.readPosition >= len(lexer.input) {
 		lexer.currentChar = 0
-	} else {
-		lexer.currentChar = lexer.input[lexer.readPosition]
+		lexer.readPosition += 1
+
+		return
 	}
 
-	lexer.position = lexer.readPosition
-	lexer.readPosition += 1
+	character, width := utf8.DecodeRuneInString(lexer.input[lexer.readPosition:])
+
+	lexer.currentChar = character
+	lexer.readPosition += width
+}
+
+// isAtEnd reports whether the whole input has been read, the current character is 0 then but a 0 in the input
+// is an illegal character rather than the end
+func (lexer *Lexer) isAtEnd() bool {
+	return lexer.position >= len(lexer.input)
+}
+
+// isInvalidCharacter reports whether the current character is a byte that isn't valid UTF-8, rather than
+// an encoded U+FFFD
+func (lexer *Lexer) isInvalidCharacter() bool {
+	return lexer.currentChar == utf8.RuneError && lexer.readPosition-lexer.position == 1
 }
 
 func (lexer *Lexer) NextToken() token.Token {
@@ -35,6 +85,12 @@ func (lexer *Lexer) NextToken() token.Token {
 
 	lexer.skipWhitespace()
 
+	line, column := lexer.line, lexer.column
+
+	if lexer.isAtEnd() {
+		return token.Token{Type: token.EOF, Line: line, Column: column}
+	}
+
 	switch lexer.currentChar {
 	case '=':
 		if lexer.peekChar() == '=' {
@@ -49,7 +105,15 @@ func (lexer *Lexer) NextToken() token.Token {
 	case '+':
 		tok = newToken(token.PLUS, lexer.currentChar)
 	case '-':
-		tok = newToken(token.MINUS, lexer.currentChar)
+		if lexer.peekChar() == '>' {
+			character := lexer.currentChar
+			lexer.readCharacter()
+
+			tok.Literal = string(character) + string(lexer.currentChar)
+			tok.Type = token.ARROW
+		} else {
+			tok = newToken(token.MINUS, lexer.currentChar)
+		}
 	case '*':
 		tok = newToken(token.ASTERISK, lexer.currentChar)
 	case '/':
@@ -70,124 +134,454 @@ func (lexer *Lexer) NextToken() token.Token {
 		}
 	case ';':
 		tok = newToken(token.SEMICOLON, lexer.currentChar)
+	case ':':
+		tok = newToken(token.COLON, lexer.currentChar)
 	case ',':
 		tok = newToken(token.COMMA, lexer.currentChar)
 	case '.':
-		tok = newToken(token.DOT, lexer.currentChar)
+		if lexer.peekChar() == '.' && lexer.readPosition+1 < len(lexer.input) && lexer.input[lexer.readPosition+1] == '.' {
+			lexer.readCharacter()
+			lexer.readCharacter()
+
+			tok.Literal = "..."
+			tok.Type = token.ELLIPSIS
+		} else {
+			tok = newToken(token.DOT, lexer.currentChar)
+		}
+	case '?':
+		switch lexer.peekChar() {
+		case '.':
+			lexer.readCharacter()
+
+			tok.Literal = "?."
+			tok.Type = token.OPTIONALCHAIN
+		case '?':
+			lexer.readCharacter()
+
+			tok.Literal = "??"
+			tok.Type = token.COALESCE
+		default:
+			tok = newToken(token.ILLEGAL, lexer.currentChar)
+			tok.Line, tok.Column = line, column
+			lexer.appendError(tok, "illegal character %#U, ? is only used in ?. and ??", lexer.currentChar)
+		}
 	case '(':
 		tok = newToken(token.LPAREN, lexer.currentChar)
 	case ')':
 		tok = newToken(token.RPAREN, lexer.currentChar)
 	case '{':
+		if n := len(lexer.interpolations); n > 0 {
+			lexer.interpolations[n-1] += 1
+		}
+
 		tok = newToken(token.LBRACE, lexer.currentChar)
 	case '}':
-		tok = newToken(token.RBRACE, lexer.currentChar)
+		if n := len(lexer.interpolations); n > 0 && lexer.interpolations[n-1] == 0 {
+			lexer.interpolations = lexer.interpolations[:n-1]
+			tok = lexer.readStringToken(true)
+		} else {
+			if n > 0 {
+				lexer.interpolations[n-1] -= 1
+			}
+
+			tok = newToken(token.RBRACE, lexer.currentChar)
+		}
 	case '[':
 		tok = newToken(token.LBRACKET, lexer.currentChar)
 	case ']':
 		tok = newToken(token.RBRACKET, lexer.currentChar)
 	case '"':
-		tok.Type = token.STRING
-		tok.Literal = lexer.readString()
-
-	case 0:
-		tok = newToken(token.EOF, lexer.currentChar)
-		tok.Literal = ""
+		tok = lexer.readStringToken(false)
+	case '`':
+		tok.Type = token.RAWSTRING
+		tok.Literal = lexer.readRawString()
 	default:
 		if isLetter(lexer.currentChar) {
 			tok.Literal = lexer.readIdentifier()
 			tok.Type = token.LookupIdentifier(tok.Literal)
+			tok.Line, tok.Column = line, column
 
 			return tok
 		} else if IsDigit(lexer.currentChar) {
 			tok.Literal = lexer.readNumber()
 			tok.Type = token.INTEGER
+			tok.Line, tok.Column = line, column
+
+			if message := checkNumber(tok.Literal); message != "" {
+				tok.Type = token.ILLEGAL
+				lexer.appendError(tok, message)
+			}
 
 			return tok
+		} else if lexer.isInvalidCharacter() {
+			tok = token.Token{Type: token.ILLEGAL, Literal: lexer.input[lexer.position:lexer.readPosition], Line: line, Column: column}
+			lexer.appendError(tok, "invalid UTF-8 encoding")
 		} else {
 			tok = newToken(token.ILLEGAL, lexer.currentChar)
+			tok.Line, tok.Column = line, column
+			lexer.appendError(tok, "illegal character %#U", lexer.currentChar)
 		}
 	}
 
+	tok.Line, tok.Column = line, column
+
 	lexer.readCharacter()
 	return tok
 }
 
-func newToken(tokenType token.Type, character byte) token.Token {
+func newToken(tokenType token.Type, character rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(character)}
 }
 
-func (lexer *Lexer) peekChar() byte {
+func (lexer *Lexer) peekChar() rune {
 	if lexer.readPosition >= len(lexer.input) {
 		return 0
 	}
 
-	return lexer.input[lexer.readPosition]
+	character, _ := utf8.DecodeRuneInString(lexer.input[lexer.readPosition:])
+
+	return character
 }
 
+// readIdentifier reads a letter followed by letters and digits, Unicode ones included like in Go
 func (lexer *Lexer) readIdentifier() string {
 	position := lexer.position
-	for isLetter(lexer.currentChar) {
+	for isLetter(lexer.currentChar) || unicode.IsDigit(lexer.currentChar) {
 		lexer.readCharacter()
 	}
 
 	return lexer.input[position:lexer.position]
 }
 
-func (lexer *Lexer) readString() string {
-	//position := lexer.position + 1
+// readStringToken reads a string, or the part of one up to the next embedded expression. A part that
+// follows an embedded expression is read from its closing }
+func (lexer *Lexer) readStringToken(isContinued bool) token.Token {
+	start := token.Token{Type: token.ILLEGAL, Literal: string(lexer.currentChar), Line: lexer.line, Column: lexer.column}
+	position := lexer.readPosition
+
+	literal, end := lexer.readString()
+	tok := token.Token{Type: token.STRING, Literal: literal, Raw: lexer.input[position:min(lexer.position, len(lexer.input))]}
+
+	if isContinued {
+		tok.Type = token.INTERPOLATIONEND
+	}
+
+	switch end {
+	case '$':
+		// The { of the ${ is part of the token, the tokens of the expression follow it
+		lexer.readCharacter()
+		lexer.interpolations = append(lexer.interpolations, 0)
+
+		tok.Type = token.INTERPOLATION
+	case 0:
+		lexer.appendError(start, "string literal not terminated")
+	}
+
+	return tok
+}
+
+// readString reads a string up to its closing quote or the ${ of an embedded expression, it returns the
+// character it stopped at, 0 when the input ended before either
+func (lexer *Lexer) readString() (string, rune) {
 	var out bytes.Buffer
 
 	for {
 		lexer.readCharacter()
 
-		if lexer.currentChar == '\\' {
-			switch lexer.peekChar() {
-			case 'n':
-				out.WriteByte('\n')
-				lexer.readCharacter() // Skip the 'n'
-			case '\\':
-				out.WriteByte('\\')
-				lexer.readCharacter() // Skip the second '\'
-			case '"':
-				out.WriteByte('"')
-				lexer.readCharacter() // Skip the second '"'
-			default:
-				out.WriteByte(lexer.currentChar)
-			}
-			continue
+		switch {
+		case lexer.isAtEnd():
+			return out.String(), 0
+		case lexer.currentChar == '"':
+			return out.String(), '"'
+		case lexer.currentChar == '$' && lexer.peekChar() == '{':
+			return out.String(), '$'
+		case lexer.currentChar == '\\':
+			lexer.readEscape(&out)
+		default:
+			lexer.writeCharacter(&out)
 		}
+	}
+}
+
+// readEscape writes the character escaped by the backslash at the current position, it stops at the last
+// character of the escape sequence
+func (lexer *Lexer) readEscape(out *bytes.Buffer) {
+	start := token.Token{Type: token.ILLEGAL, Line: lexer.line, Column: lexer.column}
+	position := lexer.position
 
-		if lexer.currentChar == '"' || lexer.currentChar == '0' {
-			break
+	// The string is unterminated, which readString reports
+	if lexer.readPosition >= len(lexer.input) {
+		return
+	}
+
+	lexer.readCharacter()
+
+	switch lexer.currentChar {
+	case 'n':
+		out.WriteByte('\n')
+	case 't':
+		out.WriteByte('\t')
+	case 'r':
+		out.WriteByte('\r')
+	case '0':
+		out.WriteByte(0)
+	case '\\', '"', '$':
+		out.WriteRune(lexer.currentChar)
+	case 'x':
+		value, ok := lexer.readHexDigits(2, 2)
+		start.Literal = lexer.input[position:lexer.readPosition]
+
+		switch {
+		case !ok:
+			lexer.appendError(start, "invalid escape sequence %s, \\x takes two hexadecimal digits", start.Literal)
+		case value >= utf8.RuneSelf:
+			// Strings are always valid UTF-8, a single byte above 7f would break that
+			lexer.appendError(start, "invalid escape sequence %s, use \\u{%x} for characters that aren't ASCII", start.Literal, value)
+		default:
+			out.WriteByte(byte(value))
 		}
+	case 'u':
+		value, ok := 0, lexer.peekChar() == '{'
 
-		out.WriteByte(lexer.currentChar)
+		if ok {
+			lexer.readCharacter()
+			value, ok = lexer.readHexDigits(1, 6)
+		}
+
+		if ok = ok && lexer.peekChar() == '}'; ok {
+			lexer.readCharacter()
+		}
+
+		start.Literal = lexer.input[position:lexer.readPosition]
+
+		switch {
+		case !ok:
+			lexer.appendError(start, "invalid escape sequence %s, \\u takes hexadecimal digits in braces like \\u{1F600}", start.Literal)
+		case !utf8.ValidRune(rune(value)):
+			lexer.appendError(start, "invalid escape sequence %s, %x is not a Unicode character", start.Literal, value)
+		default:
+			out.WriteRune(rune(value))
+		}
+	default:
+		start.Literal = lexer.input[position:lexer.readPosition]
+
+		lexer.appendError(start, "unknown escape sequence %s", start.Literal)
 	}
+}
 
-	return out.String()
+// readHexDigits reads at least min and at most max hexadecimal digits following the current character
+func (lexer *Lexer) readHexDigits(min int, max int) (int, bool) {
+	position := lexer.readPosition
+
+	for lexer.readPosition-position < max && isHexDigit(lexer.peekChar()) {
+		lexer.readCharacter()
+	}
+
+	if lexer.readPosition-position < min {
+		return 0, false
+	}
+
+	value, err := strconv.ParseInt(lexer.input[position:lexer.readPosition], 16, 32)
+
+	return int(value), err == nil
+}
+
+// writeCharacter copies the current character of a string, bytes that aren't valid UTF-8 and NULs are left out
+func (lexer *Lexer) writeCharacter(out *bytes.Buffer) {
+	if lexer.reportNul() {
+		return
+	}
+
+	if lexer.isInvalidCharacter() {
+		lexer.appendError(token.Token{Type: token.ILLEGAL, Literal: lexer.input[lexer.position:lexer.readPosition], Line: lexer.line, Column: lexer.column},
+			"invalid UTF-8 encoding")
+		return
+	}
+
+	out.WriteRune(lexer.currentChar)
+}
+
+// reportNul reports a NUL in a string or a comment, it's invisible in most editors so it isn't allowed anywhere
+func (lexer *Lexer) reportNul() bool {
+	if lexer.currentChar != 0 {
+		return false
+	}
+
+	lexer.appendError(token.Token{Type: token.ILLEGAL, Literal: "\x00", Line: lexer.line, Column: lexer.column}, "illegal character %#U", rune(0))
+
+	return true
+}
+
+// readRawString reads a string between backticks, it has no escapes and can span lines. Carriage returns
+// are dropped so a file has the same strings whatever its line endings
+func (lexer *Lexer) readRawString() string {
+	start := token.Token{Type: token.ILLEGAL, Literal: "`", Line: lexer.line, Column: lexer.column}
+
+	var out bytes.Buffer
+
+	for {
+		lexer.readCharacter()
+
+		switch {
+		case lexer.currentChar == '`':
+			return out.String()
+		case lexer.isAtEnd():
+			lexer.appendError(start, "raw string literal not terminated")
+			return out.String()
+		case lexer.currentChar == '\r':
+		default:
+			lexer.writeCharacter(&out)
+		}
+	}
+}
+
+func (lexer *Lexer) appendError(tok token.Token, format string, a ...interface{}) {
+	lexer.errors = append(lexer.errors, Error{Token: tok, Message: fmt.Sprintf(format, a...)})
+}
+
+// Errors returns the malformed tokens read so far
+func (lexer *Lexer) Errors() []Error {
+	return lexer.errors
 }
 
-func isLetter(character byte) bool {
-	return 'a' <= character && character <= 'z' || 'A' <= character && character <= 'Z' || character == '_'
+func isHexDigit(character rune) bool {
+	return IsDigit(character) || 'a' <= character && character <= 'f' || 'A' <= character && character <= 'F'
+}
+
+func isLetter(character rune) bool {
+	return unicode.IsLetter(character) || character == '_'
+}
+
+// Comments returns the comments skipped so far, they aren't part of the token stream
+func (lexer *Lexer) Comments() []token.Token {
+	return lexer.comments
 }
 
 func (lexer *Lexer) skipWhitespace() {
-	for lexer.currentChar == ' ' || lexer.currentChar == '\t' || lexer.currentChar == '\r' || lexer.currentChar == '\n' {
+	for {
+		switch {
+		case lexer.currentChar == ' ' || lexer.currentChar == '\t' || lexer.currentChar == '\r' || lexer.currentChar == '\n':
+			lexer.readCharacter()
+		case lexer.currentChar == '/' && lexer.peekChar() == '/':
+			lexer.readComment()
+		default:
+			return
+		}
+	}
+}
+
+func (lexer *Lexer) readComment() {
+	comment := token.Token{Type: token.COMMENT, Line: lexer.line, Column: lexer.column}
+	position := lexer.position
+
+	for lexer.currentChar != '\n' && !lexer.isAtEnd() {
+		lexer.reportNul()
 		lexer.readCharacter()
 	}
+
+	comment.Literal = lexer.input[position:lexer.position]
+	lexer.comments = append(lexer.comments, comment)
 }
 
+// readNumber reads everything that could belong to a number, letters included, so a malformed one like 0x1G
+// is reported as a whole instead of being split into a number and an identifier
 func (lexer *Lexer) readNumber() string {
 	position := lexer.position
+	isDecimal := lexer.currentChar != '0' || !strings.ContainsRune("xXoObB", lexer.peekChar())
 
-	for IsDigit(lexer.currentChar) {
-		lexer.readCharacter()
+	for {
+		switch {
+		case isLetter(lexer.currentChar) || unicode.IsDigit(lexer.currentChar):
+			isExponent := isDecimal && (lexer.currentChar == 'e' || lexer.currentChar == 'E')
+			lexer.readCharacter()
+
+			if isExponent && (lexer.currentChar == '+' || lexer.currentChar == '-') {
+				lexer.readCharacter()
+			}
+		case lexer.currentChar == '.' && isDecimal && IsDigit(lexer.peekChar()):
+			lexer.readCharacter()
+		default:
+			return lexer.input[position:lexer.position]
+		}
 	}
+}
 
-	return lexer.input[position:lexer.position]
+// checkNumber returns what is wrong with the literal of a number or an empty string if it's well-formed.
+// Numbers are decimal or start with 0x, 0o or 0b, an _ can separate digits and decimals can have a fraction
+// and an exponent like 1.5e3. Whether the value is a whole number that fits is left to the parser
+func checkNumber(literal string) string {
+	if len(literal) > 1 && literal[0] == '0' {
+		switch literal[1] {
+		case 'x', 'X':
+			return checkDigits(literal, literal[2:], 16, "hexadecimal")
+		case 'o', 'O':
+			return checkDigits(literal, literal[2:], 8, "octal")
+		case 'b', 'B':
+			return checkDigits(literal, literal[2:], 2, "binary")
+		}
+	}
+
+	mantissa, exponent := literal, ""
+
+	if i := strings.IndexAny(literal, "eE"); i >= 0 {
+		mantissa, exponent = literal[:i], strings.TrimLeft(literal[i+1:], "+-")
+
+		if exponent == "" {
+			return fmt.Sprintf("exponent of %s has no digits", literal)
+		}
+	}
+
+	integer, fraction, hasFraction := strings.Cut(mantissa, ".")
+
+	if len(integer) > 1 && integer[0] == '0' && !hasFraction && exponent == "" {
+		return fmt.Sprintf("invalid number %s, decimal numbers can't start with 0, octal ones start with 0o", literal)
+	}
+
+	for i, digits := range []string{integer, fraction, exponent} {
+		if i > 0 && digits == "" {
+			continue
+		}
+
+		if message := checkDigits(literal, digits, 10, "decimal"); message != "" {
+			return message
+		}
+	}
+
+	return ""
+}
+
+func checkDigits(literal string, digits string, base int, name string) string {
+	if digits == "" {
+		return fmt.Sprintf("%s number %s has no digits", name, literal)
+	}
+
+	for _, digit := range digits {
+		if value := digitValue(digit); digit != '_' && value >= base {
+			return fmt.Sprintf("invalid digit %q in %s number %s", digit, name, literal)
+		}
+	}
+
+	if digits[0] == '_' || digits[len(digits)-1] == '_' || strings.Contains(digits, "__") {
+		return fmt.Sprintf("_ must separate successive digits in %s", literal)
+	}
+
+	return ""
+}
+
+// digitValue returns the value of a digit up to base 36, anything else is worth more
+func digitValue(character rune) int {
+	switch {
+	case IsDigit(character):
+		return int(character - '0')
+	case 'a' <= character && character <= 'z':
+		return int(character-'a') + 10
+	case 'A' <= character && character <= 'Z':
+		return int(character-'A') + 10
+	}
+
+	return 36
 }
 
-func IsDigit(character byte) bool {
+func IsDigit(character rune) bool {
 	return '0' <= character && character <= '9'
 }
@@ -156,3 +156,472 @@ for true {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := `let x = 5;
+  x = "hi"
+`
+
+	tests := []struct {
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{"let", 1, 1},
+		{"x", 1, 5},
+		{"=", 1, 7},
+		{"5", 1, 9},
+		{";", 1, 10},
+		{"x", 2, 3},
+		{"=", 2, 5},
+		{"hi", 2, 7},
+		{"", 3, 1},
+	}
+
+	lexer := New(input)
+
+	for i, tt := range tests {
+		token := lexer.NextToken()
+
+		if token.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected=%q, got=%q",
+				i, tt.expectedLiteral, token.Literal)
+		}
+
+		if token.Line != tt.expectedLine || token.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, token.Line, token.Column)
+		}
+	}
+}
+
+func TestUnicodeIdentifiers(t *testing.T) {
+	input := "let größe1 = x_2 + π\n\"€\" _ü٣"
+
+	tests := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{token.LET, "let", 1, 1},
+		{token.IDENTIFIER, "größe1", 1, 5},
+		{token.ASSIGN, "=", 1, 12},
+		{token.IDENTIFIER, "x_2", 1, 14},
+		{token.PLUS, "+", 1, 18},
+		{token.IDENTIFIER, "π", 1, 20},
+		{token.STRING, "€", 2, 1},
+		{token.IDENTIFIER, "_ü٣", 2, 5},
+		{token.EOF, "", 2, 8},
+	}
+
+	lexer := New(input)
+
+	for i, tt := range tests {
+		token := lexer.NextToken()
+
+		if token.Type != tt.expectedType || token.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, token.Type, token.Literal)
+		}
+
+		if token.Line != tt.expectedLine || token.Column != tt.expectedColumn {
+			t.Fatalf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, token.Line, token.Column)
+		}
+	}
+}
+
+func TestIllegalCharacters(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedLiteral string
+		expectedError   string
+	}{
+		{"@", "@", "1:1: illegal character U+0040 '@'"},
+		{"x ＃", "＃", "1:3: illegal character U+FF03 '＃'"},
+		{"é\n  \u00a7", "§", "2:3: illegal character U+00A7 '§'"},
+		{"\x07", "\x07", "1:1: illegal character U+0007"},
+		{"println(1)\x00println(2)", "\x00", "1:11: illegal character U+0000"},
+		{"x \xff", "\xff", "1:3: invalid UTF-8 encoding"},
+		{"٣", "٣", "1:1: illegal character U+0663 '٣'"},
+		{"a ? b", "?", "1:3: illegal character U+003F '?', ? is only used in ?. and ??"},
+	}
+
+	for _, tt := range tests {
+		lexer := New(tt.input)
+		var illegal token.Token
+
+		for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
+			if tok.Type == token.ILLEGAL {
+				illegal = tok
+			}
+		}
+
+		if illegal.Literal != tt.expectedLiteral {
+			t.Errorf("wrong illegal token for %q. expected=%q, got=%q", tt.input, tt.expectedLiteral, illegal.Literal)
+		}
+
+		if errors := lexer.Errors(); len(errors) != 1 || errors[0].String() != tt.expectedError {
+			t.Errorf("wrong errors for %q. want=%q, got=%v", tt.input, tt.expectedError, errors)
+		}
+	}
+}
+
+func TestTypeAnnotationTokens(t *testing.T) {
+	input := `fn (a: int) -> [int] { a - 1 }`
+
+	tests := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.FUNCTION, "fn"},
+		{token.LPAREN, "("},
+		{token.IDENTIFIER, "a"},
+		{token.COLON, ":"},
+		{token.IDENTIFIER, "int"},
+		{token.RPAREN, ")"},
+		{token.ARROW, "->"},
+		{token.LBRACKET, "["},
+		{token.IDENTIFIER, "int"},
+		{token.RBRACKET, "]"},
+		{token.LBRACE, "{"},
+		{token.IDENTIFIER, "a"},
+		{token.MINUS, "-"},
+		{token.INTEGER, "1"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, tt := range tests {
+		token := lexer.NextToken()
+
+		if token.Type != tt.expectedType || token.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, token.Type, token.Literal)
+		}
+	}
+}
+
+func TestParameterTokens(t *testing.T) {
+	input := `fn (x, y = 1, ...rest) { f(...rest, y: x.add) }`
+
+	tests := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.FUNCTION, "fn"},
+		{token.LPAREN, "("},
+		{token.IDENTIFIER, "x"},
+		{token.COMMA, ","},
+		{token.IDENTIFIER, "y"},
+		{token.ASSIGN, "="},
+		{token.INTEGER, "1"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENTIFIER, "rest"},
+		{token.RPAREN, ")"},
+		{token.LBRACE, "{"},
+		{token.IDENTIFIER, "f"},
+		{token.LPAREN, "("},
+		{token.ELLIPSIS, "..."},
+		{token.IDENTIFIER, "rest"},
+		{token.COMMA, ","},
+		{token.IDENTIFIER, "y"},
+		{token.COLON, ":"},
+		{token.IDENTIFIER, "x"},
+		{token.DOT, "."},
+		{token.IDENTIFIER, "add"},
+		{token.RPAREN, ")"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, tt := range tests {
+		token := lexer.NextToken()
+
+		if token.Type != tt.expectedType || token.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, token.Type, token.Literal)
+		}
+	}
+}
+
+func TestPatternTokens(t *testing.T) {
+	input := `for [a, ...b] in xs {}`
+
+	tests := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.FOR, "for"},
+		{token.LBRACKET, "["},
+		{token.IDENTIFIER, "a"},
+		{token.COMMA, ","},
+		{token.ELLIPSIS, "..."},
+		{token.IDENTIFIER, "b"},
+		{token.RBRACKET, "]"},
+		{token.IN, "in"},
+		{token.IDENTIFIER, "xs"},
+		{token.LBRACE, "{"},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, tt := range tests {
+		token := lexer.NextToken()
+
+		if token.Type != tt.expectedType || token.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, token.Type, token.Literal)
+		}
+	}
+}
+
+func TestNullTokens(t *testing.T) {
+	input := `a?.b?.[0] ?? null`
+
+	tests := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.IDENTIFIER, "a"},
+		{token.OPTIONALCHAIN, "?."},
+		{token.IDENTIFIER, "b"},
+		{token.OPTIONALCHAIN, "?."},
+		{token.LBRACKET, "["},
+		{token.INTEGER, "0"},
+		{token.RBRACKET, "]"},
+		{token.COALESCE, "??"},
+		{token.NULL, "null"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, tt := range tests {
+		token := lexer.NextToken()
+
+		if token.Type != tt.expectedType || token.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, token.Type, token.Literal)
+		}
+	}
+}
+
+func TestInterpolationTokens(t *testing.T) {
+	input := `"a ${f({}) + "${x}"} b ${y}" }`
+
+	tests := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.INTERPOLATION, "a "},
+		{token.IDENTIFIER, "f"},
+		{token.LPAREN, "("},
+		{token.LBRACE, "{"},
+		{token.RBRACE, "}"},
+		{token.RPAREN, ")"},
+		{token.PLUS, "+"},
+		{token.INTERPOLATION, ""},
+		{token.IDENTIFIER, "x"},
+		{token.INTERPOLATIONEND, ""},
+		{token.INTERPOLATION, " b "},
+		{token.IDENTIFIER, "y"},
+		{token.INTERPOLATIONEND, ""},
+		{token.RBRACE, "}"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, tt := range tests {
+		token := lexer.NextToken()
+
+		if token.Type != tt.expectedType || token.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, token.Type, token.Literal)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// first
+let x = 10 / 2 // second
+x`
+
+	tests := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.LET, "let"},
+		{token.IDENTIFIER, "x"},
+		{token.ASSIGN, "="},
+		{token.INTEGER, "10"},
+		{token.SLASH, "/"},
+		{token.INTEGER, "2"},
+		{token.IDENTIFIER, "x"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, tt := range tests {
+		token := lexer.NextToken()
+
+		if token.Type != tt.expectedType || token.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, token.Type, token.Literal)
+		}
+	}
+
+	comments := lexer.Comments()
+
+	if len(comments) != 2 {
+		t.Fatalf("wrong number of comments. expected=2, got=%d", len(comments))
+	}
+
+	if comments[1].Literal != "// second" || comments[1].Line != 2 || comments[1].Column != 16 {
+		t.Fatalf("wrong comment. got=%+v", comments[1])
+	}
+}
+
+func TestStrings(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.Type
+		expectedLiteral string
+		expectedError   string
+	}{
+		{`"a0b"`, token.STRING, "a0b", ""},
+		{`"say \"hi\"\n"`, token.STRING, "say \"hi\"\n", ""},
+		{`"costs \${price} $ {}"`, token.STRING, "costs ${price} $ {}", ""},
+		{`"\t\r\0\x41\x7f"`, token.STRING, "\t\r\x00A\x7f", ""},
+		{`"\u{e9}\u{1F600}\u{10FFFF}"`, token.STRING, "é😀\U0010FFFF", ""},
+		{`"héllo, 世界"`, token.STRING, "héllo, 世界", ""},
+		{"`raw \\n ${x} \"`", token.RAWSTRING, `raw \n ${x} "`, ""},
+		{"`two\r\nlines`", token.RAWSTRING, "two\nlines", ""},
+		{`"unterminated`, token.STRING, "unterminated", "1:1: string literal not terminated"},
+		{`"ends in \`, token.STRING, "ends in ", "1:1: string literal not terminated"},
+		{"`unterminated", token.RAWSTRING, "unterminated", "1:1: raw string literal not terminated"},
+		{`"a\qb"`, token.STRING, "ab", "1:3: unknown escape sequence \\q"},
+		{`"\x4"`, token.STRING, "", "1:2: invalid escape sequence \\x4, \\x takes two hexadecimal digits"},
+		{`"\xff"`, token.STRING, "", "1:2: invalid escape sequence \\xff, use \\u{ff} for characters that aren't ASCII"},
+		{`"\u41"`, token.STRING, "41", "1:2: invalid escape sequence \\u, \\u takes hexadecimal digits in braces like \\u{1F600}"},
+		{`"\u{}"`, token.STRING, "}", "1:2: invalid escape sequence \\u{, \\u takes hexadecimal digits in braces like \\u{1F600}"},
+		{`"\u{D800}"`, token.STRING, "", "1:2: invalid escape sequence \\u{D800}, d800 is not a Unicode character"},
+		{`"\u{110000}"`, token.STRING, "", "1:2: invalid escape sequence \\u{110000}, 110000 is not a Unicode character"},
+		{"\"a\xffb\"", token.STRING, "ab", "1:3: invalid UTF-8 encoding"},
+		{"\"a\x00b\"", token.STRING, "ab", "1:3: illegal character U+0000"},
+		{"`a\x00b`", token.RAWSTRING, "ab", "1:3: illegal character U+0000"},
+		{"\"a\\\x00\"", token.STRING, "a", "1:3: unknown escape sequence \\\x00"},
+	}
+
+	for _, tt := range tests {
+		lexer := New(tt.input)
+		tok := lexer.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("wrong token for %s. expected=%q %q, got=%q %q", tt.input, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+
+		if tok := lexer.NextToken(); tok.Type != token.EOF {
+			t.Errorf("expected EOF after %s. got=%q %q", tt.input, tok.Type, tok.Literal)
+		}
+
+		errors := lexer.Errors()
+
+		switch {
+		case tt.expectedError == "" && len(errors) != 0:
+			t.Errorf("unexpected errors for %s. got=%v", tt.input, errors)
+		case tt.expectedError != "" && (len(errors) != 1 || errors[0].String() != tt.expectedError):
+			t.Errorf("wrong errors for %s. want=%q, got=%v", tt.input, tt.expectedError, errors)
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.Type
+		expectedLiteral string
+		expectedError   string
+	}{
+		{"1_000_000", token.INTEGER, "1_000_000", ""},
+		{"0xFF", token.INTEGER, "0xFF", ""},
+		{"0o17", token.INTEGER, "0o17", ""},
+		{"0b1010", token.INTEGER, "0b1010", ""},
+		{"1.5e3", token.INTEGER, "1.5e3", ""},
+		{"2E-3", token.INTEGER, "2E-3", ""},
+		{"0", token.INTEGER, "0", ""},
+		{"0x", token.ILLEGAL, "0x", "1:1: hexadecimal number 0x has no digits"},
+		{"0x1G", token.ILLEGAL, "0x1G", "1:1: invalid digit 'G' in hexadecimal number 0x1G"},
+		{"0b102", token.ILLEGAL, "0b102", "1:1: invalid digit '2' in binary number 0b102"},
+		{"12abc", token.ILLEGAL, "12abc", "1:1: invalid digit 'a' in decimal number 12abc"},
+		{"1__0", token.ILLEGAL, "1__0", "1:1: _ must separate successive digits in 1__0"},
+		{"0x_1", token.ILLEGAL, "0x_1", "1:1: _ must separate successive digits in 0x_1"},
+		{"1e+", token.ILLEGAL, "1e+", "1:1: exponent of 1e+ has no digits"},
+		{"017", token.ILLEGAL, "017", "1:1: invalid number 017, decimal numbers can't start with 0, octal ones start with 0o"},
+	}
+
+	for _, tt := range tests {
+		lexer := New(tt.input)
+		tok := lexer.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Errorf("wrong token for %s. expected=%q %q, got=%q %q", tt.input, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+
+		if tok := lexer.NextToken(); tok.Type != token.EOF {
+			t.Errorf("expected EOF after %s. got=%q %q", tt.input, tok.Type, tok.Literal)
+		}
+
+		errors := lexer.Errors()
+
+		switch {
+		case tt.expectedError == "" && len(errors) != 0:
+			t.Errorf("unexpected errors for %s. got=%v", tt.input, errors)
+		case tt.expectedError != "" && (len(errors) != 1 || errors[0].String() != tt.expectedError):
+			t.Errorf("wrong errors for %s. want=%q, got=%v", tt.input, tt.expectedError, errors)
+		}
+	}
+}
+
+func TestNumberBoundaries(t *testing.T) {
+	input := "0xbe+1 2.add(3) 1.e5"
+
+	tests := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.INTEGER, "0xbe"},
+		{token.PLUS, "+"},
+		{token.INTEGER, "1"},
+		{token.INTEGER, "2"},
+		{token.DOT, "."},
+		{token.IDENTIFIER, "add"},
+		{token.LPAREN, "("},
+		{token.INTEGER, "3"},
+		{token.RPAREN, ")"},
+		{token.INTEGER, "1"},
+		{token.DOT, "."},
+		{token.IDENTIFIER, "e5"},
+		{token.EOF, ""},
+	}
+
+	lexer := New(input)
+
+	for i, tt := range tests {
+		tok := lexer.NextToken()
+
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - token wrong. expected=%q %q, got=%q %q", i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package lsp
+
+import (
+	"go++/analyzer"
+	"go++/ast"
+	"go++/lexer"
+	"go++/parser"
+	"go++/token"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// document is an open file and the result of analyzing its latest text
+type document struct {
+	uri              string
+	text             string
+	lines            []string
+	positionEncoding string
+	diagnostics      []Diagnostic
+
+	// program and resolution are kept from the last text that parsed, completion needs them
+	// while the line being typed doesn't parse yet
+	program    *ast.Program
+	analyzer   *analyzer.Analyzer
+	resolution *analyzer.Resolution
+	isCurrent  bool
+}
+
+func newDocument(uri string, text string, positionEncoding string) *document {
+	doc := &document{uri: uri, positionEncoding: positionEncoding}
+	doc.update(text)
+
+	return doc
+}
+
+func (doc *document) update(text string) {
+	doc.text = text
+	doc.lines = strings.Split(text, "\n")
+	doc.diagnostics = []Diagnostic{}
+	doc.isCurrent = false
+
+	pars := parser.New(lexer.New(text))
+	program := pars.ParseProgram()
+
+	if len(pars.ErrorDetails()) > 0 {
+		for _, err := range pars.ErrorDetails() {
+			doc.diagnostics = append(doc.diagnostics, doc.diagnostic(err.Token.Line, err.Token.Column, SeverityError, err.Message))
+		}
+
+		return
+	}
+
+	staticAnalyzer := analyzer.New()
+
+	for _, diagnostic := range staticAnalyzer.Analyze(program) {
+		severity := SeverityError
+
+		if diagnostic.Severity == analyzer.Warning {
+			severity = SeverityWarning
+		}
+
+		doc.diagnostics = append(doc.diagnostics, doc.diagnostic(diagnostic.Line, diagnostic.Column, severity, diagnostic.Message))
+	}
+
+	doc.program = program
+	doc.analyzer = staticAnalyzer
+	doc.resolution = staticAnalyzer.Resolution()
+	doc.isCurrent = true
+}
+
+// diagnostic spans the word at the one based line and column, columns count characters like the lexer does
+func (doc *document) diagnostic(line int, column int, severity DiagnosticSeverity, message string) Diagnostic {
+	line = max(line, 1)
+	start := max(column, 1)
+	end := start
+
+	if line <= len(doc.lines) {
+		text := []rune(doc.lines[line-1])
+
+		for end <= len(text) && isWordRune(text[end-1]) {
+			end += 1
+		}
+	}
+
+	if end == start {
+		end += 1
+	}
+
+	return Diagnostic{Range: Range{Start: doc.position(line, start), End: doc.position(line, end)}, Severity: severity, Source: "gopp", Message: message}
+}
+
+// position converts a one based line and column counting characters to a position in the encoding of the client
+func (doc *document) position(line int, column int) Position {
+	pos := Position{Line: max(line-1, 0), Character: max(column-1, 0)}
+
+	if doc.positionEncoding != encodingUTF16 || pos.Line >= len(doc.lines) {
+		return pos
+	}
+
+	text := []rune(doc.lines[pos.Line])
+	characters := pos.Character
+	pos.Character = 0
+
+	for i := 0; i < characters; i++ {
+		pos.Character += 1
+
+		// Characters outside the basic multilingual plane take two UTF-16 code units
+		if i < len(text) && text[i] > 0xFFFF {
+			pos.Character += 1
+		}
+	}
+
+	return pos
+}
+
+// character converts the character of a position sent by the client to an index into the characters of its line
+func (doc *document) character(pos Position) int {
+	if pos.Line >= len(doc.lines) {
+		return 0
+	}
+
+	text := []rune(doc.lines[pos.Line])
+
+	if doc.positionEncoding != encodingUTF16 {
+		return min(pos.Character, len(text))
+	}
+
+	units := 0
+
+	for i, ch := range text {
+		if units >= pos.Character {
+			return i
+		}
+
+		units += 1
+
+		if ch > 0xFFFF {
+			units += 1
+		}
+	}
+
+	return len(text)
+}
+
+func isWordRune(ch rune) bool {
+	return ch == '_' || unicode.IsLetter(ch) || unicode.IsDigit(ch)
+}
+
+func (doc *document) tokenPosition(tok token.Token) Position {
+	return doc.position(tok.Line, tok.Column)
+}
+
+// nameRange spans a name that starts at the token
+func (doc *document) nameRange(tok token.Token, name string) Range {
+	return Range{Start: doc.tokenPosition(tok), End: doc.position(tok.Line, tok.Column+utf8.RuneCountInString(name))}
+}
+
+func (doc *document) identifierRange(identifier *ast.Identifier) Range {
+	return doc.nameRange(identifier.Token, identifier.Value)
+}
+
+func (r Range) contains(pos Position) bool {
+	return !isBeforePosition(pos, r.Start) && isBeforePosition(pos, r.End)
+}
+
+func isBeforePosition(a Position, b Position) bool {
+	return a.Line < b.Line || a.Line == b.Line && a.Character < b.Character
+}
+
+// bindingAt returns the declaring or using identifier under the position and its binding,
+// the positions are the ones of the last text that parsed
+func (doc *document) bindingAt(pos Position) (*ast.Identifier, *analyzer.Binding, bool) {
+	if doc.resolution == nil {
+		return nil, nil, false
+	}
+
+	var behind *ast.Identifier
+
+	for identifier, binding := range doc.resolution.Bindings {
+		r := doc.identifierRange(identifier)
+
+		if r.contains(pos) {
+			return identifier, binding, true
+		}
+
+		// A cursor right behind a name finds it too
+		if r.End == pos {
+			behind = identifier
+		}
+	}
+
+	if behind != nil {
+		return behind, doc.resolution.Bindings[behind], true
+	}
+
+	return nil, nil, false
+}
+
+// references returns every identifier bound to the binding in source order
+func (doc *document) references(binding *analyzer.Binding, includeDeclaration bool) []*ast.Identifier {
+	identifiers := []*ast.Identifier{}
+
+	for identifier, other := range doc.resolution.Bindings {
+		if other != binding || !includeDeclaration && identifier.Token == binding.Token {
+			continue
+		}
+
+		identifiers = append(identifiers, identifier)
+	}
+
+	sort.Slice(identifiers, func(i, j int) bool {
+		return isBeforePosition(doc.tokenPosition(identifiers[i].Token), doc.tokenPosition(identifiers[j].Token))
+	})
+
+	return identifiers
+}
+
+// scopeAt returns the innermost scope whose block contains the position
+func (doc *document) scopeAt(pos Position) *analyzer.Scope {
+	scope := doc.resolution.Global
+	var start Position
+
+	for node, candidate := range doc.resolution.Scopes {
+		var r Range
+
+		switch node := node.(type) {
+		case *ast.FunctionLiteral:
+			r = Range{Start: doc.tokenPosition(node.Token), End: doc.tokenPosition(node.Body.EndToken)}
+		case *ast.ForInLoopLiteral:
+			r = Range{Start: doc.tokenPosition(node.Token), End: doc.tokenPosition(node.Body.EndToken)}
+		case *ast.BlockStatement:
+			r = Range{Start: doc.tokenPosition(node.Token), End: doc.tokenPosition(node.EndToken)}
+		default:
+			continue
+		}
+
+		if r.contains(pos) && !isBeforePosition(r.Start, start) {
+			scope = candidate
+			start = r.Start
+		}
+	}
+
+	return scope
+}
+
+func (doc *document) symbols(statements []ast.Statement) []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+
+	for _, statement := range statements {
+		var symbol DocumentSymbol
+		var name *ast.Identifier
+		var fn *ast.FunctionLiteral
+
+		switch statement := statement.(type) {
+		case *ast.LetStatement:
+			if statement == nil {
+				continue
+			}
+
+			// Every name a pattern binds is a variable of its own
+			if statement.Pattern != nil {
+				symbols = append(symbols, doc.patternSymbols(statement)...)
+				continue
+			}
+
+			name = statement.Name
+			fn, _ = statement.Value.(*ast.FunctionLiteral)
+			symbol = DocumentSymbol{Kind: SymbolVariable, Range: Range{Start: doc.tokenPosition(statement.Token), End: doc.identifierRange(name).End}}
+		case *ast.FunctionStatement:
+			name = statement.Function.Name
+			fn = statement.Function
+			symbol = DocumentSymbol{Range: Range{Start: doc.tokenPosition(statement.Token)}}
+		default:
+			continue
+		}
+
+		symbol.Name = name.Value
+		symbol.SelectionRange = doc.identifierRange(name)
+
+		if binding, ok := doc.resolution.Bindings[name]; ok {
+			symbol.Detail = doc.analyzer.TypeOfBinding(binding).String()
+		}
+
+		if fn != nil {
+			end := doc.tokenPosition(fn.Body.EndToken)
+			end.Character += 1
+
+			symbol.Kind = SymbolFunction
+			symbol.Range.End = end
+			symbol.Children = doc.symbols(fn.Body.Statements)
+		}
+
+		symbols = append(symbols, symbol)
+	}
+
+	return symbols
+}
+
+func (doc *document) patternSymbols(statement *ast.LetStatement) []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+
+	for _, name := range ast.PatternIdentifiers(statement.Pattern) {
+		symbol := DocumentSymbol{Name: name.Value, Kind: SymbolVariable, Range: doc.identifierRange(name), SelectionRange: doc.identifierRange(name)}
+
+		if binding, ok := doc.resolution.Bindings[name]; ok {
+			symbol.Detail = doc.analyzer.TypeOfBinding(binding).String()
+		}
+
+		symbols = append(symbols, symbol)
+	}
+
+	return symbols
+}
@@ -0,0 +1,78 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"go++/framing"
+	"io"
+	"sync"
+)
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+	codeRequestFailed  = -32803
+)
+
+// message is a request or a notification, notifications have no id
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string { return e.Message }
+
+// conn reads and writes messages framed by a Content-Length header
+type conn struct {
+	in  *bufio.Reader
+	out io.Writer
+	mu  sync.Mutex
+}
+
+func newConn(in io.Reader, out io.Writer) *conn {
+	return &conn{in: bufio.NewReader(in), out: out}
+}
+
+func (c *conn) read() ([]byte, error) {
+	return framing.Read(c.in)
+}
+
+func (c *conn) write(v interface{}) error {
+	body, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return framing.Write(c.out, body)
+}
+
+func (c *conn) reply(id *json.RawMessage, result interface{}, err error) error {
+	if err == nil {
+		return c.write(map[string]interface{}{"jsonrpc": "2.0", "id": id, "result": result})
+	}
+
+	respErr, ok := err.(*responseError)
+
+	if !ok {
+		respErr = &responseError{Code: codeRequestFailed, Message: err.Error()}
+	}
+
+	return c.write(map[string]interface{}{"jsonrpc": "2.0", "id": id, "error": respErr})
+}
+
+func (c *conn) notify(method string, params interface{}) error {
+	return c.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
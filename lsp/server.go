@@ -0,0 +1,530 @@
+// Package lsp implements a language server for gopp speaking the Language Server Protocol
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go++/analyzer"
+	"go++/evaluator"
+	"go++/format"
+	"go++/token"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+type handler func(s *Server, params json.RawMessage) (interface{}, error)
+
+var handlers = map[string]handler{
+	"initialize":                  (*Server).initialize,
+	"initialized":                 ignore,
+	"shutdown":                    (*Server).shutdown,
+	"textDocument/didOpen":        (*Server).didOpen,
+	"textDocument/didChange":      (*Server).didChange,
+	"textDocument/didClose":       (*Server).didClose,
+	"textDocument/didSave":        ignore,
+	"textDocument/hover":          (*Server).hover,
+	"textDocument/definition":     (*Server).definition,
+	"textDocument/references":     (*Server).references,
+	"textDocument/documentSymbol": (*Server).documentSymbol,
+	"textDocument/completion":     (*Server).completion,
+	"textDocument/formatting":     (*Server).formatting,
+}
+
+func ignore(s *Server, params json.RawMessage) (interface{}, error) {
+	return nil, nil
+}
+
+const (
+	encodingUTF16 = "utf-16"
+	encodingUTF32 = "utf-32"
+)
+
+// Server keeps the open documents of a client, every document is analyzed on its own
+type Server struct {
+	conn             *conn
+	documents        map[string]*document
+	positionEncoding string
+	shutDown         bool
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{conn: newConn(in, out), documents: make(map[string]*document), positionEncoding: encodingUTF16}
+}
+
+// Serve handles messages until the client sends exit or closes the input
+func (s *Server) Serve() error {
+	for {
+		body, err := s.conn.read()
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		var msg message
+
+		if err := json.Unmarshal(body, &msg); err != nil {
+			if err := s.conn.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()}); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutDown {
+				return errors.New("exit without shutdown")
+			}
+
+			return nil
+		}
+
+		result, err := s.handle(msg)
+
+		// Notifications are never answered
+		if msg.ID == nil {
+			continue
+		}
+
+		if err := s.conn.reply(msg.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(msg message) (interface{}, error) {
+	handle, ok := handlers[msg.Method]
+
+	if !ok {
+		return nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + msg.Method}
+	}
+
+	return handle(s, msg.Params)
+}
+
+func decode(params json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	return nil
+}
+
+func (s *Server) document(uri string) (*document, error) {
+	doc, ok := s.documents[uri]
+
+	if !ok {
+		return nil, &responseError{Code: codeInvalidParams, Message: "document is not open: " + uri}
+	}
+
+	return doc, nil
+}
+
+func (s *Server) publishDiagnostics(doc *document) error {
+	return s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: doc.uri, Diagnostics: doc.diagnostics})
+}
+
+func (s *Server) initialize(params json.RawMessage) (interface{}, error) {
+	var p InitializeParams
+
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+
+	// Columns count code points like the lexer does, clients that can't count them get UTF-16 code units
+	if slices.Contains(p.Capabilities.General.PositionEncodings, encodingUTF32) {
+		s.positionEncoding = encodingUTF32
+	}
+
+	return InitializeResult{
+		Capabilities: ServerCapabilities{
+			PositionEncoding:           s.positionEncoding,
+			TextDocumentSync:           1,
+			HoverProvider:              true,
+			DefinitionProvider:         true,
+			ReferencesProvider:         true,
+			DocumentSymbolProvider:     true,
+			CompletionProvider:         CompletionOptions{TriggerCharacters: []string{"."}},
+			DocumentFormattingProvider: true,
+		},
+		ServerInfo: ServerInfo{Name: "gopp"},
+	}, nil
+}
+
+func (s *Server) shutdown(params json.RawMessage) (interface{}, error) {
+	s.shutDown = true
+
+	return nil, nil
+}
+
+func (s *Server) didOpen(params json.RawMessage) (interface{}, error) {
+	var p DidOpenTextDocumentParams
+
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+
+	doc := newDocument(p.TextDocument.URI, p.TextDocument.Text, s.positionEncoding)
+	s.documents[doc.uri] = doc
+
+	return nil, s.publishDiagnostics(doc)
+}
+
+func (s *Server) didChange(params json.RawMessage) (interface{}, error) {
+	var p DidChangeTextDocumentParams
+
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+
+	doc, err := s.document(p.TextDocument.URI)
+
+	if err != nil || len(p.ContentChanges) == 0 {
+		return nil, err
+	}
+
+	// The server asks for full syncs so the last change holds the whole text
+	doc.update(p.ContentChanges[len(p.ContentChanges)-1].Text)
+
+	return nil, s.publishDiagnostics(doc)
+}
+
+func (s *Server) didClose(params json.RawMessage) (interface{}, error) {
+	var p DidCloseTextDocumentParams
+
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+
+	delete(s.documents, p.TextDocument.URI)
+
+	return nil, s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []Diagnostic{}})
+}
+
+func (s *Server) hover(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+
+	doc, err := s.document(p.TextDocument.URI)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !doc.isCurrent {
+		return nil, nil
+	}
+
+	identifier, binding, ok := doc.bindingAt(p.Position)
+
+	if !ok {
+		return nil, nil
+	}
+
+	signature := binding.Name + ": " + doc.analyzer.TypeOfBinding(binding).String()
+	mutability := "immutable"
+
+	if binding.IsMutable {
+		mutability = "mutable"
+	}
+
+	if binding.Kind == analyzer.VariableBinding && binding.IsMutable {
+		signature = "let mut " + signature
+	} else if binding.Kind == analyzer.VariableBinding {
+		signature = "let " + signature
+	}
+
+	declared := "predeclared"
+
+	if binding.Token.Line > 0 {
+		declared = fmt.Sprintf("declared at %d:%d", binding.Token.Line, binding.Token.Column)
+	}
+
+	return Hover{
+		Contents: MarkupContent{
+			Kind:  "markdown",
+			Value: fmt.Sprintf("```gopp\n%s\n```\n%s %s, %s", signature, mutability, binding.Kind, declared),
+		},
+		Range: doc.identifierRange(identifier),
+	}, nil
+}
+
+func (s *Server) definition(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+
+	doc, err := s.document(p.TextDocument.URI)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !doc.isCurrent {
+		return nil, nil
+	}
+
+	_, binding, ok := doc.bindingAt(p.Position)
+
+	if !ok || binding.Token.Line == 0 {
+		return nil, nil
+	}
+
+	return Location{URI: doc.uri, Range: doc.nameRange(binding.Token, binding.Name)}, nil
+}
+
+func (s *Server) references(params json.RawMessage) (interface{}, error) {
+	var p ReferenceParams
+
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+
+	doc, err := s.document(p.TextDocument.URI)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !doc.isCurrent {
+		return nil, nil
+	}
+
+	_, binding, ok := doc.bindingAt(p.Position)
+
+	if !ok {
+		return nil, nil
+	}
+
+	locations := []Location{}
+
+	for _, identifier := range doc.references(binding, p.Context.IncludeDeclaration) {
+		locations = append(locations, Location{URI: doc.uri, Range: doc.identifierRange(identifier)})
+	}
+
+	return locations, nil
+}
+
+func (s *Server) documentSymbol(params json.RawMessage) (interface{}, error) {
+	var p DocumentSymbolParams
+
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+
+	doc, err := s.document(p.TextDocument.URI)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !doc.isCurrent {
+		return []DocumentSymbol{}, nil
+	}
+
+	return doc.symbols(doc.program.Statements), nil
+}
+
+// memberNames lists the methods of every type that has them by type name
+var memberNames = map[string][]string{
+	"string": evaluator.NewString("").GetMembers().Names(),
+	"array":  evaluator.NewArray(nil).GetMembers().Names(),
+	"int":    evaluator.NewInteger(0).GetMembers().Names(),
+}
+
+func (s *Server) completion(params json.RawMessage) (interface{}, error) {
+	var p TextDocumentPositionParams
+
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+
+	doc, err := s.document(p.TextDocument.URI)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Position.Line >= len(doc.lines) {
+		return []CompletionItem{}, nil
+	}
+
+	line := []rune(doc.lines[p.Position.Line])
+	start := doc.character(p.Position)
+
+	for start > 0 && isWordRune(line[start-1]) {
+		start -= 1
+	}
+
+	// The ? of a ?. isn't part of the receiver
+	if start > 0 && line[start-1] == '.' {
+		return doc.memberCompletions(strings.TrimSuffix(string(line[:start-1]), "?"), p.Position), nil
+	}
+
+	return doc.completions(p.Position), nil
+}
+
+// memberCompletions completes the methods of the value in front of the dot, every method
+// is offered when its type isn't known
+func (doc *document) memberCompletions(receiver string, pos Position) []CompletionItem {
+	typeNames := []string{doc.receiverType(receiver, pos)}
+
+	if memberNames[typeNames[0]] == nil {
+		typeNames = []string{"array", "int", "string"}
+	}
+
+	items := []CompletionItem{}
+	seen := make(map[string]bool)
+
+	for _, typeName := range typeNames {
+		for _, name := range memberNames[typeName] {
+			if !seen[name] {
+				seen[name] = true
+				items = append(items, CompletionItem{Label: name, Kind: CompletionMethod, Detail: typeName + " method"})
+			}
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+
+	return items
+}
+
+// receiverType guesses the type name of the text in front of a dot from literals and the types of bindings,
+// bindings are looked up by name since the text being typed may not have been analyzed
+func (doc *document) receiverType(receiver string, pos Position) string {
+	receiver = strings.TrimRight(receiver, " \t")
+
+	switch {
+	case strings.HasSuffix(receiver, "\""):
+		return "string"
+	case strings.HasSuffix(receiver, "]"):
+		depth := 0
+
+		for i := len(receiver) - 1; i >= 0; i-- {
+			if receiver[i] == ']' {
+				depth += 1
+			} else if receiver[i] == '[' {
+				depth -= 1
+			}
+
+			// An opening bracket that doesn't follow a value starts an array literal instead of an index
+			if depth == 0 {
+				before := strings.TrimRight(receiver[:i], " \t")
+				last, _ := utf8.DecodeLastRuneInString(before)
+
+				if before == "" || !isWordRune(last) && !strings.HasSuffix(before, ")") && !strings.HasSuffix(before, "]") {
+					return "array"
+				}
+
+				return ""
+			}
+		}
+	case doc.resolution != nil:
+		start := len(receiver)
+
+		for start > 0 {
+			last, size := utf8.DecodeLastRuneInString(receiver[:start])
+
+			if !isWordRune(last) {
+				break
+			}
+
+			start -= size
+		}
+
+		if start == len(receiver) || start > 0 && receiver[start-1] == '.' {
+			return ""
+		}
+
+		if binding, _, ok := doc.scopeAt(pos).Lookup(receiver[start:]); ok {
+			t := doc.analyzer.TypeOfBinding(binding)
+
+			if t.Kind == analyzer.ArrayType {
+				return "array"
+			}
+
+			return t.String()
+		}
+	}
+
+	return ""
+}
+
+// completions offers the bindings visible at the position, the builtins and the keywords
+func (doc *document) completions(pos Position) []CompletionItem {
+	items := []CompletionItem{}
+	seen := make(map[string]bool)
+
+	if doc.resolution != nil {
+		for scope := doc.scopeAt(pos); scope != nil; scope = scope.Parent {
+			for _, binding := range scope.Bindings() {
+				if seen[binding.Name] {
+					continue
+				}
+
+				seen[binding.Name] = true
+				kind := CompletionVariable
+				t := doc.analyzer.TypeOfBinding(binding)
+
+				if t.Kind == analyzer.FunctionType {
+					kind = CompletionFunction
+				}
+
+				items = append(items, CompletionItem{Label: binding.Name, Kind: kind, Detail: t.String()})
+			}
+		}
+	}
+
+	for _, name := range evaluator.BuiltinNames() {
+		items = append(items, CompletionItem{Label: name, Kind: CompletionFunction, Detail: "builtin"})
+	}
+
+	for keyword := range token.Keywords {
+		items = append(items, CompletionItem{Label: keyword, Kind: CompletionKeyword})
+	}
+
+	sort.SliceStable(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+
+	return items
+}
+
+func (s *Server) formatting(params json.RawMessage) (interface{}, error) {
+	var p DocumentFormattingParams
+
+	if err := decode(params, &p); err != nil {
+		return nil, err
+	}
+
+	doc, err := s.document(p.TextDocument.URI)
+
+	if err != nil {
+		return nil, err
+	}
+
+	formatted, err := format.Source([]byte(doc.text))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if string(formatted) == doc.text {
+		return []TextEdit{}, nil
+	}
+
+	end := Position{Line: strings.Count(doc.text, "\n") + 1, Character: 0}
+
+	return []TextEdit{{Range: Range{End: end}, NewText: string(formatted)}}, nil
+}
@@ -0,0 +1,476 @@
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+const uri = "file:///test.gopp"
+
+// client drives a server running in the same process through pipes
+type client struct {
+	t             *testing.T
+	conn          *conn
+	nextID        int
+	notifications []message
+	messages      chan []byte
+	done          chan error
+}
+
+func newClient(t *testing.T) *client {
+	c, _ := startClient(t, map[string]interface{}{})
+
+	return c
+}
+
+// startClient initializes the server with the params and returns what the server answered
+func startClient(t *testing.T, params interface{}) (*client, InitializeResult) {
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+
+	c := &client{t: t, conn: newConn(clientIn, clientOut), messages: make(chan []byte, 100), done: make(chan error, 1)}
+
+	go func() {
+		c.done <- NewServer(serverIn, serverOut).Serve()
+		_ = serverOut.Close()
+	}()
+
+	// Pipes don't buffer, messages are read right away so the server never blocks on writing
+	go func() {
+		defer close(c.messages)
+
+		for {
+			body, err := c.conn.read()
+
+			if err != nil {
+				return
+			}
+
+			c.messages <- body
+		}
+	}()
+
+	var result InitializeResult
+	c.call("initialize", params, &result)
+	c.notify("initialized", map[string]interface{}{})
+
+	return c, result
+}
+
+func (c *client) notify(method string, params interface{}) {
+	if err := c.conn.notify(method, params); err != nil {
+		c.t.Fatalf("notify %s failed: %v", method, err)
+	}
+}
+
+// call sends a request and decodes the result of its response, notifications sent in between are kept
+func (c *client) call(method string, params interface{}, result interface{}) {
+	c.nextID += 1
+
+	if err := c.conn.write(map[string]interface{}{"jsonrpc": "2.0", "id": c.nextID, "method": method, "params": params}); err != nil {
+		c.t.Fatalf("request %s failed: %v", method, err)
+	}
+
+	for {
+		body, ok := <-c.messages
+
+		if !ok {
+			c.t.Fatalf("connection closed before %s was answered", method)
+		}
+
+		var response struct {
+			ID     *int            `json:"id"`
+			Method string          `json:"method"`
+			Params json.RawMessage `json:"params"`
+			Result json.RawMessage `json:"result"`
+			Error  *responseError  `json:"error"`
+		}
+
+		if err := json.Unmarshal(body, &response); err != nil {
+			c.t.Fatalf("invalid message %s: %v", body, err)
+		}
+
+		if response.ID == nil {
+			c.notifications = append(c.notifications, message{Method: response.Method, Params: response.Params})
+			continue
+		}
+
+		if response.Error != nil {
+			c.t.Fatalf("%s failed: %s", method, response.Error.Message)
+		}
+
+		if result != nil {
+			if err := json.Unmarshal(response.Result, result); err != nil {
+				c.t.Fatalf("invalid result of %s %s: %v", method, response.Result, err)
+			}
+		}
+
+		return
+	}
+}
+
+// open opens the document and returns the diagnostics published for it
+func (c *client) open(text string) []Diagnostic {
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{TextDocument: TextDocumentItem{URI: uri, LanguageID: "gopp", Text: text}})
+
+	return c.lastDiagnostics()
+}
+
+func (c *client) change(text string) []Diagnostic {
+	c.notify("textDocument/didChange", DidChangeTextDocumentParams{
+		TextDocument:   TextDocumentIdentifier{URI: uri},
+		ContentChanges: []TextDocumentContentChangeEvent{{Text: text}},
+	})
+
+	return c.lastDiagnostics()
+}
+
+func (c *client) lastDiagnostics() []Diagnostic {
+	// Notifications are answered in order, so a request makes sure the diagnostics arrived
+	c.call("shutdown", nil, nil)
+
+	for i := len(c.notifications) - 1; i >= 0; i-- {
+		if c.notifications[i].Method == "textDocument/publishDiagnostics" {
+			var params PublishDiagnosticsParams
+
+			if err := json.Unmarshal(c.notifications[i].Params, &params); err != nil {
+				c.t.Fatal(err)
+			}
+
+			c.notifications = nil
+
+			return params.Diagnostics
+		}
+	}
+
+	c.t.Fatalf("no diagnostics were published")
+
+	return nil
+}
+
+func (c *client) close() {
+	c.call("shutdown", nil, nil)
+	c.notify("exit", nil)
+
+	if err := <-c.done; err != nil {
+		c.t.Errorf("server failed: %v", err)
+	}
+}
+
+func position(line int, character int) TextDocumentPositionParams {
+	return TextDocumentPositionParams{TextDocument: TextDocumentIdentifier{URI: uri}, Position: Position{Line: line, Character: character}}
+}
+
+const program = `let mut total = 0
+let add = fn (a: int, b: int) -> int {
+	let sum = a + b
+	sum
+}
+total = add(total, 2)
+let name = "gopp"
+`
+
+func TestDiagnostics(t *testing.T) {
+	c := newClient(t)
+	defer c.close()
+
+	if diagnostics := c.open(program); len(diagnostics) != 0 {
+		t.Errorf("expected no diagnostics. got=%+v", diagnostics)
+	}
+
+	tests := []struct {
+		text     string
+		expected []Diagnostic
+	}{
+		{"let x = y", []Diagnostic{{Range{Position{0, 8}, Position{0, 9}}, SeverityError, "gopp", "undefined: y"}}},
+		{"let x = 1\nx = 2", []Diagnostic{{Range{Position{1, 0}, Position{1, 1}}, SeverityError, "gopp", "cannot assign to immutable binding x (declared at 1:5)"}}},
+		{"let f = fn (unused) { 1 }", []Diagnostic{{Range{Position{0, 12}, Position{0, 18}}, SeverityWarning, "gopp", "parameter unused is unused"}}},
+		{"let x = ", []Diagnostic{{Range{Position{0, 8}, Position{0, 9}}, SeverityError, "gopp", "no prefix parse function for EOF found"}}},
+		{"let größe = 1 let f = fn (straße) { größe }", []Diagnostic{{Range{Position{0, 26}, Position{0, 32}}, SeverityWarning, "gopp", "parameter straße is unused"}}},
+		{"let é = @", []Diagnostic{{Range{Position{0, 8}, Position{0, 9}}, SeverityError, "gopp", "illegal character U+0040 '@'"}}},
+	}
+
+	for _, tt := range tests {
+		diagnostics := c.change(tt.text)
+
+		if len(diagnostics) != len(tt.expected) {
+			t.Errorf("wrong number of diagnostics for %q. want=%+v, got=%+v", tt.text, tt.expected, diagnostics)
+			continue
+		}
+
+		for i, diagnostic := range diagnostics {
+			if diagnostic != tt.expected[i] {
+				t.Errorf("wrong diagnostic for %q. want=%+v, got=%+v", tt.text, tt.expected[i], diagnostic)
+			}
+		}
+	}
+}
+
+func TestHover(t *testing.T) {
+	c := newClient(t)
+	defer c.close()
+
+	c.open(program)
+
+	tests := []struct {
+		line      int
+		character int
+		expected  string
+	}{
+		{0, 9, "```gopp\nlet mut total: any\n```\nmutable variable, declared at 1:9"},
+		{1, 15, "```gopp\na: int\n```\nmutable parameter, declared at 2:15"},
+		{5, 9, "```gopp\nlet add: fn (int, int) -> int\n```\nimmutable variable, declared at 2:5"},
+		{6, 6, "```gopp\nlet name: string\n```\nimmutable variable, declared at 7:5"},
+	}
+
+	for _, tt := range tests {
+		var hover Hover
+		c.call("textDocument/hover", position(tt.line, tt.character), &hover)
+
+		if hover.Contents.Value != tt.expected {
+			t.Errorf("wrong hover at %d:%d. want=%q, got=%q", tt.line, tt.character, tt.expected, hover.Contents.Value)
+		}
+	}
+
+	var hover *Hover
+	c.call("textDocument/hover", position(0, 0), &hover)
+
+	if hover != nil {
+		t.Errorf("expected no hover on a keyword. got=%+v", hover)
+	}
+}
+
+func TestDefinitionAndReferences(t *testing.T) {
+	c := newClient(t)
+	defer c.close()
+
+	c.open(program)
+
+	var location Location
+	c.call("textDocument/definition", position(3, 2), &location)
+
+	if location.URI != uri || location.Range != (Range{Position{2, 5}, Position{2, 8}}) {
+		t.Errorf("wrong definition of sum. got=%+v", location)
+	}
+
+	params := ReferenceParams{TextDocumentPositionParams: position(0, 10)}
+	params.Context.IncludeDeclaration = true
+
+	var locations []Location
+	c.call("textDocument/references", params, &locations)
+
+	expected := []Range{
+		{Position{0, 8}, Position{0, 13}},
+		{Position{5, 0}, Position{5, 5}},
+		{Position{5, 12}, Position{5, 17}},
+	}
+
+	if len(locations) != len(expected) {
+		t.Fatalf("wrong number of references to total. want=%d, got=%+v", len(expected), locations)
+	}
+
+	for i, location := range locations {
+		if location.Range != expected[i] {
+			t.Errorf("wrong reference %d. want=%+v, got=%+v", i, expected[i], location.Range)
+		}
+	}
+
+	params.Context.IncludeDeclaration = false
+	c.call("textDocument/references", params, &locations)
+
+	if len(locations) != 2 {
+		t.Errorf("expected the declaration to be left out. got=%+v", locations)
+	}
+}
+
+func TestPositionEncoding(t *testing.T) {
+	// The emoji takes two UTF-16 code units but is a single character
+	text := "let s = \"😀\" let n = 1 n\nlet é = \"😀\" @"
+
+	tests := []struct {
+		encodings  []string
+		expected   string
+		definition Range
+		hover      Position
+		diagnostic Range
+	}{
+		{nil, encodingUTF16, Range{Position{0, 17}, Position{0, 18}}, Position{0, 23}, Range{Position{1, 13}, Position{1, 14}}},
+		{[]string{encodingUTF16}, encodingUTF16, Range{Position{0, 17}, Position{0, 18}}, Position{0, 23}, Range{Position{1, 13}, Position{1, 14}}},
+		{[]string{encodingUTF32, encodingUTF16}, encodingUTF32, Range{Position{0, 16}, Position{0, 17}}, Position{0, 22}, Range{Position{1, 12}, Position{1, 13}}},
+	}
+
+	for _, tt := range tests {
+		params := map[string]interface{}{"capabilities": map[string]interface{}{"general": map[string]interface{}{"positionEncodings": tt.encodings}}}
+		c, result := startClient(t, params)
+
+		if result.Capabilities.PositionEncoding != tt.expected {
+			t.Errorf("wrong position encoding for %v. want=%q, got=%q", tt.encodings, tt.expected, result.Capabilities.PositionEncoding)
+		}
+
+		diagnostics := c.open(text)
+
+		if len(diagnostics) != 1 || diagnostics[0].Range != tt.diagnostic {
+			t.Errorf("wrong diagnostics in %s. want range %+v, got=%+v", tt.expected, tt.diagnostic, diagnostics)
+		}
+
+		c.change(strings.Split(text, "\n")[0])
+
+		var location Location
+		c.call("textDocument/definition", position(tt.hover.Line, tt.hover.Character), &location)
+
+		if location.Range != tt.definition {
+			t.Errorf("wrong definition of n in %s. want=%+v, got=%+v", tt.expected, tt.definition, location.Range)
+		}
+
+		var hover Hover
+		c.call("textDocument/hover", position(tt.hover.Line, tt.hover.Character), &hover)
+
+		if hover.Range != (Range{tt.hover, Position{tt.hover.Line, tt.hover.Character + 1}}) {
+			t.Errorf("wrong hover range of n in %s. got=%+v", tt.expected, hover.Range)
+		}
+
+		var items []CompletionItem
+		c.call("textDocument/completion", position(0, tt.hover.Character+1), &items)
+
+		if len(items) == 0 || items[0].Kind == CompletionMethod {
+			t.Errorf("wrong completions behind n in %s. got=%+v", tt.expected, items)
+		}
+
+		c.close()
+	}
+}
+
+func TestDocumentSymbols(t *testing.T) {
+	c := newClient(t)
+	defer c.close()
+
+	c.open(program)
+
+	var symbols []DocumentSymbol
+	c.call("textDocument/documentSymbol", DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &symbols)
+
+	names := []string{}
+
+	for _, symbol := range symbols {
+		names = append(names, symbol.Name)
+	}
+
+	if strings.Join(names, " ") != "total add name" {
+		t.Fatalf("wrong symbols. got=%v", names)
+	}
+
+	add := symbols[1]
+
+	if add.Kind != SymbolFunction || add.Range != (Range{Position{1, 0}, Position{4, 1}}) || len(add.Children) != 1 || add.Children[0].Name != "sum" {
+		t.Errorf("wrong symbol for add. got=%+v", add)
+	}
+}
+
+func TestFunctionDeclarationSymbols(t *testing.T) {
+	c := newClient(t)
+	defer c.close()
+
+	if diagnostics := c.open("double(2)\n\nfn double(x) {\n\tx * 2\n}\n"); len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics. got=%+v", diagnostics)
+	}
+
+	var symbols []DocumentSymbol
+	c.call("textDocument/documentSymbol", DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &symbols)
+
+	if len(symbols) != 1 || symbols[0].Name != "double" || symbols[0].Kind != SymbolFunction || symbols[0].Range != (Range{Position{2, 0}, Position{4, 1}}) {
+		t.Errorf("wrong symbols. got=%+v", symbols)
+	}
+}
+
+func TestPatternSymbols(t *testing.T) {
+	c := newClient(t)
+	defer c.close()
+
+	if diagnostics := c.open("let [first, ...rest] = [1, 2]\n"); len(diagnostics) != 0 {
+		t.Fatalf("expected no diagnostics. got=%+v", diagnostics)
+	}
+
+	var symbols []DocumentSymbol
+	c.call("textDocument/documentSymbol", DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &symbols)
+
+	if len(symbols) != 2 || symbols[0].Name != "first" || symbols[0].Detail != "int" || symbols[1].Name != "rest" || symbols[1].Detail != "[int]" {
+		t.Errorf("wrong symbols. got=%+v", symbols)
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	c := newClient(t)
+	defer c.close()
+
+	c.open(program)
+
+	labels := func(line int, character int) []string {
+		var items []CompletionItem
+		c.call("textDocument/completion", position(line, character), &items)
+
+		labels := []string{}
+
+		for _, item := range items {
+			labels = append(labels, item.Label)
+		}
+
+		return labels
+	}
+
+	contains := func(labels []string, label string) bool {
+		for _, l := range labels {
+			if l == label {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	inside := labels(3, 1)
+
+	for _, label := range []string{"a", "b", "sum", "total", "add", "println", "let"} {
+		if !contains(inside, label) {
+			t.Errorf("expected %s to be completed inside add. got=%v", label, inside)
+		}
+	}
+
+	if outside := labels(6, 0); contains(outside, "sum") || contains(outside, "a") {
+		t.Errorf("expected the locals of add not to be completed outside of it. got=%v", outside)
+	}
+
+	// The edited line doesn't parse, the members are found through the last analysis
+	c.change(program + "name.")
+
+	if members := labels(7, 5); strings.Join(members, " ") != "length replace" {
+		t.Errorf("wrong string members. got=%v", members)
+	}
+
+	c.change(program + "[1, 2].")
+
+	if members := labels(7, 7); strings.Join(members, " ") != "forEach length map" {
+		t.Errorf("wrong array members. got=%v", members)
+	}
+
+	c.change(program + "name?.")
+
+	if members := labels(7, 6); strings.Join(members, " ") != "length replace" {
+		t.Errorf("wrong string members after ?. got=%v", members)
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	c := newClient(t)
+	defer c.close()
+
+	c.open("let x   =  1\nlet y = fn(a){a}")
+
+	var edits []TextEdit
+	c.call("textDocument/formatting", DocumentFormattingParams{TextDocument: TextDocumentIdentifier{URI: uri}}, &edits)
+
+	if len(edits) != 1 || edits[0].NewText != "let x = 1\nlet y = fn (a) { a }\n" || edits[0].Range.End != (Position{2, 0}) {
+		t.Errorf("wrong formatting edits. got=%+v", edits)
+	}
+}
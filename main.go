@@ -3,39 +3,182 @@ package main
 import (
 	"errors"
 	"fmt"
+	"go++/analyzer"
+	"go++/ast"
 	"go++/evaluator"
 	"go++/lexer"
 	"go++/object"
 	"go++/parser"
-	"go++/repl"
 	"os"
+	"strings"
 )
 
+const usage = `usage: gopp <command> [arguments]
+
+commands:
+	run [-assert-types] [-profile] [-pprof file] [-e expr | file] [args...]
+	                                                 run a program, optionally measuring it
+	repl                                             start the interactive prompt
+	check [-e expr | files...]                       report syntax and static analysis errors
+	fmt [-w] files...                                format programs
+	test [-run regexp] [-v] [-cover] [-coverhtml file] [paths...]
+	                                                 run the test functions of *_test.gopp files
+	tokens [-e expr | file]                          print the tokens of a program
+	ast [-e expr | file]                             print the syntax tree of a program
+	debug file [args...]                             run a program in the debugger
+	dap                                              start the debug adapter on stdin and stdout
+	lsp                                              start the language server on stdin and stdout
+
+gopp file.gopp runs the file and gopp without arguments starts the REPL`
+
+const (
+	exitOk    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// usageError is reported with the usage and exits with exitUsage
+type usageError struct {
+	message string
+}
+
+func (e usageError) Error() string { return e.message }
+
 func main() {
-	if len(os.Args) < 2 {
-		repl.Start(os.Stdin, os.Stdout)
-		return
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	err := dispatch(args)
+
+	if err == nil {
+		return exitOk
 	}
 
-	_, err := runFromFile(os.Args[1])
+	_, _ = fmt.Fprintln(os.Stderr, err)
 
-	if err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, err)
+	var usageErr usageError
+
+	if errors.As(err, &usageErr) {
+		_, _ = fmt.Fprintln(os.Stderr, usage)
+		return exitUsage
 	}
+
+	return exitError
 }
 
-func runFromFile(file string) (object.Object, error) {
-	data, err := os.ReadFile(file)
+func dispatch(args []string) error {
+	if len(args) == 0 {
+		return replCommand(nil)
+	}
+
+	commands := map[string]func([]string) error{
+		"run":    runCommand,
+		"repl":   replCommand,
+		"check":  checkCommand,
+		"fmt":    formatFiles,
+		"tokens": tokensCommand,
+		"ast":    astCommand,
+		"test":   testCommand,
+		"lsp":    lspCommand,
+		"debug":  debugCommand,
+		"dap":    dapCommand,
+	}
+
+	if command, ok := commands[args[0]]; ok {
+		return command(args[1:])
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		fmt.Println(usage)
+		return nil
+	}
+
+	// Without a command the arguments are the ones of run, `gopp file.gopp` or `gopp -e expr`
+	return runCommand(args)
+}
+
+// source is the program a command works on, read from a file or given with -e
+type source struct {
+	name string
+	code string
+}
+
+func readSource(expression string, args []string) (source, []string, error) {
+	if expression != "" {
+		return source{name: "-e", code: expression}, args, nil
+	}
+
+	if len(args) == 0 {
+		return source{}, nil, usageError{"no file or -e expression given"}
+	}
+
+	data, err := os.ReadFile(args[0])
 
 	if err != nil {
-		return nil, errors.New("no file found named " + file)
+		return source{}, nil, errors.New("no file found named " + args[0])
 	}
 
-	lex := lexer.New(string(data))
-	pars := parser.New(lex)
+	return source{name: args[0], code: string(data)}, args[1:], nil
+}
 
+func parseSource(src source) (*ast.Program, error) {
+	pars := parser.New(lexer.New(src.code))
 	program := pars.ParseProgram()
+
+	if len(pars.Errors()) > 0 {
+		messages := make([]string, len(pars.Errors()))
+
+		for i, msg := range pars.Errors() {
+			messages[i] = src.name + ":" + msg
+		}
+
+		return nil, errors.New(strings.Join(messages, "\n"))
+	}
+
+	return program, nil
+}
+
+// newGlobals creates the environment programs run in and an analyzer that knows its bindings
+func newGlobals(scriptArgs []string) (*object.Environment, *analyzer.Analyzer) {
 	env := object.NewEnvironment()
+	staticAnalyzer := analyzer.New()
+
+	values := make([]object.Object, len(scriptArgs))
+
+	for i, arg := range scriptArgs {
+		values[i] = evaluator.NewString(arg)
+	}
+
+	env.Set("args", evaluator.NewArray(values), false)
+	staticAnalyzer.Declare("args", false)
+
+	return env, staticAnalyzer
+}
+
+// prepareSource parses and analyzes a program and creates the environment it runs in
+func prepareSource(src source, scriptArgs []string) (*ast.Program, *object.Environment, error) {
+	program, err := parseSource(src)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	env, staticAnalyzer := newGlobals(scriptArgs)
+
+	if diagnostics := analyzer.Errors(staticAnalyzer.Analyze(program)); len(diagnostics) > 0 {
+		return nil, nil, diagnosticsError(src.name, diagnostics)
+	}
+
+	return program, env, nil
+}
+
+func runSource(src source, scriptArgs []string) (object.Object, error) {
+	program, env, err := prepareSource(src, scriptArgs)
+
+	if err != nil {
+		return nil, err
+	}
 
 	obj := evaluator.Evaluate(program, env)
 
@@ -46,24 +189,22 @@ func runFromFile(file string) (object.Object, error) {
 	return obj, nil
 }
 
-/*
-func runFromMultipleFiles() (object.Object, error) {
-	err := filepath.Walk(".",
-		func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-
-			if splits := strings.Split(path, "."); splits[1] == "gopp" {
-
-			}
+func runFromFile(file string) (object.Object, error) {
+	src, _, err := readSource("", []string{file})
 
-			fmt.Println(path, info.Size())
-			return nil
-		})
 	if err != nil {
-		log.Println(err)
+		return nil, err
 	}
+
+	return runSource(src, nil)
 }
 
-*/
+func diagnosticsError(file string, diagnostics []analyzer.Diagnostic) error {
+	messages := make([]string, len(diagnostics))
+
+	for i, diagnostic := range diagnostics {
+		messages[i] = file + ":" + diagnostic.String()
+	}
+
+	return errors.New(strings.Join(messages, "\n"))
+}
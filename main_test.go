@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRunSource(t *testing.T) {
+	tests := []struct {
+		code          string
+		args          []string
+		expected      string
+		expectedError string
+	}{
+		{"args", []string{"a", "b"}, "[a, b]", ""},
+		{"args.length()", nil, "0", ""},
+		{"let x = 1 + 2 x", nil, "3", ""},
+		{"let x = ", nil, "", "-e:1:9: no prefix parse function for EOF found"},
+		{"let x = 1 x = 2", nil, "", "-e:1:11: cannot assign to immutable binding x (declared at 1:5)"},
+		{"args = 1", nil, "", "-e:1:1: cannot assign to immutable binding args (predeclared)"},
+		{"let arr = [1] arr[1]", nil, "", "ERROR: index 1 out of range"},
+	}
+
+	for _, tt := range tests {
+		obj, err := runSource(source{name: "-e", code: tt.code}, tt.args)
+
+		if tt.expectedError != "" {
+			if err == nil || err.Error() != tt.expectedError {
+				t.Errorf("wrong error for %q. want=%q, got=%v", tt.code, tt.expectedError, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("unexpected error for %q: %s", tt.code, err)
+			continue
+		}
+
+		if obj.Inspect() != tt.expected {
+			t.Errorf("wrong result for %q. want=%s, got=%s", tt.code, tt.expected, obj.Inspect())
+		}
+	}
+}
+
+func TestReadSource(t *testing.T) {
+	src, rest, err := readSource("1 + 1", []string{"a"})
+
+	if err != nil || src.name != "-e" || src.code != "1 + 1" || len(rest) != 1 {
+		t.Errorf("wrong source for -e. got=%+v %v %v", src, rest, err)
+	}
+
+	if _, _, err := readSource("", nil); err == nil {
+		t.Errorf("expected an error without a file")
+	} else if _, ok := err.(usageError); !ok {
+		t.Errorf("expected a usage error. got=%T", err)
+	}
+
+	src, rest, err = readSource("", []string{"testPrograms/gopp.gopp", "x"})
+
+	if err != nil || src.name != "testPrograms/gopp.gopp" || len(rest) != 1 || rest[0] != "x" {
+		t.Errorf("wrong source for file. got=%+v %v %v", src.name, rest, err)
+	}
+}
+
+func TestDispatchExitCodes(t *testing.T) {
+	if code := run([]string{"run", "-e", "1"}); code != exitOk {
+		t.Errorf("wrong exit code for a valid program. want=%d, got=%d", exitOk, code)
+	}
+
+	if code := run([]string{"-e", "undefinedName"}); code != exitError {
+		t.Errorf("wrong exit code for an invalid program. want=%d, got=%d", exitError, code)
+	}
+
+	if code := run([]string{"tokens"}); code != exitUsage {
+		t.Errorf("wrong exit code for a missing file. want=%d, got=%d", exitUsage, code)
+	}
+
+	if code := run([]string{"test", "-run", "("}); code != exitUsage {
+		t.Errorf("wrong exit code for an invalid -run expression. want=%d, got=%d", exitUsage, code)
+	}
+}
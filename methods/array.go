@@ -14,56 +14,64 @@ type ArrayHelper interface {
 
 func GetBuiltinArrayMethods(helper ArrayHelper) map[string]object.Object {
 	return map[string]object.Object{
-		"length": &object.BuiltinMethod{Fn: func(args ...object.Object) object.Object {
-			if len(args) != 1 {
-				return helper.NewError("ERROR: No arguments should be given to 'length'")
+		"length": &object.BuiltinMethod{Name: "length", Arity: 0, Fn: func(it object.Object, args ...object.Object) object.Object {
+			if _, ok := it.(*object.Array); !ok {
+				return helper.NewError("ERROR: 'length' must be called on an array")
 			}
 
-			if _, ok := args[0].(*object.Array); !ok {
-				return helper.NewError("ERROR: First argument must be an array")
-			}
-
-			return &object.Integer{Value: int64(len(args[0].(*object.Array).Values))}
+			return helper.NewInteger(int64(len(it.(*object.Array).Values)))
 		}},
-		"forEach": &object.BuiltinMethod{Fn: func(args ...object.Object) object.Object {
-			if len(args) != 2 {
-				return helper.NewError("ERROR: Only a callback should be given to 'forEach'")
-			}
-
-			if _, ok := args[0].(*object.Array); !ok {
-				return helper.NewError("ERROR: First argument must be an array")
+		"forEach": &object.BuiltinMethod{Name: "forEach", Arity: 1, Fn: func(it object.Object, args ...object.Object) object.Object {
+			if _, ok := it.(*object.Array); !ok {
+				return helper.NewError("ERROR: 'forEach' must be called on an array")
 			}
 
-			if _, ok := args[1].(*object.Function); !ok {
+			if !isCallable(args[0]) {
 				return helper.NewError("ERROR: First argument must be a function")
 			}
 
-			for key, value := range args[0].(*object.Array).Values {
-				helper.ApplyFunction(args[1].(*object.Function), []object.Object{helper.NewInteger(int64(key)), value})
+			for key, value := range it.(*object.Array).Values {
+				result := helper.ApplyFunction(args[0], []object.Object{helper.NewInteger(int64(key)), value})
+
+				if result != nil && result.Type() == object.ERROR {
+					return result
+				}
 			}
 
 			return helper.GetNull()
 		}},
-		"map": &object.BuiltinMethod{Fn: func(args ...object.Object) object.Object {
-			if len(args) != 2 {
-				return helper.NewError("ERROR: Only a callback should be given to 'map'")
-			}
-
-			if _, ok := args[0].(*object.Array); !ok {
-				return helper.NewError("ERROR: First argument must be an array")
+		"map": &object.BuiltinMethod{Name: "map", Arity: 1, Fn: func(it object.Object, args ...object.Object) object.Object {
+			if _, ok := it.(*object.Array); !ok {
+				return helper.NewError("ERROR: 'map' must be called on an array")
 			}
 
-			if _, ok := args[1].(*object.Function); !ok {
+			if !isCallable(args[0]) {
 				return helper.NewError("ERROR: First argument must be a function")
 			}
 
 			var newArray []object.Object
 
-			for key, value := range args[0].(*object.Array).Values {
-				newArray = append(newArray, helper.ApplyFunction(args[1].(*object.Function), []object.Object{helper.NewInteger(int64(key)), value}))
+			for key, value := range it.(*object.Array).Values {
+				result := helper.ApplyFunction(args[0], []object.Object{helper.NewInteger(int64(key)), value})
+
+				if result != nil && result.Type() == object.ERROR {
+					return result
+				}
+
+				newArray = append(newArray, result)
 			}
 
 			return helper.NewArray(newArray)
 		}},
 	}
 }
+
+// isCallable reports whether a callback can be applied, functions, builtins and bound methods can
+func isCallable(fn object.Object) bool {
+	switch fn.(type) {
+	case *object.Function, *object.Builtin, *object.BoundMethod:
+		return true
+	}
+
+	return false
+}
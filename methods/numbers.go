@@ -10,23 +10,19 @@ type NumberHelper interface {
 
 func GetBuiltinNumberMethods(helper NumberHelper) map[string]object.Object {
 	return map[string]object.Object{
-		"add": &object.BuiltinMethod{Fn: func(args ...object.Object) object.Object {
-			if len(args) != 2 {
-				return helper.NewError("ERROR: Only one argument should be given to 'add'")
-			}
-
-			switch args[0].(type) {
+		"add": &object.BuiltinMethod{Name: "add", Arity: 1, Fn: func(it object.Object, args ...object.Object) object.Object {
+			switch it.(type) {
 			case *object.Integer:
-				adder, ok := args[1].(*object.Integer)
+				adder, ok := args[0].(*object.Integer)
 
 				if !ok {
-					return helper.NewError("ERROR: Only one argument should be given to 'add'")
+					return helper.NewError("ERROR: The argument of 'add' must be an integer")
 				}
 
-				return helper.NewInteger(args[0].(*object.Integer).Value + adder.Value)
+				return helper.NewInteger(it.(*object.Integer).Value + adder.Value)
 			}
 
-			return helper.NewError("ERROR: Type %T not supported", args[0])
+			return helper.NewError("ERROR: Type %T not supported", it)
 		}},
 	}
 }
@@ -13,43 +13,34 @@ type StringHelper interface {
 
 func GetBuiltinStringMethods(helper StringHelper) map[string]object.Object {
 	return map[string]object.Object{
-		"length": &object.BuiltinMethod{Fn: func(args ...object.Object) object.Object {
-			if len(args) != 1 {
-				return helper.NewString("ERROR: No arguments should be given to 'length'")
+		"length": &object.BuiltinMethod{Name: "length", Arity: 0, Fn: func(it object.Object, args ...object.Object) object.Object {
+			if _, ok := it.(*object.String); !ok {
+				return helper.NewError("ERROR: 'length' must be called on a string")
 			}
 
-			if _, ok := args[0].(*object.String); !ok {
-				return helper.NewError("ERROR: First argument must be a string")
-			}
-
-			return helper.NewInteger(int64(len(args[0].(*object.String).Value)))
+			return helper.NewInteger(int64(len(it.(*object.String).Value)))
 		}},
-		"replace": &object.BuiltinMethod{Fn: func(args ...object.Object) object.Object {
-			if len(args) != 3 {
-				return helper.NewError("ERROR: Replace requires 2 arguments")
-			}
-
-			it, ok := args[0].(*object.String)
+		"replace": &object.BuiltinMethod{Name: "replace", Arity: 2, Fn: func(it object.Object, args ...object.Object) object.Object {
+			str, ok := it.(*object.String)
 
 			if !ok {
-				return helper.NewError("ERROR: First argument must be a string")
+				return helper.NewError("ERROR: 'replace' must be called on a string")
 			}
 
-			replace, ok := args[1].(*object.String)
+			replace, ok := args[0].(*object.String)
 
 			if !ok {
 				return helper.NewError("ERROR: First argument must be a string")
 			}
 
-			replacer, ok := args[2].(*object.String)
+			replacer, ok := args[1].(*object.String)
 
 			if !ok {
 				return helper.NewError("ERROR: Second argument must be a string")
 			}
 
-			it.Value = strings.Replace(it.Value, replace.Value, replacer.Value, -1)
-
-			return args[0]
+			// The receiver is left as it is, strings are values
+			return helper.NewString(strings.Replace(str.Value, replace.Value, replacer.Value, -1))
 		}},
 	}
 }
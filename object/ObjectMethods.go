@@ -1,5 +1,7 @@
 package object
 
+import "sort"
+
 type ObjectMembers struct {
 	Members        map[string]Object
 	MutableMembers bool
@@ -9,6 +11,20 @@ func NewMembers(members map[string]Object, isMutable bool) *ObjectMembers {
 	return &ObjectMembers{members, isMutable}
 }
 
+// methodTables holds the methods of every type, a table is shared by all values of its type and never changes
+// once it is registered
+var methodTables = map[Type]*ObjectMembers{}
+
+// RegisterMethods sets the method table of a type, it is called once for every type when the program starts
+func RegisterMethods(objectType Type, methods *ObjectMembers) {
+	methodTables[objectType] = methods
+}
+
+// Methods returns the method table of a type, types without methods have none
+func Methods(objectType Type) *ObjectMembers {
+	return methodTables[objectType]
+}
+
 //TODO add methods
 /*func (members *ObjectMethods[T]) Add(name string, obj Object) bool {
 	if !members.isMutable {
@@ -22,7 +38,12 @@ func NewMembers(members map[string]Object, isMutable bool) *ObjectMembers {
 	return true
 }*/
 
+// Get looks a member up, objects without members have none
 func (members *ObjectMembers) Get(name string) (Object, bool) {
+	if members == nil {
+		return nil, false
+	}
+
 	val, ok := members.Members[name]
 
 	if !ok {
@@ -31,3 +52,20 @@ func (members *ObjectMembers) Get(name string) (Object, bool) {
 
 	return val, ok
 }
+
+// Names returns the sorted names of the members, objects without members have none
+func (members *ObjectMembers) Names() []string {
+	if members == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(members.Members))
+
+	for name := range members.Members {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -1,12 +1,16 @@
 package object
 
+import "sort"
+
 type EnvironmentObject struct {
+	Name      string
 	IsMutable bool
 	Object    Object
 }
 
 type Environment struct {
 	store map[string]*EnvironmentObject
+	slots []*EnvironmentObject
 	outer *Environment
 }
 
@@ -37,11 +41,68 @@ func (e *Environment) Get(name string) (Object, bool) {
 	return envObj.Object, ok
 }
 
+// GetAt looks up a binding at the depth and slot the resolver gave it, the name guards against
+// environments that don't match the resolved scopes, in which case the caller should fall back to Get
+func (e *Environment) GetAt(depth int, slot int, name string) (Object, bool) {
+	env := e
+
+	for i := 0; i < depth && env != nil; i++ {
+		env = env.outer
+	}
+
+	if env == nil || slot >= len(env.slots) || env.slots[slot].Name != name {
+		return nil, false
+	}
+
+	return env.slots[slot].Object, true
+}
+
+// Set binds name in this environment, a name keeps its slot when it is bound again
 func (e *Environment) Set(name string, obj Object, isMutable bool) Object {
-	e.store[name] = &EnvironmentObject{isMutable, obj}
+	if envObj, ok := e.store[name]; ok {
+		envObj.IsMutable = isMutable
+		envObj.Object = obj
+
+		return obj
+	}
+
+	envObj := &EnvironmentObject{Name: name, IsMutable: isMutable, Object: obj}
+
+	e.store[name] = envObj
+	e.slots = append(e.slots, envObj)
+
 	return obj
 }
 
+// Bindings returns the bindings of this environment in the order they were declared, outer ones aren't included
+func (e *Environment) Bindings() []*EnvironmentObject {
+	return e.slots
+}
+
+// Outer returns the enclosing environment, nil for the global one
+func (e *Environment) Outer() *Environment {
+	return e.outer
+}
+
+// Names returns the sorted names visible from this environment, including the ones of outer environments
+func (e *Environment) Names() []string {
+	seen := make(map[string]bool)
+	names := []string{}
+
+	for env := e; env != nil; env = env.outer {
+		for _, envObj := range env.slots {
+			if !seen[envObj.Name] {
+				seen[envObj.Name] = true
+				names = append(names, envObj.Name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
 func (e *Environment) ReAssign(name string, obj Object) (Object, bool) {
 	envObj, ok := e.store[name]
 
@@ -50,7 +111,7 @@ func (e *Environment) ReAssign(name string, obj Object) (Object, bool) {
 			return &Error{Message: "ERROR: Can't reassign immutable object: " + name}, false
 		}
 
-		e.store[name] = &EnvironmentObject{true, obj}
+		envObj.Object = obj
 		return obj, true
 	}
 
@@ -8,22 +8,20 @@ import (
 )
 
 type Integer struct {
-	Value   int64
-	Members ObjectMembers
+	Value int64
 }
 
 func (i *Integer) Type() Type                 { return INTEGER }
 func (i *Integer) Inspect() string            { return fmt.Sprintf("%d", i.Value) }
-func (i *Integer) GetMembers() *ObjectMembers { return &i.Members }
+func (i *Integer) GetMembers() *ObjectMembers { return Methods(INTEGER) }
 
 type Boolean struct {
-	Value   bool
-	Members ObjectMembers
+	Value bool
 }
 
 func (b *Boolean) Type() Type                 { return BOOLEAN }
 func (b *Boolean) Inspect() string            { return fmt.Sprintf("%t", b.Value) }
-func (b *Boolean) GetMembers() *ObjectMembers { return &b.Members }
+func (b *Boolean) GetMembers() *ObjectMembers { return Methods(BOOLEAN) }
 
 type Null struct{}
 
@@ -32,17 +30,15 @@ func (n *Null) Inspect() string            { return "null" }
 func (n *Null) GetMembers() *ObjectMembers { return nil }
 
 type String struct {
-	Value   string
-	Members ObjectMembers
+	Value string
 }
 
 func (s *String) Type() Type                 { return STRING }
 func (s *String) Inspect() string            { return s.Value }
-func (s *String) GetMembers() *ObjectMembers { return &s.Members }
+func (s *String) GetMembers() *ObjectMembers { return Methods(STRING) }
 
 type Array struct {
-	Values  []Object
-	Members ObjectMembers
+	Values []Object
 }
 
 func (a *Array) Type() Type { return ARRAY }
@@ -61,9 +57,9 @@ func (a *Array) Inspect() string {
 
 	return out.String()
 }
-func (a *Array) GetMembers() *ObjectMembers { return &a.Members }
+func (a *Array) GetMembers() *ObjectMembers { return Methods(ARRAY) }
 func (a *Array) GetIndex(i int) Object {
-	if i >= len(a.Values) {
+	if i < 0 || i >= len(a.Values) {
 		return &Error{Message: "ERROR: index " + strconv.Itoa(i) + " out of range"}
 	}
 
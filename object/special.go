@@ -3,6 +3,7 @@ package object
 import (
 	"bytes"
 	"go++/ast"
+	"go++/token"
 	"strings"
 )
 
@@ -25,7 +26,13 @@ func (e *Error) Inspect() string            { return "ERROR: " + e.Message }
 func (e *Error) GetMembers() *ObjectMembers { return nil }
 
 type Function struct {
-	Parameters []*ast.Identifier
+	// Token is the fn keyword of the literal the function was created from
+	Token token.Token
+
+	// Name is empty for functions created from literals, declared functions carry the name they were declared with
+	Name       string
+	Parameters []*ast.Parameter
+	ReturnType *ast.TypeAnnotation
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
@@ -40,10 +47,19 @@ func (f *Function) Inspect() string {
 	}
 
 	out.WriteString("fn")
+
+	if f.Name != "" {
+		out.WriteString(" " + f.Name)
+	}
+
 	out.WriteString("(")
 	out.WriteString(strings.Join(params, ", "))
 	out.WriteString(")")
 
+	if f.ReturnType != nil {
+		out.WriteString(" -> " + f.ReturnType.String())
+	}
+
 	return out.String()
 }
 func (f *Function) GetMembers() *ObjectMembers { return nil }
@@ -58,17 +74,37 @@ func (b *Builtin) Type() Type                 { return BUILTIN }
 func (b *Builtin) Inspect() string            { return "builtin function" }
 func (b *Builtin) GetMembers() *ObjectMembers { return nil }
 
-type MethodFunction func(args ...Object) Object
+// MethodFunction is called with the value the method was accessed on and the arguments of the call
+type MethodFunction func(it Object, args ...Object) Object
 
+// BuiltinMethod is a method in the method table of a type, it is called through a BoundMethod
 type BuiltinMethod struct {
-	Fn MethodFunction
-	It Object
+	Name  string
+	Arity int
+	Fn    MethodFunction
 }
 
 func (b *BuiltinMethod) Type() Type                 { return METHOD }
 func (b *BuiltinMethod) Inspect() string            { return "method" }
 func (b *BuiltinMethod) GetMembers() *ObjectMembers { return nil }
 
+// BoundMethod is a method together with the value it was accessed on, a new one is made for every access
+// and its receiver never changes
+type BoundMethod struct {
+	method   *BuiltinMethod
+	receiver Object
+}
+
+func NewBoundMethod(method *BuiltinMethod, receiver Object) *BoundMethod {
+	return &BoundMethod{method: method, receiver: receiver}
+}
+
+func (b *BoundMethod) Type() Type                 { return METHOD }
+func (b *BoundMethod) Inspect() string            { return "method" }
+func (b *BoundMethod) GetMembers() *ObjectMembers { return nil }
+func (b *BoundMethod) Method() *BuiltinMethod     { return b.method }
+func (b *BoundMethod) Receiver() Object           { return b.receiver }
+
 type MethodIteratorSetupFunction func(args ...Object) ([]Integer, []Object, Object)
 
 type BuiltinIteratorMethod struct {
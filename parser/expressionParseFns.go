@@ -1,10 +1,12 @@
 package parser
 
 import (
-	"fmt"
+	"errors"
 	"go++/ast"
+	lex "go++/lexer"
 	"go++/token"
 	"strconv"
+	"strings"
 )
 
 func (parser *Parser) registerPrefix(tokenType token.Type, fn prefixParseFn) {
@@ -22,17 +24,79 @@ func (parser *Parser) parseIdentifier() ast.Expression {
 func (parser *Parser) parseIntegerLiteral() ast.Expression {
 	literal := &ast.IntegerLiteral{Token: parser.currentToken}
 
-	value, err := strconv.ParseInt(parser.currentToken.Literal, 0, 64)
+	value, err := integerValue(parser.currentToken.Literal)
+
+	// The literal without a value is returned anyway so the expression around it doesn't report errors of its own
+	switch {
+	case errors.Is(err, errNotWhole):
+		parser.appendError(parser.currentToken, "%s is not a whole number, there are only integers", parser.currentToken.Literal)
+	case errors.Is(err, strconv.ErrRange):
+		parser.appendError(parser.currentToken, "%s is out of range, integers are 64-bit", parser.currentToken.Literal)
+	case err != nil:
+		parser.appendError(parser.currentToken, "could not parse %q as integer", parser.currentToken.Literal)
+	default:
+		literal.Value = value
+	}
 
-	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as integer", parser.currentToken.Literal)
-		parser.errors = append(parser.errors, msg)
-		return nil
+	return literal
+}
+
+// parseIllegal parses a token the lexer already reported. A malformed number still stands in for an integer
+// like a number out of range does
+func (parser *Parser) parseIllegal() ast.Expression {
+	if literal := parser.currentToken.Literal; literal != "" && lex.IsDigit(rune(literal[0])) {
+		return &ast.IntegerLiteral{Token: parser.currentToken}
 	}
 
-	literal.Value = value
+	return nil
+}
+
+var errNotWhole = errors.New("not a whole number")
 
-	return literal
+// integerValue returns the value of a number the lexer accepted. A decimal with a fraction or an exponent is
+// fine as long as it's whole, 1.5e3 is 1500
+func integerValue(literal string) (int64, error) {
+	digits := strings.ReplaceAll(literal, "_", "")
+
+	if len(digits) > 1 && digits[0] == '0' && strings.ContainsRune("xXoObB", rune(digits[1])) || !strings.ContainsAny(digits, ".eE") {
+		return strconv.ParseInt(digits, 0, 64)
+	}
+
+	mantissa, exponent := digits, 0
+
+	if i := strings.IndexAny(digits, "eE"); i >= 0 {
+		value, err := strconv.Atoi(digits[i+1:])
+
+		if err != nil {
+			return 0, err
+		}
+
+		mantissa, exponent = digits[:i], value
+	}
+
+	integer, fraction, _ := strings.Cut(mantissa, ".")
+	digits = strings.TrimLeft(integer+fraction, "0")
+	exponent -= len(fraction)
+
+	switch {
+	case digits == "":
+		return 0, nil
+	case exponent < 0:
+		if -exponent > len(digits) || strings.Trim(digits[len(digits)+exponent:], "0") != "" {
+			return 0, errNotWhole
+		}
+
+		digits = digits[:len(digits)+exponent]
+	case exponent > 0:
+		// Anything longer than 19 digits is out of range, checking first keeps 1e999999999 from taking all memory
+		if len(digits)+exponent > 19 {
+			return 0, strconv.ErrRange
+		}
+
+		digits += strings.Repeat("0", exponent)
+	}
+
+	return strconv.ParseInt(digits, 10, 64)
 }
 
 func (parser *Parser) parseBoolean() ast.Expression {
@@ -41,6 +105,10 @@ func (parser *Parser) parseBoolean() ast.Expression {
 	return literal
 }
 
+func (parser *Parser) parseNullLiteral() ast.Expression {
+	return &ast.NullLiteral{Token: parser.currentToken}
+}
+
 func (parser *Parser) parsePrefixExpression() ast.Expression {
 	expression := &ast.PrefixExpression{
 		Token:    parser.currentToken,
@@ -109,28 +177,125 @@ func (parser *Parser) parseIfExpression() ast.Expression {
 func (parser *Parser) parseFunctionLiteral() ast.Expression {
 	literal := &ast.FunctionLiteral{Token: parser.currentToken}
 
-	if !parser.expectPeek(token.LPAREN) {
+	if !parser.expectPeek(token.LPAREN) || !parser.parseFunction(literal) {
 		return nil
 	}
 
+	return literal
+}
+
+// parseFunction parses the parameters, the return type and the body of a function, starting at the (
+func (parser *Parser) parseFunction(literal *ast.FunctionLiteral) bool {
 	literal.Parameters = parser.parseFunctionParameters()
 
+	if parser.peekTokenIs(token.ARROW) {
+		parser.nextToken()
+
+		if literal.ReturnType = parser.parseTypeAnnotation(); literal.ReturnType == nil {
+			return false
+		}
+	}
+
 	if !parser.expectPeek(token.LBRACE) {
-		return nil
+		return false
 	}
 
 	literal.Body = parser.parseBlockStatement()
 
-	return literal
+	return true
 }
 
 func (parser *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	expression := &ast.CallExpression{Token: parser.currentToken, Function: function}
-	expression.Arguments = parser.parseCallArguments(token.RPAREN)
+	expression.Arguments = parser.parseArguments()
+	expression.EndToken = parser.currentToken
 
 	return expression
 }
 
+// parseArguments parses the arguments of a call, every one is an expression, `...array` or `name: value`
+// and named arguments come last
+func (parser *Parser) parseArguments() []ast.Expression {
+	args := []ast.Expression{}
+
+	if parser.peekTokenIs(token.RPAREN) {
+		parser.nextToken()
+		return args
+	}
+
+	named := false
+	misplaced := false
+
+	// The lexer reports an illegal token as soon as it's the next one, so the errors of an argument are
+	// counted from before the comma in front of it
+	errorCount := len(parser.errors)
+
+	for {
+		parser.nextToken()
+
+		argument := parser.parseArgument()
+
+		if argument == nil {
+			return nil
+		}
+
+		// The arguments after a misplaced one are still parsed so the closing ) doesn't report an error of its own
+		if _, ok := argument.(*ast.NamedArgument); ok {
+			named = true
+		} else if named {
+			misplaced = true
+
+			// An argument with errors may be missing nodes that String needs
+			if len(parser.errors) == errorCount {
+				parser.appendError(ast.StartToken(argument), "positional argument %s follows named arguments", argument.String())
+			}
+		}
+
+		args = append(args, argument)
+
+		if !parser.peekTokenIs(token.COMMA) {
+			break
+		}
+
+		errorCount = len(parser.errors)
+		parser.nextToken()
+	}
+
+	if !parser.expectPeek(token.RPAREN) || misplaced {
+		return nil
+	}
+
+	return args
+}
+
+func (parser *Parser) parseArgument() ast.Expression {
+	switch {
+	case parser.currentTokenIs(token.ELLIPSIS):
+		spread := &ast.SpreadExpression{Token: parser.currentToken}
+
+		parser.nextToken()
+
+		if spread.Value = parser.parseExpression(LOWEST); spread.Value == nil {
+			return nil
+		}
+
+		return spread
+	case parser.currentTokenIs(token.IDENTIFIER) && parser.peekTokenIs(token.COLON):
+		argument := &ast.NamedArgument{Token: parser.currentToken, Name: &ast.Identifier{Token: parser.currentToken, Value: parser.currentToken.Literal}}
+
+		parser.nextToken()
+		parser.nextToken()
+
+		if argument.Value = parser.parseExpression(LOWEST); argument.Value == nil {
+			return nil
+		}
+
+		return argument
+	}
+
+	return parser.parseExpression(LOWEST)
+}
+
 // expr <dot> expr() <dot> expr()
 func (parser *Parser) parseMemberAccessExpression(expr ast.Expression) ast.Expression {
 	expression := &ast.MemberAccessExpression{Token: parser.currentToken, Expression: expr}
@@ -144,6 +309,35 @@ func (parser *Parser) parseMemberAccessExpression(expr ast.Expression) ast.Expre
 	return expression
 }
 
+// expr ?. member or expr ?.[index]
+func (parser *Parser) parseOptionalChain(expr ast.Expression) ast.Expression {
+	if parser.peekTokenIs(token.LBRACKET) {
+		parser.nextToken()
+
+		expression := parser.parseArrayAccess(expr)
+
+		if expression == nil {
+			return nil
+		}
+
+		access := expression.(*ast.ArrayAccessExpression)
+		access.IsOptional = true
+
+		return access
+	}
+
+	expression := parser.parseMemberAccessExpression(expr)
+
+	if expression == nil {
+		return nil
+	}
+
+	access := expression.(*ast.MemberAccessExpression)
+	access.IsOptional = true
+
+	return access
+}
+
 func (parser *Parser) parseCallArguments(endingToken token.Type) []ast.Expression {
 	args := []ast.Expression{}
 
@@ -169,7 +363,48 @@ func (parser *Parser) parseCallArguments(endingToken token.Type) []ast.Expressio
 }
 
 func (parser *Parser) parseStringLiteral() ast.Expression {
-	return &ast.StringLiteral{Token: parser.currentToken, Value: parser.currentToken.Literal}
+	return &ast.StringLiteral{Token: parser.currentToken, Value: parser.currentToken.Literal, IsRaw: parser.currentTokenIs(token.RAWSTRING)}
+}
+
+// "text ${expression} text", the lexer ends every part of the string that is followed by an expression with
+// an INTERPOLATION and the last part with an INTERPOLATIONEND
+func (parser *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: parser.currentToken}
+
+	for {
+		str.Strings = append(str.Strings, &ast.StringLiteral{Token: parser.currentToken, Value: parser.currentToken.Literal})
+
+		if parser.currentTokenIs(token.INTERPOLATIONEND) {
+			return str
+		}
+
+		// An empty ${} is dropped with the part in front of it, so the string still ends at its
+		// INTERPOLATIONEND and the error is only reported once
+		if parser.peekTokenIs(token.INTERPOLATIONEND) {
+			parser.appendError(parser.currentToken, "expected an expression in ${}")
+			str.Strings = str.Strings[:len(str.Strings)-1]
+			parser.nextToken()
+
+			continue
+		}
+
+		parser.nextToken()
+
+		expression := parser.parseExpression(LOWEST)
+
+		if expression == nil {
+			return nil
+		}
+
+		str.Expressions = append(str.Expressions, expression)
+
+		if !parser.peekTokenIs(token.INTERPOLATION) && !parser.peekTokenIs(token.INTERPOLATIONEND) {
+			parser.appendError(parser.peekToken, "expected } to close the expression in ${}, got %s instead", parser.peekToken.Type)
+			return nil
+		}
+
+		parser.nextToken()
+	}
 }
 
 func (parser *Parser) parseAssignExpression(exp ast.Expression) ast.Expression {
@@ -177,6 +412,10 @@ func (parser *Parser) parseAssignExpression(exp ast.Expression) ast.Expression {
 
 	expression.Assignee = exp
 
+	if isOptionalChain(exp) {
+		parser.appendError(expression.Token, "cannot assign to an optional chain, the value in front of ?. may be null")
+	}
+
 	parser.nextToken()
 
 	expression.Value = parser.parseExpression(LOWEST)
@@ -184,9 +423,24 @@ func (parser *Parser) parseAssignExpression(exp ast.Expression) ast.Expression {
 	return expression
 }
 
+// isOptionalChain reports whether a ?. comes before the end of a chain of member accesses, indexes and calls
+func isOptionalChain(expression ast.Expression) bool {
+	switch expression := expression.(type) {
+	case *ast.MemberAccessExpression:
+		return expression.IsOptional || isOptionalChain(expression.Expression)
+	case *ast.ArrayAccessExpression:
+		return expression.IsOptional || isOptionalChain(expression.Expression)
+	case *ast.CallExpression:
+		return isOptionalChain(expression.Function)
+	}
+
+	return false
+}
+
 func (parser *Parser) parseArray() ast.Expression {
 	arr := &ast.ArrayLiteral{Token: parser.currentToken}
 	arr.Values = parser.parseCallArguments(token.RBRACKET)
+	arr.EndToken = parser.currentToken
 
 	return arr
 }
@@ -202,7 +456,9 @@ func (parser *Parser) parseArrayAccess(array ast.Expression) ast.Expression {
 
 	expr.Index = parser.parseExpression(LOWEST)
 
-	parser.nextToken()
+	if !parser.expectPeek(token.RBRACKET) {
+		return nil
+	}
 
 	return expr
 }
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	lex "go++/lexer"
+	"go++/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Inputs of the parser tests, they seed the fuzzer with the programs in testPrograms
+var seeds = []string{
+	"let x = 5",
+	"let mut x = 5",
+	"return 10944",
+	"!-15; -a * b",
+	"5 + 5 * 2 == 15 != false",
+	"if x < y { x } else { y }",
+	"fn (x, y) { x + y; }",
+	"let f: fn = fn (a: int, b) -> bool { a }",
+	"fn (a: [[int]]) -> [int] { a }",
+	"add(1, 2 * 3, 4 + 5)",
+	"a = 5 * 5",
+	"let mut i = 0 for i < 10 { i = i + 1 }",
+	`"hello".length().add(1)`,
+	"let array = [2, 4, 5*5] array[4*2]",
+	"let = 5",
+	"fn (a: ) {",
+	"0xFF + 1_000 * 1.5e3 - 0b1 + 1e-3 + 1e999999999999999999999",
+	"let n: null = null n?.x?.[0](1) ?? a?.b = 2",
+}
+
+func addSeeds(f *testing.F) {
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	files, _ := filepath.Glob("../testPrograms/*.gopp")
+
+	for _, file := range files {
+		if src, err := os.ReadFile(file); err == nil {
+			f.Add(string(src))
+		}
+	}
+}
+
+func FuzzParseProgram(f *testing.F) {
+	addSeeds(f)
+
+	f.Fuzz(func(t *testing.T, input string) {
+		pars := New(lex.New(input))
+		program := pars.ParseProgram()
+
+		if program == nil {
+			t.Fatalf("ParseProgram returned nil for %q", input)
+		}
+
+		// The tree of a program with errors may be missing nodes
+		if len(pars.Errors()) == 0 {
+			_ = program.String()
+
+			if tok, ok := unmatchedBracket(input); ok {
+				t.Fatalf("%q parsed without errors but its %s at %d:%d isn't matched", input, tok.Literal, tok.Line, tok.Column)
+			}
+		}
+	})
+}
+
+var closingBrackets = map[token.Type]token.Type{token.LPAREN: token.RPAREN, token.LBRACKET: token.RBRACKET, token.LBRACE: token.RBRACE}
+
+// unmatchedBracket returns the first bracket in the tokens of input that isn't closed by the matching kind
+func unmatchedBracket(input string) (token.Token, bool) {
+	lexer := lex.New(input)
+	open := []token.Token{}
+
+	for tok := lexer.NextToken(); tok.Type != token.EOF; tok = lexer.NextToken() {
+		switch tok.Type {
+		case token.LPAREN, token.LBRACKET, token.LBRACE:
+			open = append(open, tok)
+		case token.RPAREN, token.RBRACKET, token.RBRACE:
+			if len(open) == 0 || closingBrackets[open[len(open)-1].Type] != tok.Type {
+				return tok, true
+			}
+
+			open = open[:len(open)-1]
+		}
+	}
+
+	if len(open) > 0 {
+		return open[len(open)-1], true
+	}
+
+	return token.Token{}, false
+}
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"fmt"
 	"go++/ast"
 	lex "go++/lexer"
 	"go++/token"
@@ -14,6 +15,7 @@ type (
 const (
 	_int = iota
 	LOWEST
+	COALESCE
 	EQUALS
 	LESSGREATER
 	SUM
@@ -24,18 +26,20 @@ const (
 )
 
 var precedences = map[token.Type]int{
-	token.ASSIGN:      EQUALS,
-	token.EQUALS:      EQUALS,
-	token.NOTEQUALS:   EQUALS,
-	token.LESSTHAN:    LESSGREATER,
-	token.GREATERTHAN: LESSGREATER,
-	token.PLUS:        SUM,
-	token.MINUS:       SUM,
-	token.SLASH:       PRODUCT,
-	token.ASTERISK:    PRODUCT,
-	token.LPAREN:      CALL,
-	token.DOT:         MEMBERACCESS,
-	token.LBRACKET:    MEMBERACCESS,
+	token.ASSIGN:        EQUALS,
+	token.COALESCE:      COALESCE,
+	token.EQUALS:        EQUALS,
+	token.NOTEQUALS:     EQUALS,
+	token.LESSTHAN:      LESSGREATER,
+	token.GREATERTHAN:   LESSGREATER,
+	token.PLUS:          SUM,
+	token.MINUS:         SUM,
+	token.SLASH:         PRODUCT,
+	token.ASTERISK:      PRODUCT,
+	token.LPAREN:        CALL,
+	token.DOT:           MEMBERACCESS,
+	token.OPTIONALCHAIN: MEMBERACCESS,
+	token.LBRACKET:      MEMBERACCESS,
 }
 
 type Parser struct {
@@ -47,13 +51,26 @@ type Parser struct {
 	prefixParseFns map[token.Type]prefixParseFn
 	infixParseFns  map[token.Type]infixParseFn
 
-	errors []string
+	errors []Error
+
+	// lexerErrors is how many of the lexer's errors were added to errors
+	lexerErrors int
+}
+
+// Error is a syntax error at the token the parser didn't expect
+type Error struct {
+	Token   token.Token
+	Message string
+}
+
+func (e Error) String() string {
+	return fmt.Sprintf("%d:%d: %s", e.Token.Line, e.Token.Column, e.Message)
 }
 
 func New(lexer *lex.Lexer) *Parser {
 	parser := &Parser{
 		lexer:  lexer,
-		errors: []string{},
+		errors: []Error{},
 	}
 
 	parser.nextToken()
@@ -62,8 +79,10 @@ func New(lexer *lex.Lexer) *Parser {
 	parser.prefixParseFns = make(map[token.Type]prefixParseFn)
 	parser.registerPrefix(token.IDENTIFIER, parser.parseIdentifier)
 	parser.registerPrefix(token.INTEGER, parser.parseIntegerLiteral)
+	parser.registerPrefix(token.ILLEGAL, parser.parseIllegal)
 	parser.registerPrefix(token.TRUE, parser.parseBoolean)
 	parser.registerPrefix(token.FALSE, parser.parseBoolean)
+	parser.registerPrefix(token.NULL, parser.parseNullLiteral)
 	parser.registerPrefix(token.NOT, parser.parsePrefixExpression)
 	parser.registerPrefix(token.MINUS, parser.parsePrefixExpression)
 
@@ -74,6 +93,8 @@ func New(lexer *lex.Lexer) *Parser {
 	parser.registerPrefix(token.FUNCTION, parser.parseFunctionLiteral)
 
 	parser.registerPrefix(token.STRING, parser.parseStringLiteral)
+	parser.registerPrefix(token.RAWSTRING, parser.parseStringLiteral)
+	parser.registerPrefix(token.INTERPOLATION, parser.parseInterpolatedString)
 
 	parser.infixParseFns = make(map[token.Type]infixParseFn)
 	parser.registerInfix(token.PLUS, parser.parseInfixExpression)
@@ -84,16 +105,29 @@ func New(lexer *lex.Lexer) *Parser {
 	parser.registerInfix(token.NOTEQUALS, parser.parseInfixExpression)
 	parser.registerInfix(token.LESSTHAN, parser.parseInfixExpression)
 	parser.registerInfix(token.GREATERTHAN, parser.parseInfixExpression)
+	parser.registerInfix(token.COALESCE, parser.parseInfixExpression)
 
 	parser.registerInfix(token.LPAREN, parser.parseCallExpression)
 	parser.registerInfix(token.LBRACKET, parser.parseArrayAccess)
 	parser.registerInfix(token.DOT, parser.parseMemberAccessExpression)
+	parser.registerInfix(token.OPTIONALCHAIN, parser.parseOptionalChain)
 	parser.registerInfix(token.ASSIGN, parser.parseAssignExpression)
 
 	return parser
 }
 
+// Errors returns every syntax error prefixed with its position
 func (parser *Parser) Errors() []string {
+	messages := make([]string, len(parser.errors))
+
+	for i, err := range parser.errors {
+		messages[i] = err.String()
+	}
+
+	return messages
+}
+
+func (parser *Parser) ErrorDetails() []Error {
 	return parser.errors
 }
 
@@ -117,11 +151,30 @@ func (parser *Parser) ParseProgram() *ast.Program {
 func (parser *Parser) parseStatement() ast.Statement {
 	switch parser.currentToken.Type {
 	case token.LET:
-		return parser.parseLetStatement()
+		// A nil *ast.LetStatement would be a statement that isn't nil
+		if stmt := parser.parseLetStatement(); stmt != nil {
+			return stmt
+		}
+
+		return nil
+	case token.FUNCTION:
+		if !parser.peekTokenIs(token.IDENTIFIER) {
+			return parser.parseExpressionStatement()
+		}
+
+		if stmt := parser.parseFunctionStatement(); stmt != nil {
+			return stmt
+		}
+
+		return nil
 	case token.RETURN:
 		return parser.parseReturnStatement()
 	case token.FOR:
-		return parser.parseForLoopLiteral()
+		if stmt := parser.parseForLoopLiteral(); stmt != nil {
+			return stmt
+		}
+
+		return nil
 	default:
 		return parser.parseExpressionStatement()
 	}
@@ -135,11 +188,27 @@ func (parser *Parser) parseLetStatement() *ast.LetStatement {
 		parser.nextToken()
 	}
 
-	if !parser.expectPeek(token.IDENTIFIER) {
-		return nil
+	if parser.peekTokenIs(token.LBRACKET) || parser.peekTokenIs(token.LBRACE) {
+		parser.nextToken()
+
+		if stmt.Pattern = parser.parseArrayPattern(); stmt.Pattern == nil {
+			return nil
+		}
+	} else {
+		if !parser.expectPeek(token.IDENTIFIER) {
+			return nil
+		}
+
+		stmt.Name = &ast.Identifier{Token: parser.currentToken, Value: parser.currentToken.Literal}
 	}
 
-	stmt.Name = &ast.Identifier{Token: parser.currentToken, Value: parser.currentToken.Literal}
+	if parser.peekTokenIs(token.COLON) {
+		parser.nextToken()
+
+		if stmt.Type = parser.parseTypeAnnotation(); stmt.Type == nil {
+			return nil
+		}
+	}
 
 	if !parser.expectPeek(token.ASSIGN) {
 		return nil
@@ -149,6 +218,28 @@ func (parser *Parser) parseLetStatement() *ast.LetStatement {
 
 	stmt.Value = parser.parseExpression(LOWEST)
 
+	if parser.peekTokenIs(token.SEMICOLON) {
+		parser.nextToken()
+	}
+
+	return stmt
+}
+
+// fn name(params) [-> type] { body }
+func (parser *Parser) parseFunctionStatement() *ast.FunctionStatement {
+	stmt := &ast.FunctionStatement{Token: parser.currentToken}
+	literal := &ast.FunctionLiteral{Token: parser.currentToken}
+
+	parser.nextToken()
+
+	literal.Name = &ast.Identifier{Token: parser.currentToken, Value: parser.currentToken.Literal}
+
+	if !parser.expectPeek(token.LPAREN) || !parser.parseFunction(literal) {
+		return nil
+	}
+
+	stmt.Function = literal
+
 	return stmt
 }
 
@@ -159,23 +250,160 @@ func (parser *Parser) parseReturnStatement() *ast.ReturnStatement {
 
 	stmt.ReturnValue = parser.parseExpression(LOWEST)
 
+	if parser.peekTokenIs(token.SEMICOLON) {
+		parser.nextToken()
+	}
+
 	return stmt
 }
 
 func (parser *Parser) parseForLoopLiteral() *ast.ExpressionStatement {
-	stmt := &ast.ForLoopLiteral{Token: parser.currentToken}
+	forToken := parser.currentToken
 
 	parser.nextToken()
 
+	if parser.currentTokenIs(token.LBRACKET) || parser.currentTokenIs(token.LBRACE) || parser.currentTokenIs(token.IDENTIFIER) && parser.peekTokenIs(token.IN) {
+		return parser.parseForInLoopLiteral(forToken)
+	}
+
+	stmt := &ast.ForLoopLiteral{Token: forToken}
+
 	stmt.Condition = parser.parseExpression(LOWEST)
 
+	if !parser.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = parser.parseBlockStatement()
+
+	return &ast.ExpressionStatement{Token: stmt.Token, Expression: stmt}
+}
+
+// for pattern in iterable { body }, expects the current token to be the start of the pattern
+func (parser *Parser) parseForInLoopLiteral(forToken token.Token) *ast.ExpressionStatement {
+	stmt := &ast.ForInLoopLiteral{Token: forToken}
+
+	if stmt.Pattern = parser.parsePattern(); stmt.Pattern == nil {
+		return nil
+	}
+
+	if !parser.expectPeek(token.IN) {
+		return nil
+	}
+
 	parser.nextToken()
 
+	if stmt.Iterable = parser.parseExpression(LOWEST); stmt.Iterable == nil {
+		return nil
+	}
+
+	if !parser.expectPeek(token.LBRACE) {
+		return nil
+	}
+
 	stmt.Body = parser.parseBlockStatement()
 
 	return &ast.ExpressionStatement{Token: stmt.Token, Expression: stmt}
 }
 
+// name or [pattern, ...], expects the current token to be the start of the pattern
+func (parser *Parser) parsePattern() ast.Pattern {
+	switch parser.currentToken.Type {
+	case token.IDENTIFIER:
+		return &ast.Identifier{Token: parser.currentToken, Value: parser.currentToken.Literal}
+	case token.LBRACKET, token.LBRACE:
+		// A nil *ast.ArrayPattern would be a pattern that isn't nil
+		if pattern := parser.parseArrayPattern(); pattern != nil {
+			return pattern
+		}
+
+		return nil
+	}
+
+	parser.appendError(parser.currentToken, "expected a name or a pattern, got %s instead", parser.currentToken.Type)
+
+	return nil
+}
+
+// [pattern, ..., ...rest]
+func (parser *Parser) parseArrayPattern() *ast.ArrayPattern {
+	// There are no hashes or structs to take apart with {x, y}. The braces are skipped and stand in for an
+	// empty pattern so the rest of the statement parses without errors that only follow from this one
+	if parser.currentTokenIs(token.LBRACE) {
+		brace := parser.currentToken
+		parser.appendError(brace, "only arrays can be destructured, there are no object patterns")
+
+		for depth := 1; depth > 0; {
+			if parser.peekTokenIs(token.EOF) {
+				return nil
+			}
+
+			parser.nextToken()
+
+			if parser.currentTokenIs(token.LBRACE) {
+				depth += 1
+			} else if parser.currentTokenIs(token.RBRACE) {
+				depth -= 1
+			}
+		}
+
+		return &ast.ArrayPattern{Token: brace, Elements: []ast.Pattern{}}
+	}
+
+	pattern := &ast.ArrayPattern{Token: parser.currentToken, Elements: []ast.Pattern{}}
+
+	if parser.peekTokenIs(token.RBRACKET) {
+		parser.nextToken()
+		return pattern
+	}
+
+	// Every comma is followed by another element, array literals don't take a trailing comma either
+	for {
+		parser.nextToken()
+
+		if parser.currentTokenIs(token.ELLIPSIS) {
+			if !parser.expectPeek(token.IDENTIFIER) {
+				return nil
+			}
+
+			pattern.Rest = &ast.Identifier{Token: parser.currentToken, Value: parser.currentToken.Literal}
+
+			if !parser.peekTokenIs(token.RBRACKET) {
+				parser.appendError(pattern.Rest.Token, "rest element %s must be the last element", pattern.Rest.Value)
+				return nil
+			}
+
+			break
+		}
+
+		element := parser.parsePattern()
+
+		if element == nil {
+			return nil
+		}
+
+		pattern.Elements = append(pattern.Elements, element)
+
+		if !parser.peekTokenIs(token.COMMA) {
+			break
+		}
+
+		parser.nextToken()
+
+		// The pattern is still complete, so a trailing comma is the only error
+		if parser.peekTokenIs(token.RBRACKET) {
+			parser.appendError(parser.peekToken, "expected a name or a pattern, got ] instead")
+			break
+		}
+	}
+
+	if !parser.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return pattern
+}
+
 func (parser *Parser) parseExpressionStatement() *ast.ExpressionStatement {
 	stmt := &ast.ExpressionStatement{Token: parser.currentToken}
 
@@ -198,6 +426,11 @@ func (parser *Parser) parseExpression(precedence int) ast.Expression {
 
 	leftExp := prefix()
 
+	// An illegal token stands for nothing, the operators after it have nothing on their left
+	if leftExp == nil {
+		return nil
+	}
+
 	for !parser.peekTokenIs(token.SEMICOLON) && precedence < parser.peekPrecedence() {
 		infix := parser.infixParseFns[parser.peekToken.Type]
 		if infix == nil {
@@ -213,7 +446,7 @@ func (parser *Parser) parseExpression(precedence int) ast.Expression {
 }
 
 func (parser *Parser) noPrefixParseFnError(t token.Type) {
-	parser.appendError("no prefix parse function for %s found", t)
+	parser.appendError(parser.currentToken, "no prefix parse function for %s found", t)
 }
 
 func (parser *Parser) parseBlockStatement() *ast.BlockStatement {
@@ -232,33 +465,158 @@ func (parser *Parser) parseBlockStatement() *ast.BlockStatement {
 		parser.nextToken()
 	}
 
+	if parser.currentTokenIs(token.EOF) {
+		parser.appendError(parser.currentToken, "expected } to close the block opened at %d:%d, got EOF", block.Token.Line, block.Token.Column)
+	}
+
+	block.EndToken = parser.currentToken
+
 	return block
 }
 
-func (parser *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+func (parser *Parser) parseFunctionParameters() []*ast.Parameter {
+	parameters := []*ast.Parameter{}
 
 	if parser.peekTokenIs(token.RPAREN) {
 		parser.nextToken()
-		return identifiers
+		return parameters
 	}
 
 	parser.nextToken()
 
-	ident := &ast.Identifier{Token: parser.currentToken, Value: parser.currentToken.Literal}
-	identifiers = append(identifiers, ident)
+	parameter := parser.parseFunctionParameter()
+
+	if parameter == nil {
+		return nil
+	}
+
+	parameters = append(parameters, parameter)
+	names := make(map[string]bool)
+	parser.declareParameter(parameter, names)
 
 	for parser.peekTokenIs(token.COMMA) {
 		parser.nextToken()
 		parser.nextToken()
 
-		ident := &ast.Identifier{Token: parser.currentToken, Value: parser.currentToken.Literal}
-		identifiers = append(identifiers, ident)
+		parameter := parser.parseFunctionParameter()
+
+		if parameter == nil {
+			return nil
+		}
+
+		parser.declareParameter(parameter, names)
+
+		previous := parameters[len(parameters)-1]
+
+		if previous.IsRest {
+			parser.appendError(previous.Name.Token, "rest parameter %s must be the last parameter", previous.Name.Value)
+			return nil
+		}
+
+		if previous.Default != nil && parameter.Default == nil && !parameter.IsRest {
+			parser.appendError(ast.StartToken(parameter.Target()), "parameter %s without a default follows parameters with defaults", parameter.Target())
+			return nil
+		}
+
+		parameters = append(parameters, parameter)
 	}
 
 	if !parser.expectPeek(token.RPAREN) {
 		return nil
 	}
 
-	return identifiers
+	return parameters
+}
+
+// declareParameter adds the names the parameter binds to names, every parameter needs a name of its own
+func (parser *Parser) declareParameter(parameter *ast.Parameter, names map[string]bool) {
+	for _, identifier := range ast.PatternIdentifiers(parameter.Target()) {
+		if names[identifier.Value] {
+			parser.appendError(identifier.Token, "duplicate parameter %s", identifier.Value)
+		}
+
+		names[identifier.Value] = true
+	}
+}
+
+// [...]name [: type] [= default] or [pattern, ...] [: type] [= default]
+func (parser *Parser) parseFunctionParameter() *ast.Parameter {
+	parameter := &ast.Parameter{}
+
+	switch {
+	case parser.currentTokenIs(token.ELLIPSIS):
+		parameter.IsRest = true
+
+		if !parser.expectPeek(token.IDENTIFIER) {
+			return nil
+		}
+
+		parameter.Name = &ast.Identifier{Token: parser.currentToken, Value: parser.currentToken.Literal}
+	case parser.currentTokenIs(token.IDENTIFIER):
+		parameter.Name = &ast.Identifier{Token: parser.currentToken, Value: parser.currentToken.Literal}
+	case parser.currentTokenIs(token.LBRACKET) || parser.currentTokenIs(token.LBRACE):
+		if parameter.Pattern = parser.parseArrayPattern(); parameter.Pattern == nil {
+			return nil
+		}
+	default:
+		parser.appendError(parser.currentToken, "expected a parameter name, got %s instead", parser.currentToken.Type)
+		return nil
+	}
+
+	if parser.peekTokenIs(token.COLON) {
+		parser.nextToken()
+
+		if parameter.Type = parser.parseTypeAnnotation(); parameter.Type == nil {
+			return nil
+		}
+	}
+
+	if parser.peekTokenIs(token.ASSIGN) {
+		parser.nextToken()
+
+		if parameter.IsRest {
+			parser.appendError(parser.currentToken, "rest parameter %s can't have a default", parameter.Name.Value)
+			return nil
+		}
+
+		parser.nextToken()
+
+		if parameter.Default = parser.parseExpression(LOWEST); parameter.Default == nil {
+			return nil
+		}
+	}
+
+	return parameter
+}
+
+// Expects the current token to precede the type, `name` or `[type]`
+func (parser *Parser) parseTypeAnnotation() *ast.TypeAnnotation {
+	if parser.peekTokenIs(token.LBRACKET) {
+		parser.nextToken()
+
+		annotation := &ast.TypeAnnotation{Token: parser.currentToken, Name: "array"}
+
+		if annotation.Element = parser.parseTypeAnnotation(); annotation.Element == nil {
+			return nil
+		}
+
+		if !parser.expectPeek(token.RBRACKET) {
+			return nil
+		}
+
+		return annotation
+	}
+
+	// fn and null are keywords so they can't be lexed as identifiers
+	if parser.peekTokenIs(token.FUNCTION) || parser.peekTokenIs(token.NULL) {
+		parser.nextToken()
+
+		return &ast.TypeAnnotation{Token: parser.currentToken, Name: parser.currentToken.Literal}
+	}
+
+	if !parser.expectPeek(token.IDENTIFIER) {
+		return nil
+	}
+
+	return &ast.TypeAnnotation{Token: parser.currentToken, Name: parser.currentToken.Literal}
 }
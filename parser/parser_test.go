@@ -18,6 +18,7 @@ func TestLetStatements(t *testing.T) {
 		{"let mut x = 5", "x", true, 5},
 		{"let y = true", "y", false, true},
 		{"let foobar = y", "foobar", false, "y"},
+		{"let z = 1;", "z", false, 1},
 	}
 
 	for _, tt := range tests {
@@ -196,6 +197,72 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xFF", 255},
+		{"0o17", 15},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"1e9", 1000000000},
+		{"1.5e3", 1500},
+		{"1500e-2", 15},
+		{"2.0", 2},
+		{"0e99", 0},
+		{"9223372036854775807", 9223372036854775807},
+		{"0x7fff_ffff_ffff_ffff", 9223372036854775807},
+	}
+
+	for _, tt := range tests {
+		parser := New(lex.New(tt.input))
+		program := parser.ParseProgram()
+		checkParserErrors(t, parser)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+
+		if literal.Value != tt.expected || literal.TokenLiteral() != tt.input {
+			t.Errorf("wrong literal for %s. expected=%d, got=%d (%s)", tt.input, tt.expected, literal.Value, literal.TokenLiteral())
+		}
+	}
+}
+
+func TestInvalidNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 0.5", []string{"1:9: 0.5 is not a whole number, there are only integers"}},
+		{"let x = 1e-3", []string{"1:9: 1e-3 is not a whole number, there are only integers"}},
+		{"let x = 9223372036854775808", []string{"1:9: 9223372036854775808 is out of range, integers are 64-bit"}},
+		{"let x = 1e19", []string{"1:9: 1e19 is out of range, integers are 64-bit"}},
+		{"let x = 1e999999999999999999999", []string{"1:9: 1e999999999999999999999 is out of range, integers are 64-bit"}},
+		{"let x = 0x1_0000_0000_0000_0000", []string{"1:9: 0x1_0000_0000_0000_0000 is out of range, integers are 64-bit"}},
+		{"let x = 5 +\n  0b12", []string{"2:3: invalid digit '2' in binary number 0b12"}},
+		{"println(0x)", []string{"1:9: hexadecimal number 0x has no digits"}},
+		{"println(9223372036854775808)", []string{"1:9: 9223372036854775808 is out of range, integers are 64-bit"}},
+		{"println(0.5 + 1, 1_)", []string{"1:9: 0.5 is not a whole number, there are only integers", "1:18: _ must separate successive digits in 1_"}},
+		{"f(x: 1, 2, 3)", []string{"1:9: positional argument 2 follows named arguments", "1:12: positional argument 3 follows named arguments"}},
+		{"f(x: 1, 0.5)", []string{"1:9: 0.5 is not a whole number, there are only integers"}},
+		{"f(x: 1, 0x + 2)", []string{"1:9: hexadecimal number 0x has no digits"}},
+	}
+
+	for _, tt := range tests {
+		parser := New(lex.New(tt.input))
+		parser.ParseProgram()
+
+		if fmt.Sprint(parser.Errors()) != fmt.Sprint(tt.expected) {
+			t.Errorf("wrong errors for %q. want=%q, got=%q", tt.input, tt.expected, parser.Errors())
+		}
+	}
+}
+
 func TestBooleanExpression(t *testing.T) {
 	input := `true;`
 
@@ -458,6 +525,18 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a + b.toInt() + c * d / f + g)",
 			"add((((a + (b.toInt)()) + ((c * d) / f)) + g))",
 		},
+		{
+			"a ?? b == c ?? d + 1",
+			"((a ?? (b == c)) ?? (d + 1))",
+		},
+		{
+			"x = a?.b ?? null",
+			"x = ((a?.b) ?? null)",
+		},
+		{
+			"a?.b?.[i + 1].c(d)",
+			"((a?.b)?.[(i + 1)].c)(d)",
+		},
 	}
 
 	for _, tt := range tests {
@@ -662,14 +741,14 @@ func TestFunctionParameterList(t *testing.T) {
 	}
 }
 
-func testParameterList(t *testing.T, parameters []*ast.Identifier, expected []string) bool {
+func testParameterList(t *testing.T, parameters []*ast.Parameter, expected []string) bool {
 	if len(parameters) != len(expected) {
 		t.Errorf("len(parameters)=%d, want=%d", len(parameters), len(expected))
 		return false
 	}
 
 	for i := 0; i < len(parameters); i++ {
-		if !testIdentifier(t, parameters[i], expected[i]) {
+		if !testIdentifier(t, parameters[i].Name, expected[i]) {
 			return false
 		}
 	}
@@ -677,6 +756,161 @@ func testParameterList(t *testing.T, parameters []*ast.Identifier, expected []st
 	return true
 }
 
+func TestTypeAnnotations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x: int = 1", "let x: int = 1;"},
+		{"let mut names: [string] = []", "let names: [string] = [];"},
+		{"let f: fn = fn (a: int, b) -> bool { a }", "let f: fn = fn (a: int, b) -> bool {\na\n};"},
+		{"fn (a: [[int]]) -> [int] { a }", "fn (a: [[int]]) -> [int] {\na\n}"},
+		{"let n: null = null", "let n: null = null;"},
+	}
+
+	for _, tt := range tests {
+		lexer := lex.New(tt.input)
+		parser := New(lexer)
+
+		program := parser.ParseProgram()
+		checkParserErrors(t, parser)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestInvalidTypeAnnotations(t *testing.T) {
+	tests := []string{
+		"let x: = 1",
+		"let x: [int = 1",
+		"fn (a: 1) {}",
+		"fn () -> {}",
+	}
+
+	for _, input := range tests {
+		parser := New(lex.New(input))
+		parser.ParseProgram()
+
+		if len(parser.Errors()) == 0 {
+			t.Errorf("expected parser errors for %q", input)
+		}
+	}
+}
+
+func TestParametersAndArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn (x, y = 10) { x }", "fn (x, y = 10) {\nx\n}"},
+		{"fn (first, ...rest) { rest }", "fn (first, ...rest) {\nrest\n}"},
+		{"fn (x: int = 1 + 2, ...rest: [int]) { x }", "fn (x: int = (1 + 2), ...rest: [int]) {\nx\n}"},
+		{"f(...xs)", "f(...xs)"},
+		{"f(1, ...xs, y: 2, z: g(3))", "f(1, ...xs, y: 2, z: g(3))"},
+		{"f(y: 1 + 2)", "f(y: (1 + 2))"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lex.New(tt.input))
+
+		program := parser.ParseProgram()
+		checkParserErrors(t, parser)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestFunctionStatements(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn add(x, y) { x + y }", "fn add(x, y) {\n(x + y)\n}"},
+		{"fn double(x: int) -> int { x * 2 } double(2)", "fn double(x: int) -> int {\n(x * 2)\n}double(2)"},
+		{"fn (x) { x }(1)", "fn (x) {\nx\n}(1)"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lex.New(tt.input))
+
+		program := parser.ParseProgram()
+		checkParserErrors(t, parser)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	program := New(lex.New("fn f() {} let g = fn () {}")).ParseProgram()
+
+	if statement, ok := program.Statements[0].(*ast.FunctionStatement); !ok || statement.Function.Name.Value != "f" {
+		t.Errorf("program.Statements[0] is not a declaration of f. got=%T (%+v)", program.Statements[0], program.Statements[0])
+	}
+
+	if literal := program.Statements[1].(*ast.LetStatement).Value.(*ast.FunctionLiteral); literal.Name != nil {
+		t.Errorf("function literals have no name. got=%s", literal.Name)
+	}
+
+	parser := New(lex.New("fn f { }"))
+	parser.ParseProgram()
+
+	if len(parser.Errors()) == 0 || parser.Errors()[0] != "1:6: expected next token to be of type (, got type { instead" {
+		t.Errorf("wrong errors. got=%q", parser.Errors())
+	}
+}
+
+func TestInvalidParametersAndArguments(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn (...rest, x) {}", "1:8: rest parameter rest must be the last parameter"},
+		{"fn (x = 1, y) {}", "1:12: parameter y without a default follows parameters with defaults"},
+		{"fn (...rest = []) {}", "1:13: rest parameter rest can't have a default"},
+		{"fn (1) {}", "1:5: expected a parameter name, got INTEGER instead"},
+		{"fn f(a, a) { a }", "1:9: duplicate parameter a"},
+		{"fn ([a, [b]], ...b) {}", "1:18: duplicate parameter b"},
+		{"fn ([a, a] = [1, 2]) {}", "1:9: duplicate parameter a"},
+		{"f(x: 1, 2)", "1:9: positional argument 2 follows named arguments"},
+		{"f(x: 0, !", "1:10: no prefix parse function for EOF found"},
+		{"f(x: 0, !@)", "1:10: illegal character U+0040 '@'"},
+		{"f(x: 0, #*0)", "1:9: illegal character U+0023 '#'"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lex.New(tt.input))
+		parser.ParseProgram()
+
+		if len(parser.Errors()) == 0 || parser.Errors()[0] != tt.expected {
+			t.Errorf("wrong errors for %q. want=%q, got=%q", tt.input, tt.expected, parser.Errors())
+		}
+	}
+}
+
+func TestUnterminatedBlocks(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fn () {", "1:8: expected } to close the block opened at 1:7, got EOF"},
+		{"if x {\n\ty", "2:3: expected } to close the block opened at 1:6, got EOF"},
+		{"for x { let y = fn () { 1 }", "1:28: expected } to close the block opened at 1:7, got EOF"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lex.New(tt.input))
+		parser.ParseProgram()
+
+		if len(parser.Errors()) != 1 || parser.Errors()[0] != tt.expected {
+			t.Errorf("wrong errors for %q. want=%q, got=%q", tt.input, tt.expected, parser.Errors())
+		}
+	}
+}
+
 func TestCallExpressionParsing(t *testing.T) {
 	input := `add(1, 2 * 3, 4 + 5)`
 
@@ -770,6 +1004,116 @@ func TestStringLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a ${x} b"`, "a ${x} b"},
+		{`"${x + 1}${f(y)}"`, "${(x + 1)}${f(y)}"},
+		{`"${fn () { 1 }}"`, "${fn () {\n1\n}}"},
+		{`"outer ${"inner ${x}"}"`, "outer ${inner ${x}}"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lex.New(tt.input))
+
+		program := parser.ParseProgram()
+		checkParserErrors(t, parser)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("program.Statements does not contain 1 statement for %q. got=%d", tt.input, len(program.Statements))
+		}
+
+		str, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.InterpolatedString)
+
+		if !ok {
+			t.Fatalf("expression is not ast.InterpolatedString. got=%T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+		}
+
+		if len(str.Strings) != len(str.Expressions)+1 {
+			t.Errorf("wrong number of parts for %q. got=%d strings and %d expressions", tt.input, len(str.Strings), len(str.Expressions))
+		}
+
+		if str.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, str.String())
+		}
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{`"a ${} b"`, "1:1: expected an expression in ${}"},
+		{`"a ${x y} b"`, "1:8: expected } to close the expression in ${}, got IDENTIFIER instead"},
+		{`"a ${x`, "1:7: expected } to close the expression in ${}, got EOF instead"},
+	}
+
+	for _, tt := range errors {
+		parser := New(lex.New(tt.input))
+		parser.ParseProgram()
+
+		if len(parser.Errors()) == 0 || parser.Errors()[0] != tt.expected {
+			t.Errorf("wrong errors for %q. want=%q, got=%q", tt.input, tt.expected, parser.Errors())
+		}
+	}
+
+	// The rest of the string and what follows it still parse after an empty ${}
+	empty := []struct {
+		input    string
+		expected string
+	}{
+		{`println("${}")`, "1:9: expected an expression in ${}"},
+		{`println("a ${1} b ${}", 2)`, "1:15: expected an expression in ${}"},
+		{`let s = "${}" + 1`, "1:9: expected an expression in ${}"},
+	}
+
+	for _, tt := range empty {
+		parser := New(lex.New(tt.input))
+		parser.ParseProgram()
+
+		if len(parser.Errors()) != 1 || parser.Errors()[0] != tt.expected {
+			t.Errorf("wrong errors for %q. want=%q, got=%q", tt.input, tt.expected, parser.Errors())
+		}
+	}
+}
+
+func TestRawStrings(t *testing.T) {
+	parser := New(lex.New("let s = `a\n\\b`"))
+
+	program := parser.ParseProgram()
+	checkParserErrors(t, parser)
+
+	literal, ok := program.Statements[0].(*ast.LetStatement).Value.(*ast.StringLiteral)
+
+	if !ok || !literal.IsRaw || literal.Value != "a\n\\b" {
+		t.Errorf("value is not the raw string. got=%T (%+v)", program.Statements[0].(*ast.LetStatement).Value, literal)
+	}
+}
+
+func TestLexerErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`let s = "a\qb" let t = "\u{}"`, []string{"1:11: unknown escape sequence \\q", "1:25: invalid escape sequence \\u{, \\u takes hexadecimal digits in braces like \\u{1F600}"}},
+		{`let s = f(1, 2`, []string{"1:15: expected next token to be of type ), got type EOF instead"}},
+		{"let s = `open", []string{"1:9: raw string literal not terminated"}},
+		{"let x = 5 @ 3", []string{"1:11: illegal character U+0040 '@'"}},
+		{"let größe = #", []string{"1:13: illegal character U+0023 '#'"}},
+		{"let s = \"a ${x} b", []string{"1:15: string literal not terminated"}},
+	}
+
+	for _, tt := range tests {
+		parser := New(lex.New(tt.input))
+		parser.ParseProgram()
+
+		if fmt.Sprint(parser.Errors()) != fmt.Sprint(tt.expected) {
+			t.Errorf("wrong errors for %q. want=%q, got=%q", tt.input, tt.expected, parser.Errors())
+		}
+	}
+}
+
 func TestAssignExpressionParsing(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -844,6 +1188,133 @@ func TestForStatementParsing(t *testing.T) {
 	}
 }
 
+func TestPatterns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let [a, b] = xs", "let [a, b] = xs;"},
+		{"let mut [a, [b, c], ...rest] = xs", "let [a, [b, c], ...rest] = xs;"},
+		{"let [] = xs", "let [] = xs;"},
+		{"let [...all]: [int] = xs", "let [...all]: [int] = xs;"},
+		{"fn ([x, y], [z]: [int] = [1]) { x }", "fn ([x, y], [z]: [int] = [1]) {\nx\n}"},
+		{"for x in xs { x }", "for x in xs {\nx\n}"},
+		{"for [k, v] in pairs() { k }", "for [k, v] in pairs() {\nk\n}"},
+		{"for x < 10 { x }", "for (x < 10) {\nx\n}"},
+		{"for x { x }", "for x {\nx\n}"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lex.New(tt.input))
+
+		program := parser.ParseProgram()
+		checkParserErrors(t, parser)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	program := New(lex.New("let [a, [b], ...c] = xs")).ParseProgram()
+	statement := program.Statements[0].(*ast.LetStatement)
+
+	if statement.Name != nil || statement.Pattern == nil {
+		t.Fatalf("statement doesn't destructure its value. got=%T (%+v)", statement, statement)
+	}
+
+	names := []string{}
+
+	for _, identifier := range ast.PatternIdentifiers(statement.Pattern) {
+		names = append(names, identifier.Value)
+	}
+
+	if fmt.Sprint(names) != "[a b c]" {
+		t.Errorf("wrong pattern identifiers. got=%v", names)
+	}
+
+	errors := []struct {
+		input    string
+		expected string
+	}{
+		{"let {x, y} = point", "1:5: only arrays can be destructured, there are no object patterns"},
+		{"let [a, ...b, c] = xs", "1:12: rest element b must be the last element"},
+		{"let [a, 1] = xs", "1:9: expected a name or a pattern, got INTEGER instead"},
+		{"let [a, b,] = xs", "1:11: expected a name or a pattern, got ] instead"},
+		{"for[A,]in[]{}0", "1:7: expected a name or a pattern, got ] instead"},
+		{"fn ([a], b = 1, [c]) {}", "1:17: parameter [c] without a default follows parameters with defaults"},
+		{"for [a] xs {}", "1:9: expected next token to be of type IN, got type IDENTIFIER instead"},
+	}
+
+	for _, tt := range errors {
+		parser := New(lex.New(tt.input))
+		parser.ParseProgram()
+
+		if len(parser.Errors()) == 0 || parser.Errors()[0] != tt.expected {
+			t.Errorf("wrong errors for %q. want=%q, got=%q", tt.input, tt.expected, parser.Errors())
+		}
+	}
+
+	objectPatterns := []struct {
+		input    string
+		expected string
+	}{
+		{"let {x, y} = point", "1:5: only arrays can be destructured, there are no object patterns"},
+		{"fn f({a}) { a }", "1:6: only arrays can be destructured, there are no object patterns"},
+		{"for {k, v} in xs { k }", "1:5: only arrays can be destructured, there are no object patterns"},
+		{"let [a, {b, {c}}] = xs", "1:9: only arrays can be destructured, there are no object patterns"},
+		{"let {x", "1:5: only arrays can be destructured, there are no object patterns"},
+	}
+
+	for _, tt := range objectPatterns {
+		parser := New(lex.New(tt.input))
+		parser.ParseProgram()
+
+		if len(parser.Errors()) != 1 || parser.Errors()[0] != tt.expected {
+			t.Errorf("wrong errors for %q. want=[%q], got=%q", tt.input, tt.expected, parser.Errors())
+		}
+	}
+}
+
+func TestOptionalChains(t *testing.T) {
+	parser := New(lex.New("a?.b?.[0]"))
+	program := parser.ParseProgram()
+	checkParserErrors(t, parser)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+	index, ok := stmt.Expression.(*ast.ArrayAccessExpression)
+
+	if !ok || !index.IsOptional {
+		t.Fatalf("expression is not an optional ast.ArrayAccessExpression. got=%T (%+v)", stmt.Expression, stmt.Expression)
+	}
+
+	member, ok := index.Expression.(*ast.MemberAccessExpression)
+
+	if !ok || !member.IsOptional || member.AccessedMember.Value != "b" {
+		t.Fatalf("index.Expression is not an optional ast.MemberAccessExpression. got=%T (%+v)", index.Expression, index.Expression)
+	}
+
+	errors := []struct {
+		input    string
+		expected []string
+	}{
+		{"a?.b = 1", []string{"1:6: cannot assign to an optional chain, the value in front of ?. may be null"}},
+		{"a?.b.c[0] = 1", []string{"1:11: cannot assign to an optional chain, the value in front of ?. may be null"}},
+		{"0 .?.a = 1", []string{"1:4: expected next token to be of type IDENTIFIER, got type ?. instead", "1:8: cannot assign to an optional chain, the value in front of ?. may be null"}},
+		{"a?.1", []string{"1:4: expected next token to be of type IDENTIFIER, got type INTEGER instead"}},
+		{"a ? b", []string{"1:3: illegal character U+003F '?', ? is only used in ?. and ??"}},
+	}
+
+	for _, tt := range errors {
+		parser := New(lex.New(tt.input))
+		parser.ParseProgram()
+
+		if fmt.Sprint(parser.Errors()) != fmt.Sprint(tt.expected) {
+			t.Errorf("wrong errors for %q. want=%q, got=%q", tt.input, tt.expected, parser.Errors())
+		}
+	}
+}
+
 func TestMemberAccessExpressionParsing(t *testing.T) {
 	tests := []struct {
 		input string
@@ -1105,6 +1576,27 @@ func TestArrayAccess(t *testing.T) {
 	}
 }
 
+func TestUnmatchedBrackets(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[0)", "1:4: expected next token to be of type ], got type ) instead"},
+		{"[][0)=0", "1:5: expected next token to be of type ], got type ) instead"},
+		{"a[0", "1:4: expected next token to be of type ], got type EOF instead"},
+		{"for 0 0}", "1:7: expected next token to be of type {, got type INTEGER instead"},
+	}
+
+	for _, tt := range tests {
+		parser := New(lex.New(tt.input))
+		parser.ParseProgram()
+
+		if errors := parser.Errors(); len(errors) == 0 || errors[0] != tt.expected {
+			t.Errorf("wrong errors for %q. want first=%q, got=%q", tt.input, tt.expected, errors)
+		}
+	}
+}
+
 func testLiteralExpression(t *testing.T, exp ast.Expression, expected interface{}) bool {
 	switch v := expected.(type) {
 	case int:
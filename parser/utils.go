@@ -8,6 +8,13 @@ import (
 func (parser *Parser) nextToken() {
 	parser.currentToken = parser.peekToken
 	parser.peekToken = parser.lexer.NextToken()
+
+	// Malformed tokens are reported in the order they are read, among the syntax errors
+	for _, err := range parser.lexer.Errors()[parser.lexerErrors:] {
+		parser.errors = append(parser.errors, Error(err))
+	}
+
+	parser.lexerErrors = len(parser.lexer.Errors())
 }
 
 func (parser *Parser) currentTokenIs(t token.Type) bool {
@@ -24,7 +31,7 @@ func (parser *Parser) expectPeek(t token.Type) bool {
 		return true
 	}
 
-	parser.appendError("expected next token to be of type %s, got type %s instead", t, parser.peekToken.Type)
+	parser.appendError(parser.peekToken, "expected next token to be of type %s, got type %s instead", t, parser.peekToken.Type)
 	return false
 }
 
@@ -44,6 +51,6 @@ func (parser *Parser) currentPrecedence() int {
 	return LOWEST
 }
 
-func (parser *Parser) appendError(format string, a ...interface{}) {
-	parser.errors = append(parser.errors, fmt.Sprintf(format, a...))
+func (parser *Parser) appendError(tok token.Token, format string, a ...interface{}) {
+	parser.errors = append(parser.errors, Error{Token: tok, Message: fmt.Sprintf(format, a...)})
 }
@@ -0,0 +1,248 @@
+package profiler
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+	"time"
+)
+
+// The fields of the messages in pprof's profile.proto the profile is written with
+const (
+	profileSampleType        = 1
+	profileSample            = 2
+	profileLocation          = 4
+	profileFunction          = 5
+	profileStringTable       = 6
+	profileTimeNanos         = 9
+	profileDurationNanos     = 10
+	profilePeriodType        = 11
+	profilePeriod            = 12
+	profileDefaultSampleType = 14
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+	functionStartLine  = 5
+)
+
+// WritePprof writes the profile in the gzipped protocol buffer format of pprof, every call stack is a
+// sample with the calls made to it, the time spent at it and the objects allocated at it
+func (p *Profiler) WritePprof(w io.Writer) error {
+	table := newStringTable()
+	profile := &protobuf{}
+
+	for _, sampleType := range [][2]string{{"calls", "count"}, {"time", "nanoseconds"}, {"allocations", "count"}} {
+		valueType := &protobuf{}
+		valueType.int64Field(valueTypeType, table.index(sampleType[0]))
+		valueType.int64Field(valueTypeUnit, table.index(sampleType[1]))
+
+		profile.message(profileSampleType, valueType)
+	}
+
+	functions := p.Functions()
+
+	sort.Slice(functions, func(i, j int) bool { return functions[i].Line < functions[j].Line })
+
+	for i, function := range functions {
+		function.id = uint64(i + 1)
+
+		message := &protobuf{}
+		message.uint64Field(functionID, function.id)
+		message.int64Field(functionName, table.index(function.Name))
+		message.int64Field(functionSystemName, table.index(function.Name))
+		message.int64Field(functionFilename, table.index(p.name))
+		message.int64Field(functionStartLine, int64(function.Line))
+
+		profile.message(profileFunction, message)
+	}
+
+	locations := make(map[location]uint64)
+
+	for _, n := range p.sortedNodes() {
+		stack := []uint64{}
+
+		for at := n; at != nil; at = at.caller {
+			id, ok := locations[at.location]
+
+			if !ok {
+				id = uint64(len(locations) + 1)
+				locations[at.location] = id
+
+				line := &protobuf{}
+				line.uint64Field(lineFunctionID, at.location.function.id)
+				line.int64Field(lineLine, int64(at.location.line))
+
+				message := &protobuf{}
+				message.uint64Field(locationID, id)
+				message.message(locationLine, line)
+
+				profile.message(profileLocation, message)
+			}
+
+			stack = append(stack, id)
+		}
+
+		message := &protobuf{}
+		message.packedUint64(sampleLocationID, stack)
+		message.packedInt64(sampleValue, []int64{n.calls, int64(n.time), n.allocations})
+
+		profile.message(profileSample, message)
+	}
+
+	profile.int64Field(profileTimeNanos, p.start.UnixNano())
+	profile.int64Field(profileDurationNanos, int64(p.duration))
+
+	periodType := &protobuf{}
+	periodType.int64Field(valueTypeType, table.index("time"))
+	periodType.int64Field(valueTypeUnit, table.index("nanoseconds"))
+
+	profile.message(profilePeriodType, periodType)
+	profile.int64Field(profilePeriod, int64(time.Nanosecond))
+	profile.int64Field(profileDefaultSampleType, table.index("time"))
+
+	// The string table goes last, every string has been added to it by now
+	for _, s := range table.strings {
+		profile.stringField(profileStringTable, s)
+	}
+
+	compressed := gzip.NewWriter(w)
+
+	if _, err := compressed.Write(profile.bytes); err != nil {
+		return err
+	}
+
+	return compressed.Close()
+}
+
+// sortedNodes returns the call stacks something was measured at, callers before the functions they call
+func (p *Profiler) sortedNodes() []*node {
+	nodes := []*node{}
+
+	for _, n := range p.nodes {
+		if n.calls != 0 || n.time != 0 || n.allocations != 0 {
+			nodes = append(nodes, n)
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return depth(nodes[i]) < depth(nodes[j]) || depth(nodes[i]) == depth(nodes[j]) && nodes[i].location.line < nodes[j].location.line
+	})
+
+	return nodes
+}
+
+func depth(n *node) int {
+	d := 0
+
+	for ; n != nil; n = n.caller {
+		d += 1
+	}
+
+	return d
+}
+
+// stringTable numbers the strings of a profile, the first one has to be empty
+type stringTable struct {
+	strings []string
+	indexes map[string]int64
+}
+
+func newStringTable() *stringTable {
+	return &stringTable{strings: []string{""}, indexes: map[string]int64{"": 0}}
+}
+
+func (t *stringTable) index(s string) int64 {
+	if i, ok := t.indexes[s]; ok {
+		return i
+	}
+
+	t.indexes[s] = int64(len(t.strings))
+	t.strings = append(t.strings, s)
+
+	return t.indexes[s]
+}
+
+// protobuf encodes a protocol buffer message, fields with their zero value are left out
+type protobuf struct {
+	bytes []byte
+}
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (b *protobuf) varint(x uint64) {
+	for x >= 0x80 {
+		b.bytes = append(b.bytes, byte(x)|0x80)
+		x >>= 7
+	}
+
+	b.bytes = append(b.bytes, byte(x))
+}
+
+func (b *protobuf) key(field int, wireType int) {
+	b.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (b *protobuf) uint64Field(field int, x uint64) {
+	if x == 0 {
+		return
+	}
+
+	b.key(field, wireVarint)
+	b.varint(x)
+}
+
+func (b *protobuf) int64Field(field int, x int64) {
+	b.uint64Field(field, uint64(x))
+}
+
+func (b *protobuf) bytesField(field int, data []byte) {
+	b.key(field, wireBytes)
+	b.varint(uint64(len(data)))
+	b.bytes = append(b.bytes, data...)
+}
+
+// stringField writes empty strings too, the string table needs them
+func (b *protobuf) stringField(field int, s string) {
+	b.bytesField(field, []byte(s))
+}
+
+func (b *protobuf) message(field int, message *protobuf) {
+	b.bytesField(field, message.bytes)
+}
+
+func (b *protobuf) packedUint64(field int, xs []uint64) {
+	packed := &protobuf{}
+
+	for _, x := range xs {
+		packed.varint(x)
+	}
+
+	b.bytesField(field, packed.bytes)
+}
+
+func (b *protobuf) packedInt64(field int, xs []int64) {
+	packed := &protobuf{}
+
+	for _, x := range xs {
+		packed.varint(uint64(x))
+	}
+
+	b.bytesField(field, packed.bytes)
+}
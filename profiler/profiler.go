@@ -0,0 +1,225 @@
+// Package profiler measures where gopp programs spend their time and what they allocate
+package profiler
+
+import (
+	"go++/ast"
+	"go++/evaluator"
+	"go++/object"
+	"strings"
+	"time"
+)
+
+// Function is what was measured of the calls of every function defined at the same position
+type Function struct {
+	Name   string
+	Line   int
+	Column int
+
+	Calls       int
+	Allocations int
+
+	// Self is the time spent in the function's own statements, Cumulative includes the functions it called
+	Self       time.Duration
+	Cumulative time.Duration
+
+	// active counts the calls on the stack, recursive calls only add to Cumulative once
+	active int
+	id     uint64
+}
+
+// Line is what was measured of the statements starting on a line
+type Line struct {
+	Line        int
+	Hits        int
+	Allocations int
+	Time        time.Duration
+}
+
+type frame struct {
+	function *Function
+	line     int
+	start    time.Time
+
+	// caller is the node of the call stack the function was called from, node the one of its current line
+	caller *node
+	node   *node
+}
+
+// location is a line of a function, call stacks are made of them
+type location struct {
+	function *Function
+	line     int
+}
+
+// node is a call stack in the tree of every stack the program was at, with what was measured at it
+type node struct {
+	caller   *node
+	location location
+
+	calls       int64
+	time        time.Duration
+	allocations int64
+}
+
+type nodeKey struct {
+	caller   *node
+	location location
+}
+
+// Profiler is hooked into the evaluator while it runs a program
+type Profiler struct {
+	name  string
+	lines []string
+
+	functions map[definition]*Function
+	hotLines  map[int]*Line
+	types     map[object.Type]int
+	nodes     map[nodeKey]*node
+
+	frames    []*frame
+	lastEvent time.Time
+	start     time.Time
+	duration  time.Duration
+}
+
+// definition is the position a function is defined at
+type definition struct {
+	line, column int
+}
+
+func New(name string, source string) *Profiler {
+	return &Profiler{
+		name:      name,
+		lines:     strings.Split(source, "\n"),
+		functions: make(map[definition]*Function),
+		hotLines:  make(map[int]*Line),
+		types:     make(map[object.Type]int),
+		nodes:     make(map[nodeKey]*node),
+	}
+}
+
+// Run evaluates the program in env while measuring it
+func (p *Profiler) Run(program *ast.Program, env *object.Environment) object.Object {
+	main := &Function{Name: "main", Calls: 1, active: 1}
+	p.functions[definition{}] = main
+
+	p.start = time.Now()
+	p.lastEvent = p.start
+	p.frames = []*frame{{function: main, start: p.start, node: p.node(nil, main, 0)}}
+
+	previous := evaluator.Profiler
+	evaluator.Profiler = p
+	defer func() { evaluator.Profiler = previous }()
+
+	result := evaluator.Evaluate(program, env)
+
+	now := time.Now()
+	p.flush(now)
+
+	p.duration = now.Sub(p.start)
+	main.Cumulative = p.duration
+
+	return result
+}
+
+// flush gives the time since the last event to the statement that was running
+func (p *Profiler) flush(now time.Time) {
+	elapsed := now.Sub(p.lastEvent)
+	p.lastEvent = now
+
+	top := p.frames[len(p.frames)-1]
+	top.function.Self += elapsed
+
+	if line, ok := p.hotLines[top.line]; ok {
+		line.Time += elapsed
+	}
+
+	top.node.time += elapsed
+}
+
+// node returns the node of the call stack made of caller and the line of fn
+func (p *Profiler) node(caller *node, fn *Function, line int) *node {
+	key := nodeKey{caller: caller, location: location{function: fn, line: line}}
+	n, ok := p.nodes[key]
+
+	if !ok {
+		n = &node{caller: caller, location: key.location}
+		p.nodes[key] = n
+	}
+
+	return n
+}
+
+func (p *Profiler) Statement(statement ast.Statement) {
+	p.flush(time.Now())
+
+	line := ast.StartToken(statement).Line
+
+	stats, ok := p.hotLines[line]
+
+	if !ok {
+		stats = &Line{Line: line}
+		p.hotLines[line] = stats
+	}
+
+	stats.Hits += 1
+
+	top := p.frames[len(p.frames)-1]
+
+	if top.line != line {
+		top.line = line
+		top.node = p.node(top.caller, top.function, line)
+	}
+}
+
+func (p *Profiler) Call(fn *object.Function) {
+	now := time.Now()
+	p.flush(now)
+
+	key := definition{fn.Token.Line, fn.Token.Column}
+	function, ok := p.functions[key]
+
+	if !ok {
+		function = &Function{Name: evaluator.FunctionName(fn), Line: fn.Token.Line, Column: fn.Token.Column}
+		p.functions[key] = function
+	}
+
+	function.Calls += 1
+	function.active += 1
+
+	caller := p.frames[len(p.frames)-1].node
+	callee := &frame{function: function, line: fn.Token.Line, start: now, caller: caller, node: p.node(caller, function, fn.Token.Line)}
+
+	p.frames = append(p.frames, callee)
+	callee.node.calls += 1
+}
+
+func (p *Profiler) Return(fn *object.Function) {
+	if len(p.frames) == 1 {
+		return
+	}
+
+	now := time.Now()
+	p.flush(now)
+
+	top := p.frames[len(p.frames)-1]
+	p.frames = p.frames[:len(p.frames)-1]
+
+	top.function.active -= 1
+
+	if top.function.active == 0 {
+		top.function.Cumulative += now.Sub(top.start)
+	}
+}
+
+func (p *Profiler) Allocated(obj object.Object) {
+	top := p.frames[len(p.frames)-1]
+	top.function.Allocations += 1
+
+	if line, ok := p.hotLines[top.line]; ok {
+		line.Allocations += 1
+	}
+
+	p.types[obj.Type()] += 1
+	top.node.allocations += 1
+}
@@ -0,0 +1,239 @@
+package profiler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"go++/ast"
+	"go++/evaluator"
+	"go++/lexer"
+	"go++/object"
+	"go++/parser"
+	"io"
+	"strings"
+	"testing"
+)
+
+const program = `let fib = fn (n) {
+	if n < 2 {
+		return n
+	}
+
+	fib(n - 1) + fib(n - 2)
+}
+
+let square = fn (x) { x * x }
+let numbers = [1, 2, 3].map(fn (v, i) { square(v) })
+fib(10)
+`
+
+func profile(t *testing.T) *Profiler {
+	pars := parser.New(lexer.New(program))
+	parsed := pars.ParseProgram()
+
+	if len(pars.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", pars.Errors())
+	}
+
+	prof := New("fib.gopp", program)
+	result := prof.Run(parsed, object.NewEnvironment())
+
+	if integer, ok := result.(*object.Integer); !ok || integer.Value != 55 {
+		t.Fatalf("wrong result. got=%T (%+v)", result, result)
+	}
+
+	if evaluator.Profiler != nil {
+		t.Errorf("the profiler is still hooked into the evaluator")
+	}
+
+	return prof
+}
+
+// statements counts the statements a debug hook is told about
+type statements struct {
+	count int
+}
+
+func (s *statements) Statement(statement ast.Statement, env *object.Environment) *object.Error {
+	s.count += 1
+
+	return nil
+}
+
+func (s *statements) Call(fn *object.Function, env *object.Environment) {}
+func (s *statements) Return(fn *object.Function, result object.Object)  {}
+func (s *statements) Iteration(loop ast.Expression)                     {}
+
+func TestProfileWithDebugHook(t *testing.T) {
+	hook := &statements{}
+	evaluator.Debugger = hook
+	defer func() { evaluator.Debugger = nil }()
+
+	prof := profile(t)
+
+	if evaluator.Debugger != hook {
+		t.Errorf("the profiler replaced the debug hook. got=%T", evaluator.Debugger)
+	}
+
+	main := prof.functions[definition{}]
+
+	if hook.count == 0 || main.Allocations == 0 {
+		t.Errorf("expected the debug hook and the profiler to both see the program. statements=%d, allocations=%d", hook.count, main.Allocations)
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	tests := []struct {
+		name        string
+		line        int
+		calls       int
+		allocations int
+	}{
+		{"main", 0, 1, 12},
+		{"fib", 1, 177, 177 + 88*5},
+		{"square", 9, 3, 3},
+		{"fn", 10, 3, 0},
+	}
+
+	functions := map[string]*Function{}
+
+	for _, function := range profile(t).Functions() {
+		functions[function.Name] = function
+
+		if function.Cumulative < function.Self {
+			t.Errorf("%s took less time with its calls than without. self=%s, cumulative=%s", function.Name, function.Self, function.Cumulative)
+		}
+	}
+
+	for _, tt := range tests {
+		function, ok := functions[tt.name]
+
+		if !ok {
+			t.Errorf("no profile of %s", tt.name)
+			continue
+		}
+
+		if function.Line != tt.line || function.Calls != tt.calls || function.Allocations != tt.allocations {
+			t.Errorf("wrong profile of %s. want line=%d calls=%d allocs=%d, got=%+v", tt.name, tt.line, tt.calls, tt.allocations, function)
+		}
+	}
+}
+
+func TestLines(t *testing.T) {
+	hits := map[int]int{}
+
+	for _, line := range profile(t).Lines() {
+		hits[line.Line] = line.Hits
+	}
+
+	expected := map[int]int{1: 1, 2: 177, 3: 89, 6: 88, 9: 4, 10: 4, 11: 1}
+
+	for line, count := range expected {
+		if hits[line] != count {
+			t.Errorf("wrong hits of line %d. want=%d, got=%d", line, count, hits[line])
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	var out bytes.Buffer
+	profile(t).Report(&out)
+
+	for _, expected := range []string{
+		"profile of fib.gopp",
+		"     177",
+		"fib (fib.gopp:1:11)",
+		"fib.gopp:6\tfib(n - 1) + fib(n - 2)",
+		"allocated 632 objects: 627 INTEGER, 3 FUNCTION, 2 ARRAY",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("the report doesn't contain %q. got=\n%s", expected, out.String())
+		}
+	}
+}
+
+// field is a decoded field of a protocol buffer message
+type field struct {
+	number int
+	value  uint64
+	bytes  []byte
+}
+
+func decode(t *testing.T, data []byte) []field {
+	fields := []field{}
+
+	varint := func() uint64 {
+		var x uint64
+
+		for shift := 0; ; shift += 7 {
+			if len(data) == 0 {
+				t.Fatalf("truncated varint")
+			}
+
+			b := data[0]
+			data = data[1:]
+			x |= uint64(b&0x7f) << shift
+
+			if b < 0x80 {
+				return x
+			}
+		}
+	}
+
+	for len(data) > 0 {
+		key := varint()
+		f := field{number: int(key >> 3)}
+
+		switch key & 7 {
+		case 0:
+			f.value = varint()
+		case 2:
+			length := varint()
+			f.bytes, data = data[:length], data[length:]
+		default:
+			t.Fatalf("unexpected wire type %d", key&7)
+		}
+
+		fields = append(fields, f)
+	}
+
+	return fields
+}
+
+func TestWritePprof(t *testing.T) {
+	var out bytes.Buffer
+
+	if err := profile(t).WritePprof(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := gzip.NewReader(&out)
+
+	if err != nil {
+		t.Fatalf("the profile isn't gzipped: %v", err)
+	}
+
+	data, err := io.ReadAll(reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	counts := map[int]int{}
+	table := []string{}
+
+	for _, f := range decode(t, data) {
+		counts[f.number] += 1
+
+		if f.number == profileStringTable {
+			table = append(table, string(f.bytes))
+		}
+	}
+
+	if counts[profileSampleType] != 3 || counts[profileFunction] != 4 || counts[profileSample] == 0 || counts[profileLocation] == 0 {
+		t.Errorf("wrong messages in the profile. got=%v", counts)
+	}
+
+	if len(table) == 0 || table[0] != "" || !strings.Contains(strings.Join(table, ","), ",fib,") {
+		t.Errorf("wrong string table. got=%q", table)
+	}
+}
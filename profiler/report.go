@@ -0,0 +1,125 @@
+package profiler
+
+import (
+	"fmt"
+	"go++/object"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// reportedLines is how many lines the report lists
+const reportedLines = 10
+
+// Functions returns what was measured of every function, the ones that took the most time themselves first
+func (p *Profiler) Functions() []*Function {
+	functions := make([]*Function, 0, len(p.functions))
+
+	for _, function := range p.functions {
+		functions = append(functions, function)
+	}
+
+	sort.Slice(functions, func(i, j int) bool {
+		if functions[i].Self != functions[j].Self {
+			return functions[i].Self > functions[j].Self
+		}
+
+		return functions[i].Line < functions[j].Line
+	})
+
+	return functions
+}
+
+// Lines returns what was measured of every line that ran, the ones that took the most time first
+func (p *Profiler) Lines() []*Line {
+	lines := make([]*Line, 0, len(p.hotLines))
+
+	for _, line := range p.hotLines {
+		lines = append(lines, line)
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Time != lines[j].Time {
+			return lines[i].Time > lines[j].Time
+		}
+
+		return lines[i].Line < lines[j].Line
+	})
+
+	return lines
+}
+
+// Report prints the functions and the lines that took the most time and what the program allocated
+func (p *Profiler) Report(w io.Writer) {
+	fmt.Fprintf(w, "profile of %s, %s in total\n\n", p.name, round(p.duration))
+
+	fmt.Fprintf(w, "%8s %12s %12s %8s  %s\n", "calls", "self", "cumulative", "allocs", "function")
+
+	for _, function := range p.Functions() {
+		fmt.Fprintf(w, "%8d %12s %12s %8d  %s\n", function.Calls, round(function.Self), round(function.Cumulative), function.Allocations, p.describe(function))
+	}
+
+	fmt.Fprintf(w, "\n%8s %12s %8s  %s\n", "hits", "time", "allocs", "line")
+
+	for i, line := range p.Lines() {
+		if i == reportedLines {
+			break
+		}
+
+		fmt.Fprintf(w, "%8d %12s %8d  %s:%d\t%s\n", line.Hits, round(line.Time), line.Allocations, p.name, line.Line, p.source(line.Line))
+	}
+
+	fmt.Fprintf(w, "\n%s\n", p.allocations())
+}
+
+func (p *Profiler) describe(function *Function) string {
+	if function.Line == 0 {
+		return function.Name
+	}
+
+	return fmt.Sprintf("%s (%s:%d:%d)", function.Name, p.name, function.Line, function.Column)
+}
+
+func (p *Profiler) source(line int) string {
+	if line < 1 || line > len(p.lines) {
+		return ""
+	}
+
+	return strings.TrimSpace(p.lines[line-1])
+}
+
+// allocations sums up the objects allocated by their type, the most allocated type first
+func (p *Profiler) allocations() string {
+	total := 0
+	types := []object.Type{}
+
+	for objectType, count := range p.types {
+		total += count
+		types = append(types, objectType)
+	}
+
+	if total == 0 {
+		return "allocated no objects"
+	}
+
+	sort.Slice(types, func(i, j int) bool {
+		if p.types[types[i]] != p.types[types[j]] {
+			return p.types[types[i]] > p.types[types[j]]
+		}
+
+		return types[i] < types[j]
+	})
+
+	counts := make([]string, len(types))
+
+	for i, objectType := range types {
+		counts[i] = fmt.Sprintf("%d %s", p.types[objectType], objectType)
+	}
+
+	return fmt.Sprintf("allocated %d objects: %s", total, strings.Join(counts, ", "))
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
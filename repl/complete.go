@@ -0,0 +1,126 @@
+package repl
+
+import (
+	"go++/ast"
+	"go++/evaluator"
+	"go++/lexer"
+	"go++/parser"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+func isIdentifierRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// complete returns where the word in front of the cursor starts and the names it can be completed to,
+// after a dot those are the members of the value in front of it, otherwise the bindings and builtins
+func (s *session) complete(line []rune, pos int) (int, []string) {
+	start := pos
+
+	for start > 0 && isIdentifierRune(line[start-1]) {
+		start -= 1
+	}
+
+	prefix := string(line[start:pos])
+	names := []string{}
+
+	if start > 0 && line[start-1] == '.' {
+		names = s.memberNames(line[:start-1])
+	} else {
+		names = append(names, s.env.Names()...)
+		names = append(names, evaluator.BuiltinNames()...)
+	}
+
+	candidates := []string{}
+	seen := make(map[string]bool)
+
+	for _, name := range names {
+		if strings.HasPrefix(name, prefix) && !seen[name] {
+			seen[name] = true
+			candidates = append(candidates, name)
+		}
+	}
+
+	sort.Strings(candidates)
+
+	return start, candidates
+}
+
+// memberNames lists the members of the value the text ends with, only expressions without
+// side effects like names, literals, indexing and member access are evaluated
+func (s *session) memberNames(text []rune) []string {
+	expression, ok := parseReceiver(string(text[receiverStart(text):]))
+
+	if !ok {
+		return nil
+	}
+
+	value := evaluator.Evaluate(expression, s.env)
+
+	if value == nil {
+		return nil
+	}
+
+	return value.GetMembers().Names()
+}
+
+// receiverStart finds where the operand a member access is applied to starts, skipping back
+// over names, dots, indexing and string literals
+func receiverStart(text []rune) int {
+	i := len(text)
+	depth := 0
+
+	for i > 0 {
+		r := text[i-1]
+
+		switch {
+		case r == ']':
+			depth += 1
+		case r == '[' && depth > 0:
+			depth -= 1
+		case r == '"':
+			i -= 1
+
+			for i > 0 && text[i-1] != '"' {
+				i -= 1
+			}
+		case depth > 0 || isIdentifierRune(r) || r == '.':
+		default:
+			return i
+		}
+
+		i -= 1
+	}
+
+	return 0
+}
+
+func parseReceiver(input string) (ast.Expression, bool) {
+	pars := parser.New(lexer.New(input))
+	program := pars.ParseProgram()
+
+	if len(pars.Errors()) > 0 || len(program.Statements) != 1 {
+		return nil, false
+	}
+
+	statement, ok := program.Statements[0].(*ast.ExpressionStatement)
+
+	if !ok {
+		return nil, false
+	}
+
+	isPure := true
+
+	ast.Inspect(statement.Expression, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.CallExpression, *ast.AssignExpression, *ast.FunctionLiteral, *ast.IfExpression, *ast.ForLoopLiteral, *ast.ForInLoopLiteral:
+			isPure = false
+		}
+
+		return isPure
+	})
+
+	return statement.Expression, isPure
+}
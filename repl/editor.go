@@ -0,0 +1,297 @@
+package repl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// errInterrupted is returned when the line is abandoned with Ctrl-C
+var errInterrupted = errors.New("interrupted")
+
+// lineReader reads one line of input after showing the prompt
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+// plainReader reads lines from input that isn't a terminal, like a pipe or a test
+type plainReader struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func (r *plainReader) ReadLine(prompt string) (string, error) {
+	_, _ = io.WriteString(r.out, prompt)
+
+	line, err := r.in.ReadString('\n')
+
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// completer returns where the word in front of the cursor starts and what it can be completed to
+type completer func(line []rune, pos int) (int, []string)
+
+// newLineReader edits lines in raw mode when both in and out are a terminal
+func newLineReader(in io.Reader, out io.Writer, complete completer) lineReader {
+	inFile, inOk := in.(*os.File)
+	outFile, outOk := out.(*os.File)
+
+	if inOk && outOk && isTerminal(inFile.Fd()) && isTerminal(outFile.Fd()) {
+		return &editor{in: bufio.NewReader(inFile), out: outFile, fd: inFile.Fd(), history: loadHistory(historyPath()), complete: complete}
+	}
+
+	return &plainReader{in: bufio.NewReader(in), out: out}
+}
+
+func ctrl(key rune) rune {
+	return key & 0x1f
+}
+
+// editor is a line editor with cursor movement, emacs style shortcuts and history
+type editor struct {
+	in       *bufio.Reader
+	out      io.Writer
+	fd       uintptr
+	history  *history
+	complete completer
+
+	prompt string
+	buf    []rune
+	pos    int
+
+	// browsing is the history entry shown, saved is the line that was edited before browsing
+	browsing int
+	saved    []rune
+}
+
+func (e *editor) ReadLine(prompt string) (string, error) {
+	restore, err := makeRaw(e.fd)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer restore()
+
+	e.prompt = prompt
+	e.buf = []rune{}
+	e.pos = 0
+	e.browsing = len(e.history.entries)
+
+	_, _ = io.WriteString(e.out, prompt)
+
+	for {
+		r, _, err := e.in.ReadRune()
+
+		if err != nil {
+			return "", err
+		}
+
+		switch r {
+		case '\r', '\n':
+			_, _ = io.WriteString(e.out, "\r\n")
+			line := string(e.buf)
+			e.history.add(line)
+
+			return line, nil
+		case ctrl('C'):
+			_, _ = io.WriteString(e.out, "^C\r\n")
+
+			return "", errInterrupted
+		case ctrl('D'):
+			if len(e.buf) == 0 {
+				_, _ = io.WriteString(e.out, "\r\n")
+
+				return "", io.EOF
+			}
+
+			e.deleteForward()
+		case ctrl('A'):
+			e.pos = 0
+		case ctrl('E'):
+			e.pos = len(e.buf)
+		case ctrl('B'):
+			e.moveLeft()
+		case ctrl('F'):
+			e.moveRight()
+		case ctrl('K'):
+			e.buf = e.buf[:e.pos]
+		case ctrl('U'):
+			e.buf = e.buf[e.pos:]
+			e.pos = 0
+		case ctrl('W'):
+			e.deleteWord()
+		case ctrl('L'):
+			_, _ = io.WriteString(e.out, "\x1b[H\x1b[2J")
+		case ctrl('P'):
+			e.browse(-1)
+		case ctrl('N'):
+			e.browse(1)
+		case ctrl('H'), 127:
+			if e.pos > 0 {
+				e.pos -= 1
+				e.deleteForward()
+			}
+		case '\t':
+			e.completeWord()
+		case 27:
+			e.escape()
+		default:
+			if unicode.IsPrint(r) {
+				e.insert([]rune{r})
+			}
+		}
+
+		e.redraw()
+	}
+}
+
+// escape handles the sequences sent by the arrow, home, end and delete keys
+func (e *editor) escape() {
+	next, _, err := e.in.ReadRune()
+
+	if err != nil || next != '[' && next != 'O' {
+		return
+	}
+
+	params := ""
+
+	for {
+		r, _, err := e.in.ReadRune()
+
+		if err != nil {
+			return
+		}
+
+		if r < 0x40 || r > 0x7e {
+			params += string(r)
+			continue
+		}
+
+		switch {
+		case r == 'A':
+			e.browse(-1)
+		case r == 'B':
+			e.browse(1)
+		case r == 'C':
+			e.moveRight()
+		case r == 'D':
+			e.moveLeft()
+		case r == 'H' || r == '~' && (params == "1" || params == "7"):
+			e.pos = 0
+		case r == 'F' || r == '~' && (params == "4" || params == "8"):
+			e.pos = len(e.buf)
+		case r == '~' && params == "3":
+			e.deleteForward()
+		}
+
+		return
+	}
+}
+
+// completeWord completes the word in front of the cursor as far as every candidate agrees,
+// the candidates are listed when that doesn't add anything
+func (e *editor) completeWord() {
+	start, candidates := e.complete(e.buf, e.pos)
+
+	if len(candidates) == 0 {
+		return
+	}
+
+	prefix := candidates[0]
+
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	if word := string(e.buf[start:e.pos]); len(prefix) > len(word) {
+		e.insert([]rune(prefix[len(word):]))
+		return
+	}
+
+	if len(candidates) > 1 {
+		_, _ = io.WriteString(e.out, "\r\n"+strings.Join(candidates, "  ")+"\r\n")
+	}
+}
+
+func (e *editor) insert(runes []rune) {
+	buf := append([]rune{}, e.buf[:e.pos]...)
+	buf = append(buf, runes...)
+	e.buf = append(buf, e.buf[e.pos:]...)
+	e.pos += len(runes)
+}
+
+func (e *editor) deleteForward() {
+	if e.pos < len(e.buf) {
+		e.buf = append(e.buf[:e.pos], e.buf[e.pos+1:]...)
+	}
+}
+
+// deleteWord deletes the word in front of the cursor and the spaces after it
+func (e *editor) deleteWord() {
+	start := e.pos
+
+	for start > 0 && unicode.IsSpace(e.buf[start-1]) {
+		start -= 1
+	}
+
+	for start > 0 && !unicode.IsSpace(e.buf[start-1]) {
+		start -= 1
+	}
+
+	e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+	e.pos = start
+}
+
+func (e *editor) moveLeft() {
+	if e.pos > 0 {
+		e.pos -= 1
+	}
+}
+
+func (e *editor) moveRight() {
+	if e.pos < len(e.buf) {
+		e.pos += 1
+	}
+}
+
+// browse shows an older or newer history entry, moving past the newest one restores the edited line
+func (e *editor) browse(direction int) {
+	next := e.browsing + direction
+
+	if next < 0 || next > len(e.history.entries) {
+		return
+	}
+
+	if e.browsing == len(e.history.entries) {
+		e.saved = e.buf
+	}
+
+	e.browsing = next
+
+	if next == len(e.history.entries) {
+		e.buf = e.saved
+	} else {
+		e.buf = []rune(e.history.entries[next])
+	}
+
+	e.pos = len(e.buf)
+}
+
+func (e *editor) redraw() {
+	_, _ = fmt.Fprintf(e.out, "\r%s%s\x1b[K", e.prompt, string(e.buf))
+
+	if back := len(e.buf) - e.pos; back > 0 {
+		_, _ = fmt.Fprintf(e.out, "\x1b[%dD", back)
+	}
+}
@@ -0,0 +1,90 @@
+package repl
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const maxHistory = 1000
+
+// history holds the lines entered in earlier sessions and this one, new lines are appended to its file
+type history struct {
+	path    string
+	entries []string
+}
+
+// historyPath is $GOPP_HISTORY or .gopp_history in the home directory
+func historyPath() string {
+	if path := os.Getenv("GOPP_HISTORY"); path != "" {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".gopp_history")
+}
+
+// loadHistory reads the history file, a missing or unreadable file gives an empty history
+func loadHistory(path string) *history {
+	h := &history{path: path}
+
+	if path == "" {
+		return h
+	}
+
+	file, err := os.Open(path)
+
+	if err != nil {
+		return h
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[len(h.entries)-maxHistory:]
+		_ = os.WriteFile(path, []byte(strings.Join(h.entries, "\n")+"\n"), 0600)
+	}
+
+	return h
+}
+
+// add records a line unless it is empty or repeats the previous one
+func (h *history) add(line string) {
+	if strings.TrimSpace(line) == "" || len(h.entries) > 0 && h.entries[len(h.entries)-1] == line {
+		return
+	}
+
+	h.entries = append(h.entries, line)
+
+	if len(h.entries) > maxHistory {
+		h.entries = h.entries[1:]
+	}
+
+	if h.path == "" {
+		return
+	}
+
+	file, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+
+	if err != nil {
+		return
+	}
+
+	defer file.Close()
+
+	_, _ = file.WriteString(line + "\n")
+}
@@ -0,0 +1,75 @@
+package repl
+
+import (
+	"go++/lexer"
+	"go++/token"
+)
+
+// continuedBy are the tokens a statement can't end with, input ending in one of them goes on on the next line
+var continuedBy = map[token.Type]bool{
+	token.ASSIGN:      true,
+	token.PLUS:        true,
+	token.MINUS:       true,
+	token.ASTERISK:    true,
+	token.SLASH:       true,
+	token.EQUALS:      true,
+	token.NOTEQUALS:   true,
+	token.NOT:         true,
+	token.LESSTHAN:    true,
+	token.GREATERTHAN: true,
+	token.COMMA:       true,
+	token.DOT:         true,
+	token.COLON:       true,
+	token.ARROW:       true,
+	token.FUNCTION:    true,
+	token.LET:         true,
+	token.MUT:         true,
+	token.RETURN:      true,
+	token.FOR:         true,
+	token.IF:          true,
+	token.ELSE:        true,
+}
+
+// isIncomplete reports whether the input needs more lines, which it does when a string, raw string, brace,
+// bracket or parenthesis is left open or the last token is an operator
+func isIncomplete(input string) bool {
+	depth := 0
+	inString := false
+	inRawString := false
+
+	for i := 0; i < len(input); i++ {
+		switch ch := input[i]; {
+		case inRawString:
+			inRawString = ch != '`'
+		case inString && ch == '\\':
+			i += 1
+		case ch == '"':
+			inString = !inString
+		case inString:
+		case ch == '`':
+			inRawString = true
+		case ch == '/' && i+1 < len(input) && input[i+1] == '/':
+			for i < len(input) && input[i] != '\n' {
+				i += 1
+			}
+		case ch == '(' || ch == '[' || ch == '{':
+			depth += 1
+		case ch == ')' || ch == ']' || ch == '}':
+			depth -= 1
+		}
+	}
+
+	if inString || inRawString || depth > 0 {
+		return true
+	}
+
+	// Strings are closed so lexing the input always reaches the end
+	lex := lexer.New(input)
+	last := token.Token{Type: token.EOF}
+
+	for tok := lex.NextToken(); tok.Type != token.EOF; tok = lex.NextToken() {
+		last = tok
+	}
+
+	return continuedBy[last.Type]
+}
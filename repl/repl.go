@@ -1,48 +1,243 @@
 package repl
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
+	"go++/analyzer"
+	"go++/ast"
 	"go++/evaluator"
 	"go++/lexer"
 	"go++/object"
 	"go++/parser"
 	"io"
+	"os"
+	"strings"
 )
 
 const PROMPT = ">> "
 
+// CONTINUATION_PROMPT is shown while a statement spans several lines
+const CONTINUATION_PROMPT = "... "
+
+const help = `:load file   evaluate a file into the session
+:env         list the bindings of the session
+:type expr   print the static type of an expression
+:ast expr    print the syntax tree of an expression
+:reset       forget every binding
+:quit        leave the REPL`
+
+// session is the state kept between inputs
+type session struct {
+	out     io.Writer
+	env     *object.Environment
+	checker *analyzer.Analyzer
+}
+
+func newSession(out io.Writer) *session {
+	return &session{out: out, env: object.NewEnvironment(), checker: analyzer.New()}
+}
+
 func Start(in io.Reader, out io.Writer) {
-	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+	s := newSession(out)
+	lines := newLineReader(in, out, s.complete)
 
 	for {
-		fmt.Fprintf(out, PROMPT)
+		input, err := readInput(lines)
 
-		scanned := scanner.Scan()
+		if errors.Is(err, errInterrupted) {
+			continue
+		}
 
-		if !scanned {
+		if err != nil && input == "" {
 			return
 		}
 
-		line := scanner.Text()
+		if trimmed := strings.TrimSpace(input); strings.HasPrefix(trimmed, ":") {
+			if !s.command(trimmed) {
+				return
+			}
+		} else {
+			s.evaluate(input)
+		}
 
-		pars := parser.New(lexer.New(line))
+		if err != nil {
+			return
+		}
+	}
+}
 
-		program := pars.ParseProgram()
+// readInput reads lines until they form a complete statement, the error is only set
+// when the input ended or was interrupted
+func readInput(lines lineReader) (string, error) {
+	input, err := lines.ReadLine(PROMPT)
 
-		if len(pars.Errors()) > 0 {
-			printParserErrors(out, pars.Errors())
-			continue
+	if err != nil {
+		return "", err
+	}
+
+	if strings.HasPrefix(strings.TrimSpace(input), ":") {
+		return input, nil
+	}
+
+	for isIncomplete(input) {
+		line, err := lines.ReadLine(CONTINUATION_PROMPT)
+
+		if errors.Is(err, errInterrupted) {
+			return "", err
+		}
+
+		if err != nil {
+			// What was read so far is evaluated so its errors are reported
+			return input, err
 		}
 
-		evaluated := evaluator.Evaluate(program, env)
+		input += "\n" + line
+	}
+
+	return input, nil
+}
+
+func (s *session) evaluate(input string) {
+	program, ok := s.parse(input)
+
+	if !ok {
+		return
+	}
+
+	evaluated := evaluator.Evaluate(program, s.env)
+
+	if evaluated != nil {
+		io.WriteString(s.out, evaluated.Inspect())
+		io.WriteString(s.out, "\n")
+	}
+}
+
+// parse parses and analyzes the input, errors are printed
+func (s *session) parse(input string) (*ast.Program, bool) {
+	pars := parser.New(lexer.New(input))
+
+	program := pars.ParseProgram()
+
+	if len(pars.Errors()) > 0 {
+		printParserErrors(s.out, pars.Errors())
+		return nil, false
+	}
+
+	if diagnostics := analyzer.Errors(s.checker.Analyze(program)); len(diagnostics) > 0 {
+		printDiagnostics(s.out, diagnostics)
+		return nil, false
+	}
+
+	return program, true
+}
+
+// parseExpression parses the argument of :type and :ast
+func (s *session) parseExpression(input string) (ast.Expression, bool) {
+	pars := parser.New(lexer.New(input))
+
+	program := pars.ParseProgram()
 
-		if evaluated != nil {
-			io.WriteString(out, evaluated.Inspect())
-			io.WriteString(out, "\n")
+	if len(pars.Errors()) > 0 {
+		printParserErrors(s.out, pars.Errors())
+		return nil, false
+	}
+
+	if len(program.Statements) != 1 {
+		fmt.Fprintln(s.out, "expected a single expression")
+		return nil, false
+	}
+
+	statement, ok := program.Statements[0].(*ast.ExpressionStatement)
+
+	if !ok {
+		fmt.Fprintf(s.out, "expected an expression, got %s\n", program.Statements[0].TokenLiteral())
+		return nil, false
+	}
+
+	return statement.Expression, true
+}
+
+// command runs a meta-command, it returns false when the REPL should stop
+func (s *session) command(input string) bool {
+	name, argument, _ := strings.Cut(input, " ")
+	argument = strings.TrimSpace(argument)
+
+	switch name {
+	case ":quit", ":q":
+		return false
+	case ":help":
+		fmt.Fprintln(s.out, help)
+	case ":reset":
+		s.env = object.NewEnvironment()
+		s.checker = analyzer.New()
+	case ":env":
+		s.printEnv()
+	case ":load":
+		s.load(argument)
+	case ":type":
+		s.printType(argument)
+	case ":ast":
+		if expression, ok := s.parseExpression(argument); ok {
+			_ = ast.Dump(s.out, expression)
+		}
+	default:
+		fmt.Fprintf(s.out, "unknown command %s, :help lists the commands\n", name)
+	}
+
+	return true
+}
+
+func (s *session) load(file string) {
+	if file == "" {
+		fmt.Fprintln(s.out, "usage: :load file.gopp")
+		return
+	}
+
+	data, err := os.ReadFile(file)
+
+	if err != nil {
+		fmt.Fprintln(s.out, "no file found named "+file)
+		return
+	}
+
+	program, ok := s.parse(string(data))
+
+	if !ok {
+		return
+	}
+
+	if evaluated, ok := evaluator.Evaluate(program, s.env).(*object.Error); ok {
+		fmt.Fprintln(s.out, evaluated.Inspect())
+	}
+}
+
+func (s *session) printEnv() {
+	for _, binding := range s.env.Bindings() {
+		keyword := "let"
+
+		if binding.IsMutable {
+			keyword = "let mut"
 		}
+
+		fmt.Fprintf(s.out, "%s %s = %s\n", keyword, binding.Name, binding.Object.Inspect())
+	}
+}
+
+func (s *session) printType(input string) {
+	expression, ok := s.parseExpression(input)
+
+	if !ok {
+		return
+	}
+
+	t, diagnostics := s.checker.TypeOf(expression)
+
+	if diagnostics := analyzer.Errors(diagnostics); len(diagnostics) > 0 {
+		printDiagnostics(s.out, diagnostics)
+		return
 	}
+
+	fmt.Fprintln(s.out, t)
 }
 
 func printParserErrors(out io.Writer, errors []string) {
@@ -51,3 +246,10 @@ func printParserErrors(out io.Writer, errors []string) {
 		io.WriteString(out, "\t"+msg+"\n")
 	}
 }
+
+func printDiagnostics(out io.Writer, diagnostics []analyzer.Diagnostic) {
+	io.WriteString(out, "Error(s) occurred!\n")
+	for _, diagnostic := range diagnostics {
+		io.WriteString(out, "\t"+diagnostic.String()+"\n")
+	}
+}
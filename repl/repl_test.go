@@ -0,0 +1,158 @@
+package repl
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsIncomplete(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"let x = 5", false},
+		{"let x =", true},
+		{"1 +", true},
+		{"let f = fn (a, b) {", true},
+		{"let f = fn (a, b) {\n a + b\n}", false},
+		{"[1, 2,", true},
+		{"add(1,", true},
+		{"\"unterminated {", true},
+		{"\"{\"", false},
+		{"\"\\\"{\"", false},
+		{"x // comment {", false},
+		{"let s = `a", true},
+		{"let s = `a\nb`", false},
+		{"println(`{`)", false},
+		{"println(`\\` + \"(\")", false},
+		{"`\"` + \"`\" + (", true},
+		{"if x < 1 {} else", true},
+		{"}", false},
+	}
+
+	for _, tt := range tests {
+		if got := isIncomplete(tt.input); got != tt.expected {
+			t.Errorf("isIncomplete(%q) wrong. want=%t, got=%t", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func runSession(input string) string {
+	var out bytes.Buffer
+
+	Start(strings.NewReader(input), &out)
+
+	return out.String()
+}
+
+func TestMultiLineInput(t *testing.T) {
+	output := runSession("let add = fn (a, b) {\na +\nb\n}\nadd(2, 3)\n")
+
+	expected := ">> ... ... ... null\n>> 5\n>> "
+
+	if output != expected {
+		t.Errorf("wrong output. want=%q, got=%q", expected, output)
+	}
+}
+
+func TestMultiLineRawString(t *testing.T) {
+	output := runSession("let s = `a\nb`\ns\nprintln(`{`)\n1\n")
+
+	expected := ">> ... null\n>> a\nb\n>> null\n>> 1\n>> "
+
+	if output != expected {
+		t.Errorf("wrong output. want=%q, got=%q", expected, output)
+	}
+}
+
+func TestMetaCommands(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "lib.gopp")
+
+	if err := os.WriteFile(file, []byte("let double = fn (x: int) -> int { x * 2 }"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x = 1\nlet mut y = \"a\"\n:env\n", "let x = 1\nlet mut y = a\n"},
+		{"let x = 1\n:reset\n:env\nx\n", "undefined: x"},
+		{":load " + file + "\ndouble(21)\n", "42\n"},
+		{":load missing.gopp\n", "no file found named missing.gopp\n"},
+		{":type [1, 2]\n", "[int]\n"},
+		{"let s = \"a\"\n:type s + \"b\"\n", "string\n"},
+		{":type 1 + true\n", "type mismatch: int + bool"},
+		{":ast -1\n", "PrefixExpression 1:1\n"},
+		{":quit\n1\n", ">> "},
+		{":nope\n", "unknown command :nope"},
+	}
+
+	for _, tt := range tests {
+		output := runSession(tt.input)
+
+		if !strings.Contains(output, tt.expected) {
+			t.Errorf("wrong output for %q. want it to contain %q, got=%q", tt.input, tt.expected, output)
+		}
+	}
+
+	if output := runSession(":quit\n1\n"); output != ">> " {
+		t.Errorf(":quit didn't stop the REPL. got=%q", output)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+
+	h := loadHistory(path)
+	h.add("let x = 1")
+	h.add("let x = 1")
+	h.add("  ")
+	h.add("x")
+
+	loaded := loadHistory(path)
+
+	if strings.Join(loaded.entries, "|") != "let x = 1|x" {
+		t.Errorf("wrong history entries. got=%q", loaded.entries)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	s := newSession(&bytes.Buffer{})
+	s.evaluate("let greeting = \"hi\" let mut grades = [1, 2] let count = 3")
+
+	tests := []struct {
+		line          string
+		expectedStart int
+		expected      []string
+	}{
+		{"gr", 0, []string{"grades", "greeting"}},
+		{"1 + cou", 4, []string{"count"}},
+		{"pri", 0, []string{"print", "printf", "println"}},
+		{"greeting.", 9, []string{"length", "replace"}},
+		{"greeting.len", 9, []string{"length"}},
+		{"\"a.b\".rep", 6, []string{"replace"}},
+		{"grades.", 7, []string{"forEach", "length", "map"}},
+		{"count.a", 6, []string{"add"}},
+		{"true.", 5, nil},
+		{"len(greeting).", 14, nil},
+		{"grades[0].", 10, []string{"add"}},
+		{"grades[-1].", 11, nil},
+	}
+
+	for _, tt := range tests {
+		line := []rune(tt.line)
+		start, candidates := s.complete(line, len(line))
+
+		if start != tt.expectedStart {
+			t.Errorf("wrong start for %q. want=%d, got=%d", tt.line, tt.expectedStart, start)
+		}
+
+		if strings.Join(candidates, " ") != strings.Join(tt.expected, " ") {
+			t.Errorf("wrong candidates for %q. want=%v, got=%v", tt.line, tt.expected, candidates)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !linux && !darwin && !freebsd
+
+package repl
+
+import "errors"
+
+// Line editing isn't supported here, the REPL reads plain lines instead
+
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
@@ -0,0 +1,290 @@
+// Package tester runs the test functions of *_test.gopp files for gopp test
+package tester
+
+import (
+	"errors"
+	"fmt"
+	"go++/analyzer"
+	"go++/ast"
+	"go++/coverage"
+	"go++/evaluator"
+	"go++/lexer"
+	"go++/object"
+	"go++/parser"
+	"go++/token"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const suffix = "_test.gopp"
+
+// Runner runs test files and reports their results to Out
+type Runner struct {
+	Out io.Writer
+
+	// Filter selects the tests to run by name, every test runs when it is nil
+	Filter *regexp.Regexp
+
+	// Verbose reports the tests that pass too
+	Verbose bool
+
+	// Globals creates the environment a test runs in and an analyzer that knows its bindings
+	Globals func() (*object.Environment, *analyzer.Analyzer)
+
+	// Coverage records which statements of the test files ran when it isn't nil
+	Coverage *coverage.Profile
+}
+
+// Result is the outcome of a test
+type Result struct {
+	Name   string
+	Passed bool
+
+	// Message says why the test failed, Line and Column are where it did
+	Message string
+	Line    int
+	Column  int
+}
+
+// Discover finds the test files in paths, directories are searched recursively and files are taken as they are
+func Discover(paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files := []string{}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+
+		if err != nil {
+			return nil, errors.New("no file or directory found named " + path)
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if !entry.IsDir() && strings.HasSuffix(file, suffix) {
+				files = append(files, file)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("no test files found in " + strings.Join(paths, ", "))
+	}
+
+	return files, nil
+}
+
+// IsTestName reports whether a function bound to name is a test, the name is test followed by
+// nothing or by something that doesn't start with a lower case letter, like testAdd or test_add
+func IsTestName(name string) bool {
+	rest, ok := strings.CutPrefix(name, "test")
+
+	if !ok {
+		return false
+	}
+
+	for _, r := range rest {
+		return !unicode.IsLower(r)
+	}
+
+	return true
+}
+
+// Run runs every file and reports whether all their tests passed
+func (r *Runner) Run(files []string) bool {
+	passed := true
+
+	for _, file := range files {
+		if !r.RunFile(file) {
+			passed = false
+		}
+	}
+
+	return passed
+}
+
+// RunFile runs the tests of a file and reports whether they all passed
+func (r *Runner) RunFile(file string) bool {
+	results, err := r.runFile(file)
+
+	if err != nil {
+		fmt.Fprintln(r.Out, err)
+		fmt.Fprintf(r.Out, "FAIL\t%s\n", file)
+
+		return false
+	}
+
+	passed := true
+
+	for _, result := range results {
+		passed = passed && result.Passed
+	}
+
+	switch {
+	case !passed:
+		fmt.Fprintf(r.Out, "FAIL\t%s\n", file)
+	case len(results) == 0:
+		fmt.Fprintf(r.Out, "ok\t%s [no tests to run]\n", file)
+	default:
+		fmt.Fprintf(r.Out, "ok\t%s\n", file)
+	}
+
+	return passed
+}
+
+func (r *Runner) runFile(file string) ([]Result, error) {
+	data, err := os.ReadFile(file)
+
+	if err != nil {
+		return nil, errors.New("no file found named " + file)
+	}
+
+	pars := parser.New(lexer.New(string(data)))
+	program := pars.ParseProgram()
+
+	if len(pars.Errors()) > 0 {
+		return nil, errors.New(file + ":" + strings.Join(pars.Errors(), "\n"+file+":"))
+	}
+
+	_, staticAnalyzer := r.Globals()
+
+	if diagnostics := analyzer.Errors(staticAnalyzer.Analyze(program)); len(diagnostics) > 0 {
+		messages := make([]string, len(diagnostics))
+
+		for i, diagnostic := range diagnostics {
+			messages[i] = file + ":" + diagnostic.String()
+		}
+
+		return nil, errors.New(strings.Join(messages, "\n"))
+	}
+
+	if r.Coverage != nil {
+		r.Coverage.Add(file, string(data), program)
+	}
+
+	results := []Result{}
+
+	for _, test := range tests(program) {
+		if r.Filter != nil && !r.Filter.MatchString(test.name.Value) {
+			continue
+		}
+
+		if r.Verbose {
+			fmt.Fprintf(r.Out, "=== RUN   %s\n", test.name.Value)
+		}
+
+		result := r.runTest(program, test)
+		r.report(file, result)
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (r *Runner) report(file string, result Result) {
+	if result.Passed {
+		if r.Verbose {
+			fmt.Fprintf(r.Out, "--- PASS: %s\n", result.Name)
+		}
+
+		return
+	}
+
+	fmt.Fprintf(r.Out, "--- FAIL: %s\n", result.Name)
+	fmt.Fprintf(r.Out, "\t%s:%d:%d: %s\n", file, result.Line, result.Column, strings.ReplaceAll(result.Message, "\n", "\n\t"))
+}
+
+// test is a top level test function, declared with fn or bound with let
+type test struct {
+	token    token.Token
+	name     *ast.Identifier
+	function *ast.FunctionLiteral
+}
+
+// tests returns the top level test functions in the order they are declared
+func tests(program *ast.Program) []test {
+	found := []test{}
+
+	for _, statement := range program.Statements {
+		switch statement := statement.(type) {
+		case *ast.LetStatement:
+			if literal, ok := statement.Value.(*ast.FunctionLiteral); ok && statement.Name != nil && IsTestName(statement.Name.Value) {
+				found = append(found, test{token: statement.Token, name: statement.Name, function: literal})
+			}
+		case *ast.FunctionStatement:
+			if IsTestName(statement.Function.Name.Value) {
+				found = append(found, test{token: statement.Token, name: statement.Function.Name, function: statement.Function})
+			}
+		}
+	}
+
+	return found
+}
+
+// runTest evaluates the whole file in a fresh environment before applying the test, so a test
+// can't see what the ones before it changed
+func (r *Runner) runTest(program *ast.Program, test test) Result {
+	result := Result{Name: test.name.Value, Line: test.token.Line, Column: test.token.Column}
+
+	if len(test.function.Parameters) > 0 {
+		result.Message = "test functions take no parameters"
+		return result
+	}
+
+	env, _ := r.Globals()
+	positions := &tracker{}
+
+	previous := evaluator.Debugger
+	evaluator.Debugger = positions
+	defer func() { evaluator.Debugger = previous }()
+
+	if r.Coverage != nil {
+		evaluator.Coverage = r.Coverage
+		defer func() { evaluator.Coverage = nil }()
+	}
+
+	evaluated := evaluator.Evaluate(program, env)
+
+	if err, ok := evaluated.(*object.Error); ok {
+		result.Message = "setting up the test failed: " + err.Message
+		result.Line, result.Column = positions.failure()
+
+		return result
+	}
+
+	call := &ast.CallExpression{Token: test.token, Function: &ast.Identifier{Token: test.name.Token, Value: test.name.Value}}
+	positions.reset()
+
+	if err, ok := evaluator.Evaluate(call, env).(*object.Error); ok {
+		result.Message = err.Message
+		result.Line, result.Column = positions.failure()
+
+		return result
+	}
+
+	result.Passed = true
+
+	return result
+}
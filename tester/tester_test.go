@@ -0,0 +1,177 @@
+package tester
+
+import (
+	"bytes"
+	"go++/analyzer"
+	"go++/coverage"
+	"go++/object"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const testFile = `let add = fn (a, b) { a + b }
+let mut counter = 0
+
+let testAdd = fn () {
+	counter = counter + 1
+	assertEqual(add(1, 2), 3)
+}
+
+fn testIsolated() {
+	counter = counter + 1
+	assertEqual(counter, 1)
+}
+
+let testNested = fn () {
+	let check = fn (xs) {
+		assertEqual(xs, [1, 2])
+	}
+
+	check([1, 3])
+}
+
+let testing = fn () { assert(false) }
+`
+
+func newRunner(out *bytes.Buffer) *Runner {
+	return &Runner{Out: out, Globals: func() (*object.Environment, *analyzer.Analyzer) {
+		return object.NewEnvironment(), analyzer.New()
+	}}
+}
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestDiscover(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a_test.gopp":     "",
+		"a.gopp":          "",
+		"sub/b_test.gopp": "",
+	})
+
+	files, err := Discover([]string{dir})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 2 || files[0] != filepath.Join(dir, "a_test.gopp") || files[1] != filepath.Join(dir, "sub", "b_test.gopp") {
+		t.Errorf("wrong test files. got=%v", files)
+	}
+
+	if _, err := Discover([]string{filepath.Join(dir, "sub", "nope")}); err == nil {
+		t.Errorf("expected a missing path to fail")
+	}
+}
+
+func TestIsTestName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected bool
+	}{
+		{"test", true},
+		{"testAdd", true},
+		{"test_add", true},
+		{"testing", false},
+		{"check", false},
+	}
+
+	for _, tt := range tests {
+		if IsTestName(tt.name) != tt.expected {
+			t.Errorf("wrong result for %s. want=%t", tt.name, tt.expected)
+		}
+	}
+}
+
+func TestRunFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"math_test.gopp": testFile})
+	file := filepath.Join(dir, "math_test.gopp")
+
+	var out bytes.Buffer
+	runner := newRunner(&out)
+	runner.Verbose = true
+
+	if runner.RunFile(file) {
+		t.Fatalf("expected the file to fail")
+	}
+
+	expected := `=== RUN   testAdd
+--- PASS: testAdd
+=== RUN   testIsolated
+--- PASS: testIsolated
+=== RUN   testNested
+--- FAIL: testNested
+	FILE:16:3: assertEqual failed
+		want: [1, 2]
+		got:  [1, 3]
+		at [1]: want 2, got 3
+FAIL	FILE
+`
+
+	if got := out.String(); got != strings.ReplaceAll(expected, "FILE", file) {
+		t.Errorf("wrong report. got=\n%s", got)
+	}
+
+	out.Reset()
+	runner.Verbose = false
+	runner.Filter = regexp.MustCompile("Add|Isolated")
+
+	if !runner.RunFile(file) || out.String() != "ok\t"+file+"\n" {
+		t.Errorf("expected the filtered tests to pass. got=\n%s", out.String())
+	}
+
+	runner.Coverage = coverage.New()
+	runner.RunFile(file)
+
+	// The filter leaves out testNested and testing, so the four statements of their bodies never ran
+	if counts := runner.Coverage.Counts(runner.Coverage.Files()[0]); counts.Statements != 15 || counts.CoveredStatements != 11 {
+		t.Errorf("wrong coverage. got=%+v", counts)
+	}
+}
+
+func TestRunFileErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"parse_test.gopp": "let x = ",
+		"setup_test.gopp": "let testA = fn () { }\nlet y = [1][1]\n",
+		"args_test.gopp":  "let testA = fn (x) { }",
+	})
+
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{"parse_test.gopp", ":1:9: no prefix parse function for EOF found"},
+		{"setup_test.gopp", ":2:1: setting up the test failed: ERROR: index 1 out of range"},
+		{"args_test.gopp", ":1:1: test functions take no parameters"},
+	}
+
+	for _, tt := range tests {
+		var out bytes.Buffer
+
+		if newRunner(&out).RunFile(filepath.Join(dir, tt.file)) {
+			t.Errorf("expected %s to fail", tt.file)
+		}
+
+		if !strings.Contains(out.String(), tt.file+tt.expected) {
+			t.Errorf("wrong report for %s. want it to contain %q, got=\n%s", tt.file, tt.expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package tester
+
+import (
+	"go++/ast"
+	"go++/object"
+	"go++/token"
+)
+
+// tracker is hooked into the evaluator to know which statement a test failed at, errors carry no position
+type tracker struct {
+	// statements holds the statement running in every function being applied, the program's one first
+	statements []token.Token
+
+	// failed is the statement an error was returned from, until a statement runs after it
+	failed *token.Token
+}
+
+func (t *tracker) reset() {
+	t.statements = []token.Token{{}}
+	t.failed = nil
+}
+
+func (t *tracker) Statement(statement ast.Statement, env *object.Environment) *object.Error {
+	if len(t.statements) == 0 {
+		t.reset()
+	}
+
+	t.statements[len(t.statements)-1] = ast.StartToken(statement)
+	t.failed = nil
+
+	return nil
+}
+
+func (t *tracker) Call(fn *object.Function, env *object.Environment) {
+	t.statements = append(t.statements, token.Token{})
+}
+
+func (t *tracker) Return(fn *object.Function, result object.Object) {
+	if len(t.statements) < 2 {
+		return
+	}
+
+	innermost := t.statements[len(t.statements)-1]
+	t.statements = t.statements[:len(t.statements)-1]
+
+	if _, ok := result.(*object.Error); ok && t.failed == nil {
+		t.failed = &innermost
+	}
+}
+
+func (t *tracker) Iteration(loop ast.Expression) {}
+
+// failure returns the line and column of the statement the error came from
+func (t *tracker) failure() (int, int) {
+	if t.failed != nil {
+		return t.failed.Line, t.failed.Column
+	}
+
+	if len(t.statements) == 0 {
+		return 0, 0
+	}
+
+	last := t.statements[len(t.statements)-1]
+
+	return last.Line, last.Column
+}
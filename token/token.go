@@ -5,6 +5,12 @@ type Type string
 type Token struct {
 	Type    Type
 	Literal string
+	Line    int
+	Column  int
+
+	// Raw is the text of a string or a part of one as it was written, with its escapes and without
+	// the quotes, the ${ or the } around it
+	Raw string
 }
 
 var Keywords = map[string]Type{
@@ -13,8 +19,10 @@ var Keywords = map[string]Type{
 	"mut":    MUT,
 	"return": RETURN,
 	"for":    FOR,
+	"in":     IN,
 	"true":   TRUE,
 	"false":  FALSE,
+	"null":   NULL,
 	"if":     IF,
 	"else":   ELSE,
 }
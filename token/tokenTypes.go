@@ -21,6 +21,13 @@ const (
 	COMMA     = ","
 	DOT       = "."
 	SEMICOLON = ";"
+	COLON     = ":"
+	ARROW     = "->"
+	ELLIPSIS  = "..."
+
+	// a?.b and a?.[i] are null when a is, a ?? b is b when a is null
+	OPTIONALCHAIN = "?."
+	COALESCE      = "??"
 
 	LPAREN   = "("
 	RPAREN   = ")"
@@ -34,10 +41,19 @@ const (
 	MUT      = "MUT"
 	RETURN   = "RETURN"
 	FOR      = "FOR"
+	IN       = "IN"
 	TRUE     = "TRUE"
 	FALSE    = "FALSE"
+	NULL     = "NULL"
 	IF       = "IF"
 	ELSE     = "ELSE"
 
-	STRING = "STRING"
+	STRING    = "STRING"
+	RAWSTRING = "RAWSTRING"
+
+	// An interpolated string is split into the parts before its embedded expressions and the part that ends it
+	INTERPOLATION    = "INTERPOLATION"
+	INTERPOLATIONEND = "INTERPOLATIONEND"
+
+	COMMENT = "COMMENT"
 )